@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const auditUsage = "audit verify [-json]"
+
+var errChainBroken = errors.New("audit chain is broken")
+
+func runAudit(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 || args[0] != "verify" {
+		return fmt.Errorf("unknown audit subcommand, usage: %s", auditUsage)
+	}
+
+	flags, asJSON := outputFlags("audit verify")
+	if _, err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	service := ctn.Get(di.AuditService).(*auditService.AuditService)
+	report, err := service.Verify(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("checked entries:      %d\n", report.Checked)
+		fmt.Printf("unchained entries:    %d\n", report.Unchained)
+		fmt.Printf("verified checkpoints: %d\n", report.Checkpoints)
+		fmt.Printf("last valid seq:       %d\n", report.LastSeq)
+		fmt.Printf("signed head seq:      %d\n", report.HeadSeq)
+		if report.Valid {
+			fmt.Println("chain is intact")
+		} else {
+			fmt.Printf("first broken link at seq %d: %s\n", report.BrokenSeq, report.Reason)
+		}
+	}
+
+	if !report.Valid {
+		return errChainBroken
+	}
+
+	return nil
+}
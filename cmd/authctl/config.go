@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/elusiv0/medods_test/internal/config"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const configUsage = "config print [-config path] [-set KEY=VALUE] [-json]"
+
+func runConfig(_ diContainer.Container, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return fmt.Errorf("unknown config subcommand, usage: %s", configUsage)
+	}
+
+	flags, asJSON := outputFlags("config print")
+	file := flags.String("config", "", "path to YAML or TOML config file, CONFIG_FILE by default")
+	overrides := config.Overrides{}
+	flags.Var(overrides, "set", "override config value as KEY=VALUE, may be repeated")
+	if _, err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.GetConfig(config.WithFile(*file), config.WithOverrides(overrides))
+	if err != nil {
+		return err
+	}
+	settings := cfg.Settings()
+
+	if *asJSON {
+		return printJSON(settings)
+	}
+
+	writer := newTable()
+	fmt.Fprintln(writer, "KEY\tVALUE")
+	for _, setting := range settings {
+		fmt.Fprintf(writer, "%s\t%s\n", setting.Key, setting.Value)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	fixtureService "github.com/elusiv0/medods_test/internal/service/fixture"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const fixturesUsage = "fixtures load <path> [-json]"
+
+func runFixtures(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 || args[0] != "load" {
+		return fmt.Errorf("unknown fixtures subcommand, usage: %s", fixturesUsage)
+	}
+
+	flags, asJSON := outputFlags("fixtures load")
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		return err
+	}
+	path := arg(positional)
+	if path == "" {
+		return fmt.Errorf("missing fixtures path, usage: %s", fixturesUsage)
+	}
+
+	service := ctn.Get(di.FixtureService).(*fixtureService.FixtureService)
+	result, err := service.ApplyPath(commandContext(), path)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(result)
+	}
+	fmt.Printf("users: %d created, %d updated, %d unchanged\n", result.Created, result.Updated, result.Unchanged)
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	groupService "github.com/elusiv0/medods_test/internal/service/group"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const groupsUsage = "groups list | create -name <name> [-roles a,b] [-parents id,id] | delete <id> | add-member <id> -user <uuid> | remove-member <id> -user <uuid> | effective <uuid> [-json]"
+
+func runGroups(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing groups subcommand, usage: %s", groupsUsage)
+	}
+
+	flags, asJSON := outputFlags("groups " + args[0])
+	name := flags.String("name", "", "name of created group")
+	description := flags.String("description", "", "description of created group")
+	roles := flags.String("roles", "", "comma separated roles of created group")
+	parents := flags.String("parents", "", "comma separated ids of groups created group is nested into")
+	uuid := flags.String("user", "", "uuid of member")
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	service := ctn.Get(di.GroupService).(*groupService.GroupService)
+	ctx := commandContext()
+
+	switch args[0] {
+	case "list":
+		list, err := service.List(ctx)
+		if err != nil {
+			return err
+		}
+		return printGroups(*asJSON, list, list)
+	case "create":
+		if *name == "" {
+			return fmt.Errorf("-name is required, usage: %s", groupsUsage)
+		}
+		group, err := service.Create(ctx, groupDto.CreateGroup{
+			Name:        *name,
+			Description: *description,
+			Roles:       splitList(*roles),
+			Parents:     splitList(*parents),
+		})
+		if err != nil {
+			return err
+		}
+		return printGroups(*asJSON, []groupDto.Group{group}, group)
+	case "delete":
+		id := arg(positional)
+		if id == "" {
+			return fmt.Errorf("missing group id, usage: %s", groupsUsage)
+		}
+		if err := service.Delete(ctx, id); err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(map[string]string{"id": id, "result": "deleted"})
+		}
+		fmt.Printf("group %s deleted\n", id)
+		return nil
+	case "add-member", "remove-member":
+		id := arg(positional)
+		if id == "" || *uuid == "" {
+			return fmt.Errorf("missing group id or -user, usage: %s", groupsUsage)
+		}
+		var group groupDto.Group
+		if args[0] == "add-member" {
+			group, err = service.AddMember(ctx, id, *uuid)
+		} else {
+			group, err = service.RemoveMember(ctx, id, *uuid)
+		}
+		if err != nil {
+			return err
+		}
+		return printGroups(*asJSON, []groupDto.Group{group}, group)
+	case "effective":
+		user := arg(positional)
+		if user == "" {
+			return fmt.Errorf("missing user uuid, usage: %s", groupsUsage)
+		}
+		effective, err := service.EffectiveOf(ctx, user)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(effective)
+		}
+		writer := newTable()
+		fmt.Fprintf(writer, "roles\t%s\n", strings.Join(effective.Roles, ","))
+		fmt.Fprintf(writer, "groups\t%s\n", strings.Join(effective.Groups, ","))
+		fmt.Fprintf(writer, "permissions\t%s\n", strings.Join(effective.Permissions, ","))
+		return writer.Flush()
+	default:
+		return fmt.Errorf("unknown groups subcommand, usage: %s", groupsUsage)
+	}
+}
+
+func printGroups(asJSON bool, groups []groupDto.Group, result interface{}) error {
+	if asJSON {
+		return printJSON(result)
+	}
+
+	writer := newTable()
+	fmt.Fprintln(writer, "ID\tNAME\tROLES\tPARENTS\tMEMBERS")
+	for _, group := range groups {
+		fmt.Fprintf(
+			writer, "%s\t%s\t%s\t%s\t%d\n",
+			group.ID, group.Name, strings.Join(group.Roles, ","), strings.Join(group.Parents, ","), len(group.Members),
+		)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	keyDto "github.com/elusiv0/medods_test/internal/model/key"
+	keyService "github.com/elusiv0/medods_test/internal/service/key"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const keysUsage = "keys generate | rotate [-key id] | list [-json]"
+
+func runKeys(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing keys subcommand, usage: %s", keysUsage)
+	}
+
+	flags, asJSON := outputFlags("keys " + args[0])
+	id := flags.String("key", "", "pending key to activate, new pending one is generated when empty")
+	if _, err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	service := ctn.Get(di.KeyService).(*keyService.KeyService)
+	ctx := commandContext()
+
+	var (
+		keys []keyDto.Key
+		err  error
+	)
+	switch args[0] {
+	case "generate":
+		var key keyDto.Key
+		key, err = service.Generate(ctx)
+		keys = []keyDto.Key{key}
+	case "rotate":
+		var key keyDto.Key
+		key, err = service.Rotate(ctx, *id)
+		keys = []keyDto.Key{key}
+	case "list":
+		keys, err = service.List(ctx)
+	default:
+		return fmt.Errorf("unknown keys subcommand, usage: %s", keysUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(keys)
+	}
+
+	writer := newTable()
+	fmt.Fprintln(writer, "KEY\tSTATUS\tCREATED AT\tACTIVATED AT\tRETIRED AT")
+	for _, key := range keys {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			key.ID,
+			key.Status,
+			formatTime(key.CreatedAt),
+			formatTime(key.ActivatedAt),
+			formatTime(key.RetiredAt),
+		)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	"github.com/joho/godotenv"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+type command struct {
+	usage string
+	run   func(ctn diContainer.Container, args []string) error
+}
+
+var commands = map[string]command{
+	"audit": {
+		usage: auditUsage,
+		run:   runAudit,
+	},
+	"migrate": {
+		usage: migrateUsage,
+		run:   runMigrate,
+	},
+	"users": {
+		usage: usersUsage,
+		run:   runUsers,
+	},
+	"roles": {
+		usage: rolesUsage,
+		run:   runRoles,
+	},
+	"groups": {
+		usage: groupsUsage,
+		run:   runGroups,
+	},
+	"tenants": {
+		usage: tenantsUsage,
+		run:   runTenants,
+	},
+	"sessions": {
+		usage: sessionsUsage,
+		run:   runSessions,
+	},
+	"keys": {
+		usage: keysUsage,
+		run:   runKeys,
+	},
+	"tokens": {
+		usage: tokensUsage,
+		run:   runTokens,
+	},
+	"config": {
+		usage: configUsage,
+		run:   runConfig,
+	},
+	"fixtures": {
+		usage: fixturesUsage,
+		run:   runFixtures,
+	},
+	"policies": {
+		usage: policiesUsage,
+		run:   runPolicies,
+	},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(".env"); err != nil {
+		log.Fatal("error with extract env varialbes")
+	}
+	ctn, err := di.InitContainer()
+	if err != nil {
+		log.Fatal("error with init app deps")
+	}
+	defer ctn.Delete()
+
+	if err := cmd.run(ctn, os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "authctl: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage: authctl <command> [arguments]")
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, "  authctl "+commands[name].usage)
+	}
+}
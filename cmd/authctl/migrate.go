@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const migrateUsage = "migrate up|status [-json]"
+
+func runMigrate(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate subcommand, usage: %s", migrateUsage)
+	}
+
+	flags, asJSON := outputFlags("migrate " + args[0])
+	if _, err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+
+	migrator := ctn.Get(di.Migrator).(*mongoClient.Migrator)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(map[string]int{"applied": applied})
+		}
+		fmt.Printf("applied migrations: %d\n", applied)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(statuses)
+		}
+
+		writer := newTable()
+		fmt.Fprintln(writer, "VERSION\tSTATE\tAPPLIED AT\tDESCRIPTION")
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", status.Version, state, formatTime(status.AppliedAt), status.Description)
+		}
+		return writer.Flush()
+	default:
+		return fmt.Errorf("unknown migrate subcommand, usage: %s", migrateUsage)
+	}
+
+	return nil
+}
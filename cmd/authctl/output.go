@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/user"
+	"text/tabwriter"
+	"time"
+
+	reqUtils "github.com/elusiv0/medods_test/internal/util/request"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+)
+
+// outputFlags registers flags shared by every subcommand.
+func outputFlags(name string) (*flag.FlagSet, *bool) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	asJSON := flags.Bool("json", false, "print result as json")
+
+	return flags, asJSON
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// commandContext marks changes made from the command line in audit log, they
+// apply to tenant named by AUTHCTL_TENANT or to the default one.
+func commandContext() context.Context {
+	actor := "authctl"
+	if current, err := user.Current(); err == nil {
+		actor += ":" + current.Username
+	}
+
+	ctx := tenantUtil.WithTenant(context.Background(), tenantUtil.Normalize(os.Getenv("AUTHCTL_TENANT")))
+
+	return reqUtils.WithInfo(ctx, reqUtils.Info{
+		Actor:     actor,
+		UserAgent: "authctl",
+	})
+}
+
+// parseFlags parses flags placed anywhere among args and returns positional arguments.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := make([]string, 0)
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+func arg(positional []string) string {
+	if len(positional) == 0 {
+		return ""
+	}
+
+	return positional[0]
+}
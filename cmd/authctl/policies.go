@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elusiv0/medods_test/pkg/policy"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const policiesUsage = "policies check <path> [-json]"
+
+func runPolicies(_ diContainer.Container, args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return fmt.Errorf("unknown policies subcommand, usage: %s", policiesUsage)
+	}
+
+	flags, asJSON := outputFlags("policies check")
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		return err
+	}
+	path := arg(positional)
+	if path == "" {
+		return fmt.Errorf("missing policies path, usage: %s", policiesUsage)
+	}
+
+	engine, err := policy.Load(path)
+	if err != nil {
+		return err
+	}
+	policies := engine.Policies()
+
+	if *asJSON {
+		return printJSON(policies)
+	}
+	table := newTable()
+	fmt.Fprintln(table, "ID\tEFFECT\tACTIONS\tRESOURCES\tCONDITIONS")
+	for _, policy := range policies {
+		fmt.Fprintf(
+			table,
+			"%s\t%s\t%s\t%s\t%d\n",
+			policy.ID,
+			policy.Effect,
+			strings.Join(policy.Actions, ","),
+			strings.Join(policy.Resources, ","),
+			len(policy.Conditions),
+		)
+	}
+
+	return table.Flush()
+}
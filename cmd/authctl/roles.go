@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	roleService "github.com/elusiv0/medods_test/internal/service/role"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const rolesUsage = "roles list | create -name <name> -permissions a,b [-description text] | delete <name> | assign <uuid> -roles a,b [-json]"
+
+func runRoles(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing roles subcommand, usage: %s", rolesUsage)
+	}
+
+	flags, asJSON := outputFlags("roles " + args[0])
+	name := flags.String("name", "", "name of created role")
+	description := flags.String("description", "", "description of created role")
+	permissions := flags.String("permissions", "", "comma separated permissions of created role")
+	roles := flags.String("roles", "", "comma separated roles to assign, empty removes all")
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	service := ctn.Get(di.RoleService).(*roleService.RoleService)
+	ctx := commandContext()
+
+	switch args[0] {
+	case "list":
+		list, err := service.List(ctx)
+		if err != nil {
+			return err
+		}
+		return printRoles(*asJSON, list, list)
+	case "create":
+		if *name == "" {
+			return fmt.Errorf("-name is required, usage: %s", rolesUsage)
+		}
+		role, err := service.Create(ctx, roleDto.CreateRole{
+			Name:        *name,
+			Description: *description,
+			Permissions: splitList(*permissions),
+		})
+		if err != nil {
+			return err
+		}
+		return printRoles(*asJSON, []roleDto.Role{role}, role)
+	case "delete":
+		role := arg(positional)
+		if role == "" {
+			return fmt.Errorf("missing role name, usage: %s", rolesUsage)
+		}
+		if err := service.Delete(ctx, role); err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(map[string]string{"name": role, "result": "deleted"})
+		}
+		fmt.Printf("role %s deleted\n", role)
+		return nil
+	case "assign":
+		uuid := arg(positional)
+		if uuid == "" {
+			return fmt.Errorf("missing user uuid, usage: %s", rolesUsage)
+		}
+		assignment, err := service.Assign(ctx, uuid, roleDto.Assignment{Roles: splitList(*roles)})
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(assignment)
+		}
+		fmt.Printf("user %s roles: %s\n", uuid, strings.Join(assignment.Roles, ","))
+		return nil
+	default:
+		return fmt.Errorf("unknown roles subcommand, usage: %s", rolesUsage)
+	}
+}
+
+func printRoles(asJSON bool, roles []roleDto.Role, result interface{}) error {
+	if asJSON {
+		return printJSON(result)
+	}
+
+	writer := newTable()
+	fmt.Fprintln(writer, "NAME\tPERMISSIONS\tDESCRIPTION")
+	for _, role := range roles {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", role.Name, strings.Join(role.Permissions, ","), role.Description)
+	}
+
+	return writer.Flush()
+}
+
+func splitList(value string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
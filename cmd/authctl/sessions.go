@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	authService "github.com/elusiv0/medods_test/internal/service/auth"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const sessionsUsage = "sessions list|revoke --user <uuid> [-json]"
+
+func runSessions(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing sessions subcommand, usage: %s", sessionsUsage)
+	}
+
+	flags, asJSON := outputFlags("sessions " + args[0])
+	uuid := flags.String("user", "", "uuid of session owner")
+	if _, err := parseFlags(flags, args[1:]); err != nil {
+		return err
+	}
+	if *uuid == "" {
+		return fmt.Errorf("--user is required, usage: %s", sessionsUsage)
+	}
+
+	service := ctn.Get(di.AuthService).(*authService.AuthService)
+	ctx := commandContext()
+
+	switch args[0] {
+	case "list":
+		sessions, err := service.Sessions(ctx, *uuid)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(sessions)
+		}
+
+		writer := newTable()
+		fmt.Fprintln(writer, "SESSION\tSTARTED AT\tEXPIRES AT")
+		for _, session := range sessions {
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", session.ID, formatTime(session.StartedAt), formatTime(session.ExpiresAt))
+		}
+		return writer.Flush()
+	case "revoke":
+		revoked, err := service.RevokeSessions(ctx, *uuid)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(map[string]int64{"revoked": revoked})
+		}
+		fmt.Printf("revoked sessions: %d\n", revoked)
+		return nil
+	default:
+		return fmt.Errorf("unknown sessions subcommand, usage: %s", sessionsUsage)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
+	tenantService "github.com/elusiv0/medods_test/internal/service/tenant"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const tenantsUsage = "tenants list | create -id <id> -name <name> [-hosts a,b] | disable <id> | enable <id> [-json]"
+
+func runTenants(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing tenants subcommand, usage: %s", tenantsUsage)
+	}
+
+	flags, asJSON := outputFlags("tenants " + args[0])
+	id := flags.String("id", "", "id of created tenant")
+	name := flags.String("name", "", "name of created tenant")
+	hosts := flags.String("hosts", "", "comma separated hosts of created tenant")
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	service := ctn.Get(di.TenantService).(*tenantService.TenantService)
+	ctx := commandContext()
+
+	switch args[0] {
+	case "list":
+		list, err := service.List(ctx)
+		if err != nil {
+			return err
+		}
+		return printTenants(*asJSON, list, list)
+	case "create":
+		if *id == "" {
+			return fmt.Errorf("-id is required, usage: %s", tenantsUsage)
+		}
+		tenant, err := service.Create(ctx, tenantDto.CreateTenant{
+			ID:    *id,
+			Name:  *name,
+			Hosts: splitList(*hosts),
+		})
+		if err != nil {
+			return err
+		}
+		return printTenants(*asJSON, []tenantDto.Tenant{tenant}, tenant)
+	case "disable", "enable":
+		tenantId := arg(positional)
+		if tenantId == "" {
+			return fmt.Errorf("missing tenant id, usage: %s", tenantsUsage)
+		}
+		disabled := args[0] == "disable"
+		tenant, err := service.Update(ctx, tenantId, tenantDto.UpdateTenant{Disabled: &disabled})
+		if err != nil {
+			return err
+		}
+		return printTenants(*asJSON, []tenantDto.Tenant{tenant}, tenant)
+	default:
+		return fmt.Errorf("unknown tenants subcommand, usage: %s", tenantsUsage)
+	}
+}
+
+func printTenants(asJSON bool, tenants []tenantDto.Tenant, result interface{}) error {
+	if asJSON {
+		return printJSON(result)
+	}
+
+	writer := newTable()
+	fmt.Fprintln(writer, "ID\tNAME\tHOSTS\tDISABLED")
+	for _, tenant := range tenants {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%t\n", tenant.ID, tenant.Name, strings.Join(tenant.Hosts, ","), tenant.Disabled)
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	"github.com/elusiv0/medods_test/internal/model/api"
+	tokenDto "github.com/elusiv0/medods_test/internal/model/token"
+	authService "github.com/elusiv0/medods_test/internal/service/auth"
+	keyService "github.com/elusiv0/medods_test/internal/service/key"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const tokensUsage = "tokens mint --user <uuid> [-client id] [-scope 'a b'] | inspect <access token> | verify <access token> [-json]"
+
+var errTokenInvalid = errors.New("access token is invalid")
+
+type verifyResult struct {
+	Valid     bool   `json:"valid"`
+	Expired   bool   `json:"expired"`
+	UUID      string `json:"uuid,omitempty"`
+	SessionId string `json:"session_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func runTokens(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing tokens subcommand, usage: %s", tokensUsage)
+	}
+
+	flags, asJSON := outputFlags("tokens " + args[0])
+	uuid := flags.String("user", "", "uuid of user to mint tokens for")
+	clientID := flags.String("client", "", "client to mint tokens for")
+	requestedScope := flags.String("scope", "", "space delimited scopes to request")
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	manager := ctn.Get(di.TokenManager).(*tokenManager.TokenManager)
+	// building key service loads signing keys into token manager
+	_ = ctn.Get(di.KeyService).(*keyService.KeyService)
+
+	switch args[0] {
+	case "mint":
+		if *uuid == "" {
+			return fmt.Errorf("--user is required, usage: %s", tokensUsage)
+		}
+		service := ctn.Get(di.AuthService).(*authService.AuthService)
+		tokens, err := service.SignIn(commandContext(), *uuid, tokenDto.ScopeRequest{
+			ClientID: *clientID,
+			Scope:    *requestedScope,
+		})
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(tokens)
+		}
+		fmt.Printf("access token:  %s\nrefresh token: %s\nscope:         %s\n", tokens.AccessToken, tokens.RefreshToken, tokens.Scope)
+		return nil
+	case "inspect":
+		header, claims, err := manager.Inspect(arg(positional))
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(map[string]interface{}{"header": header, "claims": claims})
+		}
+
+		writer := newTable()
+		for key, value := range header {
+			fmt.Fprintf(writer, "header.%s\t%v\n", key, value)
+		}
+		fmt.Fprintf(writer, "uuid\t%s\n", claims.UUID)
+		fmt.Fprintf(writer, "tenant\t%s\n", tenantUtil.Normalize(claims.Tenant))
+		fmt.Fprintf(writer, "session\t%s\n", claims.SessionId.Hex())
+		fmt.Fprintf(writer, "roles\t%s\n", strings.Join(claims.Roles, ","))
+		fmt.Fprintf(writer, "groups\t%s\n", strings.Join(claims.Groups, ","))
+		fmt.Fprintf(writer, "client\t%s\n", claims.ClientID)
+		fmt.Fprintf(writer, "scope\t%s\n", claims.Scope)
+		if claims.IssuedAt != nil {
+			fmt.Fprintf(writer, "issued at\t%s\n", formatTime(claims.IssuedAt.Time))
+		}
+		if claims.ExpiresAt != nil {
+			fmt.Fprintf(writer, "expires at\t%s\n", formatTime(claims.ExpiresAt.Time))
+		}
+		return writer.Flush()
+	case "verify":
+		info, err := manager.ValidateJWT(arg(positional))
+		result := verifyResult{
+			Valid:   err == nil,
+			Expired: errors.Is(err, api.ErrAccessTokenExpired),
+		}
+		if err == nil || result.Expired {
+			result.UUID = info.UUID
+			result.SessionId = info.SessionId.Hex()
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		if *asJSON {
+			if err := printJSON(result); err != nil {
+				return err
+			}
+		} else if result.Valid {
+			fmt.Printf("token is valid, user %s, session %s\n", result.UUID, result.SessionId)
+		} else {
+			fmt.Println("token is invalid: " + result.Error)
+		}
+
+		if !result.Valid {
+			return errTokenInvalid
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown tokens subcommand, usage: %s", tokensUsage)
+	}
+}
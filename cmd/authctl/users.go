@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elusiv0/medods_test/internal/di"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	userService "github.com/elusiv0/medods_test/internal/service/user"
+	diContainer "github.com/sarulabs/di/v2"
+)
+
+const usersUsage = "users create -name <name> | list [-q text] [-limit n] [-cursor uuid] | rename <uuid> -name <name> | disable <uuid> | enable <uuid> | delete <uuid> [-json]"
+
+func runUsers(ctn diContainer.Container, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing users subcommand, usage: %s", usersUsage)
+	}
+
+	flags, asJSON := outputFlags("users " + args[0])
+	name := flags.String("name", "", "name of created user")
+	limit := flags.Int("limit", 0, "page size")
+	cursor := flags.String("cursor", "", "uuid to list users after")
+	query := flags.String("q", "", "text to search in user names")
+	positional, err := parseFlags(flags, args[1:])
+	if err != nil {
+		return err
+	}
+
+	service := ctn.Get(di.UserService).(*userService.UserService)
+	ctx := commandContext()
+
+	switch args[0] {
+	case "create":
+		if *name == "" {
+			return fmt.Errorf("-name is required, usage: %s", usersUsage)
+		}
+		user, err := service.Create(ctx, userDto.CreateUser{Name: *name})
+		if err != nil {
+			return err
+		}
+		return printUsers(*asJSON, []userDto.User{user}, user)
+	case "list":
+		page, err := service.List(ctx, userDto.Filter{Query: *query, Limit: *limit, Cursor: *cursor})
+		if err != nil {
+			return err
+		}
+		if err := printUsers(*asJSON, page.Users, page); err != nil {
+			return err
+		}
+		if !*asJSON && page.NextCursor != "" {
+			fmt.Printf("next page: -cursor %s\n", page.NextCursor)
+		}
+		return nil
+	case "rename":
+		uuid := arg(positional)
+		if uuid == "" || *name == "" {
+			return fmt.Errorf("missing user uuid or -name, usage: %s", usersUsage)
+		}
+		user, err := service.Update(ctx, uuid, userDto.UpdateUser{Name: name})
+		if err != nil {
+			return err
+		}
+		return printUsers(*asJSON, []userDto.User{user}, user)
+	case "disable", "enable", "delete":
+		uuid := arg(positional)
+		if uuid == "" {
+			return fmt.Errorf("missing user uuid, usage: %s", usersUsage)
+		}
+		switch args[0] {
+		case "disable":
+			err = service.Disable(ctx, uuid)
+		case "enable":
+			err = service.Enable(ctx, uuid)
+		default:
+			err = service.Delete(ctx, uuid)
+		}
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return printJSON(map[string]string{"uuid": uuid, "result": args[0] + "d"})
+		}
+		fmt.Printf("user %s %sd\n", uuid, args[0])
+		return nil
+	default:
+		return fmt.Errorf("unknown users subcommand, usage: %s", usersUsage)
+	}
+}
+
+func printUsers(asJSON bool, users []userDto.User, result interface{}) error {
+	if asJSON {
+		return printJSON(result)
+	}
+
+	writer := newTable()
+	fmt.Fprintln(writer, "UUID\tNAME\tDISABLED\tROLES")
+	for _, user := range users {
+		fmt.Fprintf(writer, "%s\t%s\t%t\t%s\n", user.UUID, user.Name, user.Disabled, strings.Join(user.Roles, ","))
+	}
+
+	return writer.Flush()
+}
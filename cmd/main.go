@@ -2,33 +2,45 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 
 	"github.com/elusiv0/medods_test/internal/app"
+	"github.com/elusiv0/medods_test/internal/config"
 	"github.com/elusiv0/medods_test/internal/di"
-	userModel "github.com/elusiv0/medods_test/internal/repo/user/model"
+	fixtureService "github.com/elusiv0/medods_test/internal/service/fixture"
 	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	configFile := flag.String("config", "", "path to YAML or TOML config file, CONFIG_FILE by default")
+	overrides := config.Overrides{}
+	flag.Var(overrides, "set", "override config value as KEY=VALUE, may be repeated")
+	flag.Parse()
+
 	if err := godotenv.Load(".env"); err != nil {
 		log.Fatal("error with extract env varialbes")
 	}
-	ctn, err := di.InitContainer()
+	ctn, err := di.InitContainer(config.WithFile(*configFile), config.WithOverrides(overrides))
 	if err != nil {
 		log.Fatal("error with init app deps")
 	}
-	user1 := userModel.User{
-		UUID: "d4d46a09-dc0c-4d66-8840-7424ce91db72",
-		Name: "user1",
+	if _, err := ctn.SafeGet(di.Config); err != nil {
+		log.Fatal(err.Error())
+	}
+	if ctn.Get(di.Config).(*config.Config).Mongo.AutoMigrate {
+		migrator := ctn.Get(di.Migrator).(*mongoClient.Migrator)
+		if _, err := migrator.Up(context.Background()); err != nil {
+			log.Fatal("error with applying migrations: " + err.Error())
+		}
 	}
-	user2 := userModel.User{
-		UUID: "09fd5cdf-cf73-46a2-bea5-7db7e82797f6",
-		Name: "user2",
+	if path := ctn.Get(di.Config).(*config.Config).Fixtures.Path; path != "" {
+		fixtures := ctn.Get(di.FixtureService).(*fixtureService.FixtureService)
+		if _, err := fixtures.ApplyPath(context.Background(), path); err != nil {
+			log.Fatal("error with loading fixtures: " + err.Error())
+		}
 	}
-	ctn.Get("mongo").(*mongoClient.MongoClient).MongoDatabase.Collection("users").InsertOne(context.Background(), user1)
-	ctn.Get("mongo").(*mongoClient.MongoClient).MongoDatabase.Collection("users").InsertOne(context.Background(), user2)
 
 	app := ctn.Get("app").(*app.App)
 	if err := app.Run(); err != nil {
@@ -1,29 +1,45 @@
 package app
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/elusiv0/medods_test/pkg/httpserver"
 )
 
+// Worker is a background job running alongside http server until its context is cancelled.
+type Worker interface {
+	Run(ctx context.Context)
+}
+
 type App struct {
-	server *httpserver.HttpServer
-	logger *slog.Logger
+	server  *httpserver.HttpServer
+	logger  *slog.Logger
+	workers []Worker
 }
 
 func New(
 	serv *httpserver.HttpServer,
 	log *slog.Logger,
+	workers ...Worker,
 ) *App {
 	app := &App{
-		server: serv,
-		logger: log,
+		server:  serv,
+		logger:  log,
+		workers: workers,
 	}
 
 	return app
 }
 
 func (a *App) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, worker := range a.workers {
+		go worker.Run(ctx)
+	}
+
 	if err := a.server.Start(); err != nil {
 		a.logger.Error("error with up httpserver: " + err.Error())
 		return err
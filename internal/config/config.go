@@ -4,51 +4,211 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/kelseyhightower/envconfig"
+	"github.com/elusiv0/medods_test/pkg/ratelimit"
 )
 
 type (
 	Config struct {
-		Http  HTTP
-		Mongo Mongo
-		App   App
-		Jwt   JWT
+		Http        HTTP
+		Mongo       Mongo
+		App         App
+		Log         Log
+		Reload      Reload
+		Jwt         JWT
+		I18n        I18N
+		RateLimit   RateLimit
+		Lockout     Lockout
+		Rbac        RBAC
+		OAuth       OAuth
+		Tenant      Tenant
+		Policy      Policy
+		ForwardAuth ForwardAuth
+		Audit       Audit
+		Webhook     Webhook
+		Outbox      Outbox
+		Keys        Keys
+		Fixtures    Fixtures
+		Vault       Vault
+		Resilience  Resilience
 	}
 	App struct {
-		Environment string `envconfig:"env" default:"local"`
+		Environment string `env:"ENV" default:"local"`
+	}
+
+	Log struct {
+		Level string `env:"LOG_LEVEL" default:"" reload:"true"`
+	}
+
+	Reload struct {
+		WatchInterval   time.Duration `env:"RELOAD_WATCHINTERVAL" default:"5s"`
+		SecretsInterval time.Duration `env:"RELOAD_SECRETSINTERVAL" default:"1m"`
 	}
 
 	Mongo struct {
-		Host               string        `envconfig:"MONGO_HOST" required="true"`
-		Port               string        `envconfig:"MONGO_PORT" required="true"`
-		User               string        `envconfig:"MONGO_USER" default=""`
-		Password           string        `envconfig:"MONGO_PASSWORD" default=""`
-		DbName             string        `envconfig:"MONGO_DBNAME" required="true"`
-		ConnectionTimeout  time.Duration `envconfig:"MONGO_CONNECTIONTIMEOUT" default="1s"`
-		ConnectionAttempts int           `envconfig:"MONGO_CONNECTIONATTEMPTS" default="10"`
-		AuthDb             string        `envconfig:"MONGO_AUTHDB" default=""`
+		URI                string        `env:"MONGO_URI" default:"" secret:"true"`
+		Host               string        `env:"MONGO_HOST" default:""`
+		Port               string        `env:"MONGO_PORT" default:"27017"`
+		Hosts              []string      `env:"MONGO_HOSTS" default:""`
+		SRV                bool          `env:"MONGO_SRV" default:"false"`
+		ReplicaSet         string        `env:"MONGO_REPLICASET" default:""`
+		User               string        `env:"MONGO_USER" default:""`
+		Password           string        `env:"MONGO_PASSWORD" default:"" secret:"true"`
+		DbName             string        `env:"MONGO_DBNAME" required:"true"`
+		ConnectionTimeout  time.Duration `env:"MONGO_CONNECTIONTIMEOUT" default:"1s"`
+		ConnectionAttempts int           `env:"MONGO_CONNECTIONATTEMPTS" default:"10"`
+		AuthDb             string        `env:"MONGO_AUTHDB" default:""`
+		AutoMigrate        bool          `env:"MONGO_AUTOMIGRATE" default:"true"`
+		TLS                bool          `env:"MONGO_TLS" default:"false"`
+		TLSCAFile          string        `env:"MONGO_TLSCAFILE" default:""`
+		TLSCertFile        string        `env:"MONGO_TLSCERTFILE" default:""`
+		TLSKeyFile         string        `env:"MONGO_TLSKEYFILE" default:""`
+		TLSInsecure        bool          `env:"MONGO_TLSINSECURE" default:"false"`
+		MinPoolSize        int           `env:"MONGO_MINPOOLSIZE" default:"0"`
+		MaxPoolSize        int           `env:"MONGO_MAXPOOLSIZE" default:"0"`
+		ReadPreference     string        `env:"MONGO_READPREFERENCE" default:""`
+		ReadConcern        string        `env:"MONGO_READCONCERN" default:""`
+		WriteConcern       string        `env:"MONGO_WRITECONCERN" default:""`
 	}
 
 	HTTP struct {
-		Host            string        `envconfig:"HTTP_HOST" default="localhost"`
-		Port            string        `envconfig:"HTTP_PORT" default="80"`
-		ReadTimeout     time.Duration `envconfig:"HTTP_READTIMEOUT" default="5s"`
-		WriteTimeout    time.Duration `envconfig:"HTTP_WRITETIMEOUT" default="5s"`
-		ShutdownTimeout time.Duration `envconfig:"HTTP_SHUTDOWNTIMEOUT" default="3s"`
+		Host            string        `env:"HTTP_HOST" default:"localhost"`
+		Port            string        `env:"HTTP_PORT" default:"80"`
+		ReadTimeout     time.Duration `env:"HTTP_READTIMEOUT" default:"5s"`
+		WriteTimeout    time.Duration `env:"HTTP_WRITETIMEOUT" default:"5s"`
+		ShutdownTimeout time.Duration `env:"HTTP_SHUTDOWNTIMEOUT" default:"3s"`
+		TrustedProxies  []string      `env:"HTTP_TRUSTEDPROXIES" default:""`
 	}
 
 	JWT struct {
-		Secret   string        `envconfig:"JWT_SECRET" required="true"`
-		LifeTime time.Duration `envconfig:"JWT_LIFETIME" default="60m"`
+		Secret          string        `env:"JWT_SECRET" required:"true" secret:"true" reload:"true"`
+		PreviousSecrets []string      `env:"JWT_PREVIOUSSECRETS" default:"" secret:"true" reload:"true"`
+		LifeTime        time.Duration `env:"JWT_LIFETIME" default:"60m" reload:"true"`
+		RefreshLifeTime time.Duration `env:"JWT_REFRESHLIFETIME" default:"720h" reload:"true"`
+		SessionMaxAge   time.Duration `env:"JWT_SESSIONMAXAGE" default:"2160h" reload:"true"`
+		SlidingRenewal  bool          `env:"JWT_SLIDINGRENEWAL" default:"true" reload:"true"`
+	}
+
+	I18N struct {
+		DefaultLocale string `env:"I18N_DEFAULTLOCALE" default:"en"`
+		LocalesDir    string `env:"I18N_LOCALESDIR" default:""`
+	}
+
+	RateLimit struct {
+		Store       string          `env:"RATELIMIT_STORE" default:"memory"`
+		Allowlist   []string        `env:"RATELIMIT_ALLOWLIST" default:"" reload:"true"`
+		SignInIP    ratelimit.Limit `env:"RATELIMIT_SIGNIN_IP" default:"20/m" reload:"true"`
+		SignInUser  ratelimit.Limit `env:"RATELIMIT_SIGNIN_USER" default:"5/m" reload:"true"`
+		RefreshIP   ratelimit.Limit `env:"RATELIMIT_REFRESH_IP" default:"60/m" reload:"true"`
+		RefreshUser ratelimit.Limit `env:"RATELIMIT_REFRESH_USER" default:"10/m" reload:"true"`
+	}
+
+	Lockout struct {
+		Threshold     int           `env:"LOCKOUT_THRESHOLD" default:"5"`
+		Duration      time.Duration `env:"LOCKOUT_DURATION" default:"15m"`
+		BaseDelay     time.Duration `env:"LOCKOUT_BASEDELAY" default:"1s"`
+		MaxDelay      time.Duration `env:"LOCKOUT_MAXDELAY" default:"1m"`
+		FailureWindow time.Duration `env:"LOCKOUT_FAILUREWINDOW" default:"15m"`
+	}
+
+	RBAC struct {
+		CacheTTL    time.Duration `env:"RBAC_CACHETTL" default:"30s"`
+		GroupsClaim bool          `env:"RBAC_GROUPSCLAIM" default:"false" reload:"true"`
+	}
+
+	OAuth struct {
+		Clients       []string `env:"OAUTH_CLIENTS" default:"" reload:"true"`
+		DefaultScopes []string `env:"OAUTH_DEFAULTSCOPES" default:"test:read" reload:"true"`
+	}
+
+	Tenant struct {
+		Header   string        `env:"TENANT_HEADER" default:"X-Tenant-ID"`
+		Fallback bool          `env:"TENANT_FALLBACK" default:"true"`
+		CacheTTL time.Duration `env:"TENANT_CACHETTL" default:"30s"`
+	}
+
+	Policy struct {
+		Path            string `env:"POLICY_PATH" default:"" reload:"true"`
+		DryRun          bool   `env:"POLICY_DRYRUN" default:"false" reload:"true"`
+		DecisionLogSize int    `env:"POLICY_DECISIONLOGSIZE" default:"1000"`
+	}
+
+	ForwardAuth struct {
+		Cookie       string        `env:"FORWARDAUTH_COOKIE" default:"access_token"`
+		CacheTTL     time.Duration `env:"FORWARDAUTH_CACHETTL" default:"5s" reload:"true"`
+		CacheSize    int           `env:"FORWARDAUTH_CACHESIZE" default:"10000" reload:"true"`
+		UserHeader   string        `env:"FORWARDAUTH_USERHEADER" default:"X-Auth-User" reload:"true"`
+		TenantHeader string        `env:"FORWARDAUTH_TENANTHEADER" default:"X-Auth-Tenant" reload:"true"`
+		RolesHeader  string        `env:"FORWARDAUTH_ROLESHEADER" default:"X-Auth-Roles" reload:"true"`
+		GroupsHeader string        `env:"FORWARDAUTH_GROUPSHEADER" default:"X-Auth-Groups" reload:"true"`
+		ScopeHeader  string        `env:"FORWARDAUTH_SCOPEHEADER" default:"X-Auth-Scope" reload:"true"`
+		ClientHeader string        `env:"FORWARDAUTH_CLIENTHEADER" default:"X-Auth-Client" reload:"true"`
+	}
+
+	Audit struct {
+		CheckpointInterval int64 `env:"AUDIT_CHECKPOINTINTERVAL" default:"100"`
+	}
+
+	Webhook struct {
+		MaxAttempts  int           `env:"WEBHOOK_MAXATTEMPTS" default:"8"`
+		BaseBackoff  time.Duration `env:"WEBHOOK_BASEBACKOFF" default:"10s"`
+		MaxBackoff   time.Duration `env:"WEBHOOK_MAXBACKOFF" default:"1h"`
+		PollInterval time.Duration `env:"WEBHOOK_POLLINTERVAL" default:"1s"`
+		Lease        time.Duration `env:"WEBHOOK_LEASE" default:"1m"`
+		BatchSize    int           `env:"WEBHOOK_BATCHSIZE" default:"20"`
+		Timeout      time.Duration `env:"WEBHOOK_TIMEOUT" default:"5s"`
+		AllowPrivate bool          `env:"WEBHOOK_ALLOWPRIVATE" default:"false"`
+	}
+
+	Outbox struct {
+		Publisher     string        `env:"OUTBOX_PUBLISHER" default:"memory"`
+		TopicPrefix   string        `env:"OUTBOX_TOPICPREFIX" default:"auth."`
+		NatsURL       string        `env:"OUTBOX_NATS_URL" default:"nats://localhost:4222"`
+		NatsJetStream bool          `env:"OUTBOX_NATS_JETSTREAM" default:"false"`
+		KafkaBrokers  []string      `env:"OUTBOX_KAFKA_BROKERS" default:"localhost:9092"`
+		PollInterval  time.Duration `env:"OUTBOX_POLLINTERVAL" default:"1s"`
+		Lease         time.Duration `env:"OUTBOX_LEASE" default:"30s"`
+		BatchSize     int           `env:"OUTBOX_BATCHSIZE" default:"100"`
+		BaseBackoff   time.Duration `env:"OUTBOX_BASEBACKOFF" default:"1s"`
+		MaxBackoff    time.Duration `env:"OUTBOX_MAXBACKOFF" default:"5m"`
+		Retention     time.Duration `env:"OUTBOX_RETENTION" default:"168h"`
+	}
+
+	Keys struct {
+		ReloadInterval time.Duration `env:"KEYS_RELOADINTERVAL" default:"30s"`
+		RetiredKeyTTL  time.Duration `env:"KEYS_RETIREDKEYTTL" default:"24h" reload:"true"`
+	}
+
+	Fixtures struct {
+		Path string `env:"FIXTURES_PATH" default:""`
+	}
+
+	Resilience struct {
+		Attempts         int           `env:"RESILIENCE_ATTEMPTS" default:"3"`
+		BaseBackoff      time.Duration `env:"RESILIENCE_BASEBACKOFF" default:"50ms"`
+		MaxBackoff       time.Duration `env:"RESILIENCE_MAXBACKOFF" default:"1s"`
+		Timeout          time.Duration `env:"RESILIENCE_TIMEOUT" default:"3s"`
+		BreakerThreshold int           `env:"RESILIENCE_BREAKERTHRESHOLD" default:"5"`
+		BreakerCooldown  time.Duration `env:"RESILIENCE_BREAKERCOOLDOWN" default:"15s"`
+	}
+
+	Vault struct {
+		Address   string        `env:"VAULT_ADDR" default:""`
+		Token     string        `env:"VAULT_TOKEN" default:"" secret:"true"`
+		Namespace string        `env:"VAULT_NAMESPACE" default:""`
+		Timeout   time.Duration `env:"VAULT_TIMEOUT" default:"5s"`
 	}
 )
 
-func GetConfig() (*Config, error) {
-	cfg := Config{}
+// GetConfig builds config from layers applied in order: field defaults, config
+// file (CONFIG_FILE or WithFile), environment and overrides (WithOverrides).
+// Every problem found is reported at once.
+func GetConfig(opts ...Option) (*Config, error) {
+	loader := newLoader(opts...)
 
-	if err := envconfig.Process("", &cfg); err != nil {
-		return nil, fmt.Errorf("error with loading env variables: %w", err)
+	cfg, err := loader.load()
+	if err != nil {
+		return nil, fmt.Errorf("error with loading config: %w", err)
 	}
 
-	return &cfg, nil
+	return cfg, nil
 }
@@ -0,0 +1,421 @@
+package config
+
+import (
+	"context"
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elusiv0/medods_test/pkg/vault"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	fileEnv    = "CONFIG_FILE"
+	fileSuffix = "_FILE"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+type loader struct {
+	file      string
+	overrides map[string]string
+	lookupEnv func(string) (string, bool)
+	// external is set by load when any secret was read from file or vault
+	external bool
+}
+
+type secretRef struct {
+	field    reflect.Value
+	ref      string
+	required bool
+}
+
+type Option func(*loader)
+
+// WithFile reads config file at path instead of CONFIG_FILE, empty path keeps
+// CONFIG_FILE.
+func WithFile(path string) Option {
+	return func(loader *loader) {
+		if path != "" {
+			loader.file = path
+		}
+	}
+}
+
+// WithOverrides sets values that take precedence over every other layer, keys
+// are the same as environment variable names.
+func WithOverrides(overrides map[string]string) Option {
+	return func(loader *loader) {
+		for key, value := range overrides {
+			loader.overrides[strings.ToUpper(key)] = value
+		}
+	}
+}
+
+// Overrides collects repeated -set KEY=VALUE flags.
+type Overrides map[string]string
+
+func (overrides Overrides) String() string {
+	pairs := make([]string, 0, len(overrides))
+	for key, value := range overrides {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
+
+func (overrides Overrides) Set(pair string) error {
+	key, value, found := strings.Cut(pair, "=")
+	if !found || key == "" {
+		return fmt.Errorf("invalid override %q, expected KEY=VALUE", pair)
+	}
+	overrides[strings.ToUpper(key)] = value
+
+	return nil
+}
+
+func newLoader(opts ...Option) *loader {
+	loader := &loader{
+		file:      os.Getenv(fileEnv),
+		overrides: make(map[string]string),
+		lookupEnv: os.LookupEnv,
+	}
+
+	for _, opt := range opts {
+		opt(loader)
+	}
+
+	return loader
+}
+
+// load merges layers and decodes them into Config.
+func (loader *loader) load() (*Config, error) {
+	fileValues := map[string]string{}
+	if loader.file != "" {
+		var err error
+		fileValues, err = readFile(loader.file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	layers := []func(string) (string, bool){
+		lookupMap(fileValues),
+		loader.lookupEnv,
+		lookupMap(loader.overrides),
+	}
+
+	cfg := &Config{}
+	var errs []error
+	known := make(map[string]struct{})
+	refs := make(map[string]secretRef)
+	loader.external = false
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		key := tag.Get("env")
+		secret := tag.Get("secret") == "true"
+		known[key] = struct{}{}
+		if secret {
+			known[key+fileSuffix] = struct{}{}
+		}
+
+		value, found := tag.Lookup("default")
+		for _, layer := range layers {
+			layerValue, ok, err := loader.lookup(layer, key, secret)
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			if ok {
+				value, found = layerValue, true
+			}
+		}
+
+		// required values referenced in vault are checked once resolved
+		if secret && strings.HasPrefix(value, vault.RefPrefix) {
+			refs[key] = secretRef{field: field, ref: value, required: tag.Get("required") == "true"}
+			loader.external = true
+			return
+		}
+		if !found {
+			if tag.Get("required") == "true" {
+				errs = append(errs, fmt.Errorf("%s: required value is missing", key))
+			}
+			return
+		}
+		if tag.Get("required") == "true" && value == "" {
+			errs = append(errs, fmt.Errorf("%s: required value is empty", key))
+			return
+		}
+		if err := setField(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	})
+
+	errs = append(errs, loader.resolveRefs(cfg, refs)...)
+
+	for _, keys := range []map[string]string{fileValues, loader.overrides} {
+		unknown := make([]string, 0)
+		for key := range keys {
+			if _, ok := known[key]; !ok {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+		for _, key := range unknown {
+			errs = append(errs, fmt.Errorf("%s: unknown setting", key))
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// lookup returns value of key in layer, secret may instead be read from file
+// named by <key>_FILE of the same layer.
+func (loader *loader) lookup(layer func(string) (string, bool), key string, secret bool) (string, bool, error) {
+	value, found := layer(key)
+	if !secret {
+		return value, found, nil
+	}
+
+	path, fromFile := layer(key + fileSuffix)
+	if !fromFile {
+		return value, found, nil
+	}
+	if found {
+		return "", false, fmt.Errorf("%s: both %s and %s%s are set", key, key, key, fileSuffix)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("%s%s: %w", key, fileSuffix, err)
+	}
+	loader.external = true
+
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// resolveRefs reads secrets referenced as vault:<path>#<field> once config of
+// vault itself is known, every path is read once.
+func (loader *loader) resolveRefs(cfg *Config, refs map[string]secretRef) []error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(refs))
+	for key := range refs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if cfg.Vault.Address == "" {
+		return []error{fmt.Errorf("VAULT_ADDR: required to resolve %s", strings.Join(keys, ", "))}
+	}
+
+	client := vault.New(
+		cfg.Vault.Address,
+		cfg.Vault.Token,
+		cfg.Vault.Timeout,
+		vault.WithNamespace(cfg.Vault.Namespace),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Vault.Timeout)
+	defer cancel()
+
+	var errs []error
+	secrets := make(map[string]map[string]string)
+	for _, key := range keys {
+		path, name, err := vault.ParseRef(refs[key].ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		if _, ok := secrets[path]; !ok {
+			if secrets[path], err = client.Read(ctx, path); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+		}
+
+		value, ok := secrets[path][name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: field %q of %s: %w", key, name, path, vault.ErrSecretNotFound))
+			continue
+		}
+		if refs[key].required && value == "" {
+			errs = append(errs, fmt.Errorf("%s: required value is empty", key))
+			continue
+		}
+		if err := setField(refs[key].field, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	return errs
+}
+
+func lookupMap(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+// walk calls fn for every field tagged with env, nested structs are walked
+// recursively.
+func walk(value reflect.Value, fn func(field reflect.Value, tag reflect.StructTag)) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+
+		if _, ok := structField.Tag.Lookup("env"); ok {
+			fn(field, structField.Tag)
+			continue
+		}
+		if field.Kind() == reflect.Struct {
+			walk(field, fn)
+		}
+	}
+}
+
+func setField(field reflect.Value, value string) error {
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		field.SetInt(parsed)
+	case reflect.Slice:
+		items := make([]string, 0)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
+
+// readFile reads YAML or TOML config file and flattens it to keys named like
+// environment variables, so "http: {port: 80}" becomes HTTP_PORT.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		document := yaml.Node{}
+		if err = yaml.Unmarshal(data, &document); err == nil && len(document.Content) > 0 {
+			raw, _ = yamlValue(document.Content[0]).(map[string]any)
+		}
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config format, expected .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", raw, values)
+
+	return values, nil
+}
+
+// yamlValue keeps scalars as written, so that secrets and versions looking like
+// numbers are not reformatted.
+func yamlValue(node *yaml.Node) any {
+	switch node.Kind {
+	case yaml.MappingNode:
+		values := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			values[node.Content[i].Value] = yamlValue(node.Content[i+1])
+		}
+		return values
+	case yaml.SequenceNode:
+		values := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			values = append(values, yamlValue(item))
+		}
+		return values
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	default:
+		if node.Tag == "!!null" {
+			return nil
+		}
+		return node.Value
+	}
+}
+
+func flatten(prefix string, raw map[string]any, values map[string]string) {
+	for key, value := range raw {
+		key = strings.ToUpper(key)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch typed := value.(type) {
+		case map[string]any:
+			flatten(key, typed, values)
+		case []any:
+			items := make([]string, 0, len(typed))
+			for _, item := range typed {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(typed)
+		}
+	}
+}
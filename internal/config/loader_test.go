@@ -0,0 +1,110 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func newTestLoader(env map[string]string) *loader {
+	loader := newLoader()
+	loader.file = ""
+	loader.lookupEnv = lookupMap(env)
+
+	return loader
+}
+
+func baseEnv(overrides map[string]string) map[string]string {
+	env := map[string]string{
+		"MONGO_HOST":   "localhost",
+		"MONGO_DBNAME": "medods_test",
+		"JWT_SECRET":   testSecret,
+	}
+	for key, value := range overrides {
+		env[key] = value
+	}
+
+	return env
+}
+
+func newVaultServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestLoadResolvesVaultRef(t *testing.T) {
+	server := newVaultServer(t, `{"data":{"data":{"jwt_secret":"`+testSecret+`"},"metadata":{}}}`)
+
+	cfg, err := newTestLoader(baseEnv(map[string]string{
+		"VAULT_ADDR": server.URL,
+		"JWT_SECRET": "vault:secret/data/auth#jwt_secret",
+	})).load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Jwt.Secret != testSecret {
+		t.Errorf("JWT_SECRET = %q", cfg.Jwt.Secret)
+	}
+}
+
+func TestLoadRejectsEmptyRequiredVaultRef(t *testing.T) {
+	server := newVaultServer(t, `{"data":{"data":{"jwt_secret":""},"metadata":{}}}`)
+
+	_, err := newTestLoader(baseEnv(map[string]string{
+		"VAULT_ADDR": server.URL,
+		"JWT_SECRET": "vault:secret/data/auth#jwt_secret",
+	})).load()
+	if err == nil || !strings.Contains(err.Error(), "JWT_SECRET: required value is empty") {
+		t.Fatalf("err = %v, want empty JWT_SECRET", err)
+	}
+}
+
+func TestLoadRejectsMissingJWTSecret(t *testing.T) {
+	env := baseEnv(nil)
+	delete(env, "JWT_SECRET")
+
+	_, err := newTestLoader(env).load()
+	if err == nil || !strings.Contains(err.Error(), "JWT_SECRET: required value is missing") {
+		t.Fatalf("err = %v, want missing JWT_SECRET", err)
+	}
+}
+
+func TestValidateRejectsEmptyJWTSecret(t *testing.T) {
+	cfg, err := newTestLoader(baseEnv(nil)).load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	cfg.Jwt.Secret = ""
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "JWT_SECRET") {
+		t.Fatalf("err = %v, want JWT_SECRET error", err)
+	}
+}
+
+func TestValidateSessionMaxAge(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"0":    true,
+		"720h": true,
+		"1h":   false,
+		"-1h":  false,
+	} {
+		_, err := newTestLoader(baseEnv(map[string]string{
+			"JWT_REFRESHLIFETIME": "720h",
+			"JWT_SESSIONMAXAGE":   value,
+		})).load()
+		if valid && err != nil {
+			t.Errorf("JWT_SESSIONMAXAGE=%s is rejected: %v", value, err)
+		}
+		if !valid && (err == nil || !strings.Contains(err.Error(), "JWT_SESSIONMAXAGE")) {
+			t.Errorf("JWT_SESSIONMAXAGE=%s err = %v, want JWT_SESSIONMAXAGE error", value, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Reloader rebuilds config when config file changes or SIGHUP is received. New
+// config is applied only when it is valid as a whole, handlers then swap the
+// components they own. Settings not tagged reload take effect after restart.
+type Reloader struct {
+	loader          *loader
+	interval        time.Duration
+	secretsInterval time.Duration
+	logger          *slog.Logger
+
+	mu       sync.Mutex
+	current  *Config
+	loaded   bool
+	modTime  time.Time
+	handlers []func(cfg *Config)
+}
+
+func NewReloader(current *Config, log *slog.Logger, opts ...Option) *Reloader {
+	reloader := &Reloader{
+		loader:          newLoader(opts...),
+		interval:        current.Reload.WatchInterval,
+		secretsInterval: current.Reload.SecretsInterval,
+		logger:          log,
+		current:         current,
+	}
+	reloader.modTime, _ = reloader.fileModTime()
+
+	return reloader
+}
+
+// OnReload registers handler called with every applied config.
+func (reloader *Reloader) OnReload(handler func(cfg *Config)) {
+	reloader.mu.Lock()
+	defer reloader.mu.Unlock()
+
+	reloader.handlers = append(reloader.handlers, handler)
+}
+
+// Current returns config applied last.
+func (reloader *Reloader) Current() *Config {
+	reloader.mu.Lock()
+	defer reloader.mu.Unlock()
+
+	return reloader.current
+}
+
+// Reload rebuilds config and applies it when valid, invalid config is logged and
+// the current one is kept.
+func (reloader *Reloader) Reload() error {
+	reloader.mu.Lock()
+	defer reloader.mu.Unlock()
+
+	next, err := reloader.loader.load()
+	reloader.loaded = true
+	if err != nil {
+		reloader.logger.Error("ConfigReloader: config rejected, keeping current one: " + err.Error())
+		return err
+	}
+
+	changes := reloader.current.Diff(next)
+	if len(changes) == 0 {
+		reloader.logger.Debug("ConfigReloader: config unchanged")
+		return nil
+	}
+	for _, change := range changes {
+		attrs := []any{
+			slog.String("key", change.Key),
+			slog.String("old", change.Old),
+			slog.String("new", change.New),
+		}
+		if change.Reloadable {
+			reloader.logger.Info("ConfigReloader: setting changed", attrs...)
+		} else {
+			reloader.logger.Warn("ConfigReloader: setting changed, restart is required to apply it", attrs...)
+		}
+	}
+
+	for _, handler := range reloader.handlers {
+		handler(next)
+	}
+	reloader.current = next
+
+	return nil
+}
+
+// Run reloads config on SIGHUP and on config file modification until ctx is
+// cancelled. Secrets read from files or vault are re-read every secretsInterval
+// so that rotated ones are picked up.
+func (reloader *Reloader) Run(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(reloader.interval)
+	defer ticker.Stop()
+	secretsTicker := time.NewTicker(reloader.secretsInterval)
+	defer secretsTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			reloader.logger.Info("ConfigReloader: SIGHUP received")
+			_ = reloader.Reload()
+		case <-secretsTicker.C:
+			if reloader.external() {
+				_ = reloader.Reload()
+			}
+		case <-ticker.C:
+			modTime, err := reloader.fileModTime()
+			if err != nil {
+				reloader.logger.Error("ConfigReloader - Run: " + err.Error())
+				continue
+			}
+			if modTime.Equal(reloader.modTime) {
+				continue
+			}
+			reloader.modTime = modTime
+			reloader.logger.Info("ConfigReloader: config file changed", slog.String("file", reloader.loader.file))
+			_ = reloader.Reload()
+		}
+	}
+}
+
+// external reports whether the last loaded config read any secret from file or
+// vault, the initial config is assumed to.
+func (reloader *Reloader) external() bool {
+	reloader.mu.Lock()
+	defer reloader.mu.Unlock()
+
+	return reloader.loader.external || !reloader.loaded
+}
+
+func (reloader *Reloader) fileModTime() (time.Time, error) {
+	if reloader.loader.file == "" {
+		return time.Time{}, nil
+	}
+
+	info, err := os.Stat(reloader.loader.file)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.ModTime(), nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	redacted = "<redacted>"
+)
+
+// Setting is a config value keyed by its environment variable name.
+type Setting struct {
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	Secret     bool   `json:"secret,omitempty"`
+	Reloadable bool   `json:"reloadable,omitempty"`
+}
+
+// Change is a setting whose value differs between two configs, secrets are
+// redacted.
+type Change struct {
+	Key        string
+	Old        string
+	New        string
+	Reloadable bool
+}
+
+// Settings lists effective values in declaration order, non-empty secrets are
+// redacted.
+func (cfg *Config) Settings() []Setting {
+	settings := cfg.settings()
+	for i := range settings {
+		if settings[i].Secret && settings[i].Value != "" {
+			settings[i].Value = redacted
+		}
+	}
+
+	return settings
+}
+
+// Diff lists settings changed in next compared to cfg.
+func (cfg *Config) Diff(next *Config) []Change {
+	previous, current := cfg.settings(), next.settings()
+
+	changes := make([]Change, 0)
+	for i, setting := range current {
+		if setting.Value == previous[i].Value {
+			continue
+		}
+
+		change := Change{
+			Key:        setting.Key,
+			Old:        previous[i].Value,
+			New:        setting.Value,
+			Reloadable: setting.Reloadable,
+		}
+		if setting.Secret {
+			change.Old, change.New = redacted, redacted
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func (cfg *Config) settings() []Setting {
+	settings := make([]Setting, 0)
+	walk(reflect.ValueOf(cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		settings = append(settings, Setting{
+			Key:        tag.Get("env"),
+			Value:      formatField(field),
+			Secret:     tag.Get("secret") == "true",
+			Reloadable: tag.Get("reload") == "true",
+		})
+	})
+
+	return settings
+}
+
+func formatField(field reflect.Value) string {
+	if stringer, ok := field.Interface().(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	if field.Kind() == reflect.Slice {
+		items := make([]string, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			items = append(items, fmt.Sprint(field.Index(i).Interface()))
+		}
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprint(field.Interface())
+}
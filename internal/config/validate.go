@@ -0,0 +1,171 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	envUtil "github.com/elusiv0/medods_test/internal/util/env"
+	"github.com/elusiv0/medods_test/pkg/logger"
+	"github.com/elusiv0/medods_test/pkg/policy"
+	"github.com/elusiv0/medods_test/pkg/ratelimit"
+	"github.com/elusiv0/medods_test/pkg/scope"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	minJWTSecretLength = 32
+)
+
+// Validate checks values that decode fine but can not work, every problem is
+// reported in the returned error.
+func (cfg *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key string, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+	positive := func(key string, value time.Duration) {
+		check(value > 0, key, "must be positive, got %s", value)
+	}
+	oneOf := func(key string, value string, allowed ...string) {
+		for _, item := range allowed {
+			if value == item {
+				return
+			}
+		}
+		check(false, key, "must be one of %v, got %q", allowed, value)
+	}
+
+	oneOf("ENV", cfg.App.Environment, envUtil.All...)
+	if _, err := logger.ParseLevel(cfg.App.Environment, cfg.Log.Level); err != nil {
+		check(false, "LOG_LEVEL", "%s", err.Error())
+	}
+	positive("RELOAD_WATCHINTERVAL", cfg.Reload.WatchInterval)
+	positive("RELOAD_SECRETSINTERVAL", cfg.Reload.SecretsInterval)
+	positive("VAULT_TIMEOUT", cfg.Vault.Timeout)
+
+	check(cfg.Resilience.Attempts > 0, "RESILIENCE_ATTEMPTS", "must be positive, got %d", cfg.Resilience.Attempts)
+	positive("RESILIENCE_BASEBACKOFF", cfg.Resilience.BaseBackoff)
+	check(
+		cfg.Resilience.MaxBackoff >= cfg.Resilience.BaseBackoff,
+		"RESILIENCE_MAXBACKOFF", "must not be less than RESILIENCE_BASEBACKOFF",
+	)
+	positive("RESILIENCE_TIMEOUT", cfg.Resilience.Timeout)
+	check(cfg.Resilience.BreakerThreshold > 0, "RESILIENCE_BREAKERTHRESHOLD", "must be positive, got %d", cfg.Resilience.BreakerThreshold)
+	positive("RESILIENCE_BREAKERCOOLDOWN", cfg.Resilience.BreakerCooldown)
+
+	check(
+		cfg.Mongo.URI != "" || cfg.Mongo.Host != "" || len(cfg.Mongo.Hosts) > 0,
+		"MONGO_HOST", "one of MONGO_URI, MONGO_HOST or MONGO_HOSTS is required",
+	)
+	check(
+		!cfg.Mongo.SRV || cfg.Mongo.URI == "" && len(cfg.Mongo.Hosts) == 0,
+		"MONGO_SRV", "requires single MONGO_HOST, its port is ignored",
+	)
+	check(
+		(cfg.Mongo.TLSCertFile == "") == (cfg.Mongo.TLSKeyFile == ""),
+		"MONGO_TLSCERTFILE", "must be set together with MONGO_TLSKEYFILE",
+	)
+	check(cfg.Mongo.MinPoolSize >= 0, "MONGO_MINPOOLSIZE", "must not be negative, got %d", cfg.Mongo.MinPoolSize)
+	check(cfg.Mongo.MaxPoolSize >= 0, "MONGO_MAXPOOLSIZE", "must not be negative, got %d", cfg.Mongo.MaxPoolSize)
+	check(
+		cfg.Mongo.MaxPoolSize == 0 || cfg.Mongo.MinPoolSize <= cfg.Mongo.MaxPoolSize,
+		"MONGO_MINPOOLSIZE", "must not exceed MONGO_MAXPOOLSIZE",
+	)
+	if cfg.Mongo.ReadPreference != "" {
+		if _, err := readpref.ModeFromString(cfg.Mongo.ReadPreference); err != nil {
+			check(false, "MONGO_READPREFERENCE", "%s", err.Error())
+		}
+	}
+	if cfg.Mongo.ReadConcern != "" {
+		oneOf("MONGO_READCONCERN", cfg.Mongo.ReadConcern, "local", "available", "majority", "linearizable", "snapshot")
+	}
+	positive("MONGO_CONNECTIONTIMEOUT", cfg.Mongo.ConnectionTimeout)
+	check(cfg.Mongo.ConnectionAttempts > 0, "MONGO_CONNECTIONATTEMPTS", "must be positive, got %d", cfg.Mongo.ConnectionAttempts)
+
+	positive("HTTP_READTIMEOUT", cfg.Http.ReadTimeout)
+	positive("HTTP_WRITETIMEOUT", cfg.Http.WriteTimeout)
+	positive("HTTP_SHUTDOWNTIMEOUT", cfg.Http.ShutdownTimeout)
+	if _, err := ratelimit.ParseAllowlist(cfg.Http.TrustedProxies); err != nil {
+		check(false, "HTTP_TRUSTEDPROXIES", "%s", err.Error())
+	}
+
+	check(
+		len(cfg.Jwt.Secret) >= minJWTSecretLength,
+		"JWT_SECRET", "must be at least %d bytes long, got %d", minJWTSecretLength, len(cfg.Jwt.Secret),
+	)
+	positive("JWT_LIFETIME", cfg.Jwt.LifeTime)
+	positive("JWT_REFRESHLIFETIME", cfg.Jwt.RefreshLifeTime)
+	// zero session max age disables the limit
+	check(cfg.Jwt.SessionMaxAge >= 0, "JWT_SESSIONMAXAGE", "must not be negative, got %s", cfg.Jwt.SessionMaxAge)
+	check(
+		cfg.Jwt.SessionMaxAge == 0 || cfg.Jwt.SessionMaxAge >= cfg.Jwt.RefreshLifeTime,
+		"JWT_SESSIONMAXAGE", "must not be shorter than JWT_REFRESHLIFETIME",
+	)
+
+	oneOf("RATELIMIT_STORE", cfg.RateLimit.Store, "memory", "mongo")
+	if _, err := ratelimit.ParseAllowlist(cfg.RateLimit.Allowlist); err != nil {
+		check(false, "RATELIMIT_ALLOWLIST", "%s", err.Error())
+	}
+
+	check(cfg.Lockout.Threshold > 0, "LOCKOUT_THRESHOLD", "must be positive, got %d", cfg.Lockout.Threshold)
+	positive("LOCKOUT_DURATION", cfg.Lockout.Duration)
+	positive("LOCKOUT_FAILUREWINDOW", cfg.Lockout.FailureWindow)
+	check(cfg.Lockout.MaxDelay >= cfg.Lockout.BaseDelay, "LOCKOUT_MAXDELAY", "must not be less than LOCKOUT_BASEDELAY")
+
+	positive("RBAC_CACHETTL", cfg.Rbac.CacheTTL)
+	if _, err := scope.ParseClients(cfg.OAuth.Clients); err != nil {
+		check(false, "OAUTH_CLIENTS", "%s", err.Error())
+	}
+	if _, err := scope.Parse(strings.Join(cfg.OAuth.DefaultScopes, " ")); err != nil {
+		check(false, "OAUTH_DEFAULTSCOPES", "%s", err.Error())
+	}
+	check(cfg.Tenant.Header != "", "TENANT_HEADER", "must not be empty")
+	positive("TENANT_CACHETTL", cfg.Tenant.CacheTTL)
+	if cfg.Policy.Path != "" {
+		if _, err := policy.Load(cfg.Policy.Path); err != nil {
+			check(false, "POLICY_PATH", "%s", err.Error())
+		}
+	}
+	check(cfg.Policy.DecisionLogSize >= 0, "POLICY_DECISIONLOGSIZE", "must not be negative, got %d", cfg.Policy.DecisionLogSize)
+	check(cfg.ForwardAuth.CacheTTL >= 0, "FORWARDAUTH_CACHETTL", "must not be negative, got %s", cfg.ForwardAuth.CacheTTL)
+	check(cfg.ForwardAuth.CacheSize > 0, "FORWARDAUTH_CACHESIZE", "must be positive, got %d", cfg.ForwardAuth.CacheSize)
+
+	check(cfg.Audit.CheckpointInterval > 0, "AUDIT_CHECKPOINTINTERVAL", "must be positive, got %d", cfg.Audit.CheckpointInterval)
+
+	check(cfg.Webhook.MaxAttempts > 0, "WEBHOOK_MAXATTEMPTS", "must be positive, got %d", cfg.Webhook.MaxAttempts)
+	positive("WEBHOOK_BASEBACKOFF", cfg.Webhook.BaseBackoff)
+	positive("WEBHOOK_MAXBACKOFF", cfg.Webhook.MaxBackoff)
+	positive("WEBHOOK_POLLINTERVAL", cfg.Webhook.PollInterval)
+	positive("WEBHOOK_LEASE", cfg.Webhook.Lease)
+	positive("WEBHOOK_TIMEOUT", cfg.Webhook.Timeout)
+	check(cfg.Webhook.BatchSize > 0, "WEBHOOK_BATCHSIZE", "must be positive, got %d", cfg.Webhook.BatchSize)
+	check(
+		!cfg.Webhook.AllowPrivate || envUtil.IsNonProduction(cfg.App.Environment),
+		"WEBHOOK_ALLOWPRIVATE", "private webhook addresses are allowed only with ENV one of %v", envUtil.NonProduction,
+	)
+
+	oneOf("OUTBOX_PUBLISHER", cfg.Outbox.Publisher, "memory", "nats", "kafka")
+	check(
+		cfg.Outbox.Publisher != "memory" || envUtil.IsNonProduction(cfg.App.Environment),
+		"OUTBOX_PUBLISHER", "memory publisher drops events, it is allowed only with ENV one of %v", envUtil.NonProduction,
+	)
+	positive("OUTBOX_POLLINTERVAL", cfg.Outbox.PollInterval)
+	positive("OUTBOX_LEASE", cfg.Outbox.Lease)
+	positive("OUTBOX_BASEBACKOFF", cfg.Outbox.BaseBackoff)
+	positive("OUTBOX_MAXBACKOFF", cfg.Outbox.MaxBackoff)
+	check(
+		cfg.Outbox.Retention >= time.Second && cfg.Outbox.Retention.Seconds() <= math.MaxInt32,
+		"OUTBOX_RETENTION", "must be between 1s and %d seconds, got %s", math.MaxInt32, cfg.Outbox.Retention,
+	)
+	check(cfg.Outbox.BatchSize > 0, "OUTBOX_BATCHSIZE", "must be positive, got %d", cfg.Outbox.BatchSize)
+
+	positive("KEYS_RELOADINTERVAL", cfg.Keys.ReloadInterval)
+	positive("KEYS_RETIREDKEYTTL", cfg.Keys.RetiredKeyTTL)
+
+	return errors.Join(errs...)
+}
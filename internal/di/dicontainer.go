@@ -4,36 +4,97 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/elusiv0/medods_test/internal/app"
 	"github.com/elusiv0/medods_test/internal/config"
+	rateLimitMiddleware "github.com/elusiv0/medods_test/internal/middleware/ratelimit"
+	"github.com/elusiv0/medods_test/internal/migrations"
+	"github.com/elusiv0/medods_test/internal/model/api"
+	"github.com/elusiv0/medods_test/internal/repo"
+	auditRepository "github.com/elusiv0/medods_test/internal/repo/audit"
+	groupRepository "github.com/elusiv0/medods_test/internal/repo/group"
+	keyRepository "github.com/elusiv0/medods_test/internal/repo/key"
+	lockoutRepository "github.com/elusiv0/medods_test/internal/repo/lockout"
+	outboxRepository "github.com/elusiv0/medods_test/internal/repo/outbox"
+	resilientRepository "github.com/elusiv0/medods_test/internal/repo/resilient"
+	roleRepository "github.com/elusiv0/medods_test/internal/repo/role"
+	tenantRepository "github.com/elusiv0/medods_test/internal/repo/tenant"
 	tokenRepository "github.com/elusiv0/medods_test/internal/repo/token"
 	userRepository "github.com/elusiv0/medods_test/internal/repo/user"
+	webhookRepository "github.com/elusiv0/medods_test/internal/repo/webhook"
 	httpRouter "github.com/elusiv0/medods_test/internal/router/http"
+	authRouter "github.com/elusiv0/medods_test/internal/router/http/v1/auth"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
 	authService "github.com/elusiv0/medods_test/internal/service/auth"
+	fixtureService "github.com/elusiv0/medods_test/internal/service/fixture"
+	forwardAuthService "github.com/elusiv0/medods_test/internal/service/forwardauth"
+	groupService "github.com/elusiv0/medods_test/internal/service/group"
+	keyService "github.com/elusiv0/medods_test/internal/service/key"
+	lockoutService "github.com/elusiv0/medods_test/internal/service/lockout"
+	outboxService "github.com/elusiv0/medods_test/internal/service/outbox"
+	policyService "github.com/elusiv0/medods_test/internal/service/policy"
+	roleService "github.com/elusiv0/medods_test/internal/service/role"
+	tenantService "github.com/elusiv0/medods_test/internal/service/tenant"
+	userService "github.com/elusiv0/medods_test/internal/service/user"
+	webhookService "github.com/elusiv0/medods_test/internal/service/webhook"
+	envUtil "github.com/elusiv0/medods_test/internal/util/env"
 	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
 	"github.com/elusiv0/medods_test/pkg/httpserver"
+	"github.com/elusiv0/medods_test/pkg/i18n"
 	"github.com/elusiv0/medods_test/pkg/logger"
 	mongo "github.com/elusiv0/medods_test/pkg/mongo"
+	"github.com/elusiv0/medods_test/pkg/publisher"
+	"github.com/elusiv0/medods_test/pkg/ratelimit"
+	"github.com/elusiv0/medods_test/pkg/resilience"
+	"github.com/elusiv0/medods_test/pkg/scope"
+	"github.com/elusiv0/medods_test/pkg/webhook"
 	"github.com/gin-gonic/gin"
 	"github.com/sarulabs/di/v2"
 )
 
 const (
-	Config          = "config"
-	Logger          = "logger"
-	App             = "app"
-	Router          = "router"
-	Httpserver      = "httpserver"
-	TokenManager    = "tokenManager"
-	Mongo           = "mongo"
-	TokenRepository = "tokenRepository"
-	UserRepository  = "userRepository"
-	AuthService     = "authService"
+	Config             = "config"
+	Logger             = "logger"
+	LogLevel           = "logLevel"
+	ConfigReloader     = "configReloader"
+	App                = "app"
+	Router             = "router"
+	Httpserver         = "httpserver"
+	TokenManager       = "tokenManager"
+	Mongo              = "mongo"
+	TokenRepository    = "tokenRepository"
+	UserRepository     = "userRepository"
+	AuthService        = "authService"
+	I18n               = "i18n"
+	RateLimiter        = "rateLimiter"
+	LockoutRepository  = "lockoutRepository"
+	LockoutService     = "lockoutService"
+	AuditRepository    = "auditRepository"
+	AuditService       = "auditService"
+	WebhookRepository  = "webhookRepository"
+	WebhookService     = "webhookService"
+	Publisher          = "publisher"
+	OutboxRepository   = "outboxRepository"
+	OutboxService      = "outboxService"
+	UserService        = "userService"
+	Migrator           = "migrator"
+	KeyRepository      = "keyRepository"
+	KeyService         = "keyService"
+	FixtureService     = "fixtureService"
+	RepoExecutor       = "repoExecutor"
+	RoleRepository     = "roleRepository"
+	RoleService        = "roleService"
+	GroupRepository    = "groupRepository"
+	GroupService       = "groupService"
+	TenantRepository   = "tenantRepository"
+	TenantService      = "tenantService"
+	PolicyService      = "policyService"
+	ForwardAuthService = "forwardAuthService"
 )
 
-func InitContainer() (di.Container, error) {
-	builder, err := initBuilder()
+func InitContainer(opts ...config.Option) (di.Container, error) {
+	builder, err := initBuilder(opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -43,47 +104,134 @@ func InitContainer() (di.Container, error) {
 	return container, nil
 }
 
-func initBuilder() (*di.Builder, error) {
+func initBuilder(opts ...config.Option) (*di.Builder, error) {
 	builder, err := di.NewBuilder()
 
 	if err != nil {
 		return nil, fmt.Errorf("error with building di container: %w", err)
 	}
 
-	RegisterDeps(builder)
+	RegisterDeps(builder, opts...)
 
 	return builder, nil
 }
 
-func RegisterDeps(b *di.Builder) {
+func RegisterDeps(b *di.Builder, opts ...config.Option) {
 	//building config
 	b.Add(di.Def{
 		Name: Config,
 		Build: func(ctn di.Container) (interface{}, error) {
-			return config.GetConfig()
+			return config.GetConfig(opts...)
 		},
 	})
 
 	//building logger
+	b.Add(di.Def{
+		Name: LogLevel,
+		Build: func(ctn di.Container) (interface{}, error) {
+			cfg := ctn.Get("config").(*config.Config)
+
+			level, err := logger.ParseLevel(cfg.App.Environment, cfg.Log.Level)
+			if err != nil {
+				return nil, err
+			}
+			levelVar := &slog.LevelVar{}
+			levelVar.Set(level)
+
+			return levelVar, nil
+		},
+	})
 	b.Add(di.Def{
 		Name: Logger,
 		Build: func(ctn di.Container) (interface{}, error) {
 			return logger.New(
 				ctn.Get("config").(*config.Config).App.Environment,
+				ctn.Get("logLevel").(*slog.LevelVar),
 			), nil
 		},
 	})
 
+	//building config reloader
+	b.Add(di.Def{
+		Name: ConfigReloader,
+		Build: func(ctn di.Container) (interface{}, error) {
+			cfg := ctn.Get("config").(*config.Config)
+			log := ctn.Get("logger").(*slog.Logger)
+			levelVar := ctn.Get("logLevel").(*slog.LevelVar)
+			tokenManager := ctn.Get("tokenManager").(*tokenManager.TokenManager)
+			authService := ctn.Get("authService").(*authService.AuthService)
+			keyService := ctn.Get("keyService").(*keyService.KeyService)
+			limiter := ctn.Get("rateLimiter").(*rateLimitMiddleware.Limiter)
+			policyService := ctn.Get("policyService").(*policyService.PolicyService)
+			forwardAuthService := ctn.Get("forwardAuthService").(*forwardAuthService.ForwardAuthService)
+
+			reloader := config.NewReloader(cfg, log, opts...)
+			reloader.OnReload(func(cfg *config.Config) {
+				level, _ := logger.ParseLevel(cfg.App.Environment, cfg.Log.Level)
+				levelVar.Set(level)
+			})
+			reloader.OnReload(func(cfg *config.Config) {
+				tokenManager.SetLifeTime(cfg.Jwt.LifeTime)
+				tokenManager.SetSecrets(cfg.Jwt.Secret, cfg.Jwt.PreviousSecrets)
+				authService.SetPolicy(authPolicy(cfg))
+			})
+			reloader.OnReload(func(cfg *config.Config) {
+				keyService.SetPolicy(keyPolicy(cfg))
+				if err := keyService.Load(context.Background()); err != nil {
+					log.Error("ConfigReloader - KeyService: " + err.Error())
+				}
+			})
+			reloader.OnReload(func(cfg *config.Config) {
+				allowlist, _ := ratelimit.ParseAllowlist(cfg.RateLimit.Allowlist)
+				limiter.SetRules(allowlist, rateLimitRules(cfg))
+			})
+			reloader.OnReload(func(cfg *config.Config) {
+				policyService.SetDryRun(cfg.Policy.DryRun)
+				if err := policyService.Load(cfg.Policy.Path); err != nil {
+					log.Error("ConfigReloader - PolicyService: " + err.Error())
+				}
+			})
+			reloader.OnReload(func(cfg *config.Config) {
+				forwardAuthService.SetPolicy(forwardAuthPolicy(cfg))
+			})
+
+			return reloader, nil
+		},
+	})
+
+	//building message catalog
+	b.Add(di.Def{
+		Name: I18n,
+		Build: func(ctn di.Container) (interface{}, error) {
+			cfg := ctn.Get("config").(*config.Config)
+
+			catalog := i18n.New(cfg.I18n.DefaultLocale)
+			if err := catalog.LoadFS(api.Locales, api.LocalesDir); err != nil {
+				return nil, err
+			}
+			if cfg.I18n.LocalesDir != "" {
+				if err := catalog.LoadDir(cfg.I18n.LocalesDir); err != nil {
+					return nil, err
+				}
+			}
+
+			return catalog, nil
+		},
+	})
+
 	//building token manager
 	b.Add(di.Def{
 		Name: TokenManager,
 		Build: func(ctn di.Container) (interface{}, error) {
 			cfg := ctn.Get("config").(*config.Config)
 
-			return tokenManager.New(
+			manager := tokenManager.New(
 				cfg.Jwt.LifeTime,
 				cfg.Jwt.Secret,
-			), nil
+			)
+			manager.SetSecrets(cfg.Jwt.Secret, cfg.Jwt.PreviousSecrets)
+
+			return manager, nil
 		},
 	})
 
@@ -92,15 +240,54 @@ func RegisterDeps(b *di.Builder) {
 		Name: Mongo,
 		Build: func(ctn di.Container) (interface{}, error) {
 			cfg := ctn.Get("config").(*config.Config)
-			fmt.Println(cfg.Mongo.Host)
 			logger := ctn.Get("logger").(*slog.Logger)
-			mongoConn := mongo.NewMongoConn(
-				cfg.Mongo.Host,
-				cfg.Mongo.Port,
-				cfg.Mongo.DbName,
+
+			opts := []mongo.ConnOpt{
 				mongo.WithConnectionAttempts(cfg.Mongo.ConnectionAttempts),
 				mongo.WithTimeout(cfg.Mongo.ConnectionTimeout),
-				mongo.WithCredentials(cfg.Mongo.User, cfg.Mongo.Password),
+				mongo.WithHosts(cfg.Mongo.Hosts...),
+				mongo.WithReplicaSet(cfg.Mongo.ReplicaSet),
+				mongo.WithPoolSize(uint64(cfg.Mongo.MinPoolSize), uint64(cfg.Mongo.MaxPoolSize)),
+				mongo.WithReadPreference(cfg.Mongo.ReadPreference),
+				mongo.WithReadConcern(cfg.Mongo.ReadConcern),
+				mongo.WithWriteConcern(cfg.Mongo.WriteConcern),
+			}
+			if cfg.Mongo.URI != "" {
+				opts = append(opts, mongo.WithURI(cfg.Mongo.URI))
+			}
+			if cfg.Mongo.SRV {
+				opts = append(opts, mongo.WithSRV())
+			}
+			if cfg.Mongo.User != "" {
+				opts = append(opts, mongo.WithCredentials(cfg.Mongo.User, cfg.Mongo.Password))
+			}
+			if cfg.Mongo.AuthDb != "" {
+				opts = append(opts, mongo.WithAuthDb(cfg.Mongo.AuthDb))
+			}
+			if envUtil.IsNonProduction(cfg.App.Environment) {
+				opts = append(opts, mongo.WithStandalone())
+			}
+			if cfg.Mongo.TLS {
+				opts = append(opts, mongo.WithTLS(mongo.TLS{
+					CAFile:   cfg.Mongo.TLSCAFile,
+					CertFile: cfg.Mongo.TLSCertFile,
+					KeyFile:  cfg.Mongo.TLSKeyFile,
+					Insecure: cfg.Mongo.TLSInsecure,
+				}))
+			}
+
+			host, port := cfg.Mongo.Host, cfg.Mongo.Port
+			if cfg.Mongo.URI != "" {
+				host = ""
+			}
+			if cfg.Mongo.SRV {
+				port = ""
+			}
+			mongoConn := mongo.NewMongoConn(
+				host,
+				port,
+				cfg.Mongo.DbName,
+				opts...,
 			)
 
 			return mongo.New(
@@ -111,46 +298,531 @@ func RegisterDeps(b *di.Builder) {
 		},
 	})
 
+	//building migrator
+	b.Add(di.Def{
+		Name: Migrator,
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			return mongo.NewMigrator(
+				mongoClient,
+				migrations.All(migrations.Settings{
+					OutboxRetention: cfg.Outbox.Retention,
+				}),
+				logger,
+			), nil
+		},
+	})
+
+	//building resilience executor of mongo calls
+	b.Add(di.Def{
+		Name: RepoExecutor,
+		Build: func(ctn di.Container) (interface{}, error) {
+			cfg := ctn.Get("config").(*config.Config)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return resilience.NewExecutor(
+				resilience.Policy{
+					Attempts:    cfg.Resilience.Attempts,
+					BaseBackoff: cfg.Resilience.BaseBackoff,
+					MaxBackoff:  cfg.Resilience.MaxBackoff,
+					Timeout:     cfg.Resilience.Timeout,
+				},
+				resilience.NewBreaker(
+					"mongo",
+					cfg.Resilience.BreakerThreshold,
+					cfg.Resilience.BreakerCooldown,
+					logger,
+				),
+				mongo.IsRetryable,
+				logger,
+			), nil
+		},
+	})
+
 	//building repositories
 	b.Add(di.Def{
 		Name: TokenRepository,
 		Build: func(ctn di.Container) (interface{}, error) {
 			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			executor := ctn.Get("repoExecutor").(*resilience.Executor)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return resilientRepository.NewTokenRepo(
+				tokenRepository.New(
+					mongoClient,
+					logger,
+				),
+				executor,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: UserRepository,
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			executor := ctn.Get("repoExecutor").(*resilience.Executor)
 			logger := ctn.Get("logger").(*slog.Logger)
 
-			return tokenRepository.New(
+			return resilientRepository.NewUserRepo(
+				userRepository.New(
+					mongoClient,
+					logger,
+				),
+				executor,
+			), nil
+		},
+	})
+
+	b.Add(di.Def{
+		Name: LockoutRepository,
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return lockoutRepository.New(
 				mongoClient,
 				logger,
 			), nil
 		},
 	})
+
 	b.Add(di.Def{
-		Name: UserRepository,
+		Name: AuditRepository,
 		Build: func(ctn di.Container) (interface{}, error) {
 			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
 			logger := ctn.Get("logger").(*slog.Logger)
 
-			return userRepository.New(
+			return auditRepository.New(
 				mongoClient,
 				logger,
 			), nil
 		},
 	})
 
+	b.Add(di.Def{
+		Name: WebhookRepository,
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return webhookRepository.New(
+				mongoClient,
+				logger,
+			), nil
+		},
+	})
+
+	b.Add(di.Def{
+		Name: RoleRepository,
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			executor := ctn.Get("repoExecutor").(*resilience.Executor)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return resilientRepository.NewRoleRepo(
+				roleRepository.New(
+					mongoClient,
+					logger,
+				),
+				executor,
+			), nil
+		},
+	})
+
+	b.Add(di.Def{
+		Name: GroupRepository,
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			executor := ctn.Get("repoExecutor").(*resilience.Executor)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return resilientRepository.NewGroupRepo(
+				groupRepository.New(
+					mongoClient,
+					logger,
+				),
+				executor,
+			), nil
+		},
+	})
+
+	b.Add(di.Def{
+		Name: TenantRepository,
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			executor := ctn.Get("repoExecutor").(*resilience.Executor)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return resilientRepository.NewTenantRepo(
+				tenantRepository.New(
+					mongoClient,
+					logger,
+				),
+				executor,
+			), nil
+		},
+	})
+
+	b.Add(di.Def{
+		Name: OutboxRepository,
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return outboxRepository.New(
+				mongoClient,
+				logger,
+			), nil
+		},
+	})
+
+	b.Add(di.Def{
+		Name: KeyRepository,
+		Build: func(ctn di.Container) (interface{}, error) {
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return keyRepository.New(
+				mongoClient,
+				logger,
+			), nil
+		},
+	})
+
+	//building publisher
+	b.Add(di.Def{
+		Name: Publisher,
+		Build: func(ctn di.Container) (interface{}, error) {
+			cfg := ctn.Get("config").(*config.Config)
+
+			switch cfg.Outbox.Publisher {
+			case "nats":
+				return publisher.NewNATSPublisher(cfg.Outbox.NatsURL, cfg.Outbox.NatsJetStream)
+			case "kafka":
+				return publisher.NewKafkaPublisher(cfg.Outbox.KafkaBrokers), nil
+			case "memory":
+				return publisher.NewMemoryPublisher(), nil
+			default:
+				return nil, fmt.Errorf("unknown outbox publisher: %s", cfg.Outbox.Publisher)
+			}
+		},
+	})
+
 	//building services
+	b.Add(di.Def{
+		Name: OutboxService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			outboxRepo := ctn.Get("outboxRepository").(*outboxRepository.OutboxRepo)
+			publisher := ctn.Get("publisher").(publisher.Publisher)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			return outboxService.New(
+				outboxRepo,
+				publisher,
+				outboxService.Policy{
+					TopicPrefix:  cfg.Outbox.TopicPrefix,
+					PollInterval: cfg.Outbox.PollInterval,
+					Lease:        cfg.Outbox.Lease,
+					BatchSize:    cfg.Outbox.BatchSize,
+					BaseBackoff:  cfg.Outbox.BaseBackoff,
+					MaxBackoff:   cfg.Outbox.MaxBackoff,
+				},
+				logger,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: KeyService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			keyRepo := ctn.Get("keyRepository").(*keyRepository.KeyRepo)
+			tokenManager := ctn.Get("tokenManager").(*tokenManager.TokenManager)
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			service := keyService.New(
+				keyRepo,
+				tokenManager,
+				auditService,
+				keyPolicy(cfg),
+				logger,
+			)
+			if err := service.Load(context.Background()); err != nil {
+				return nil, err
+			}
+
+			return service, nil
+		},
+	})
+	b.Add(di.Def{
+		Name: FixtureService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			userRepo := ctn.Get("userRepository").(repo.UserRepo)
+			roleRepo := ctn.Get("roleRepository").(repo.RoleRepo)
+			tenantRepo := ctn.Get("tenantRepository").(repo.TenantRepo)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			return fixtureService.New(
+				userRepo,
+				roleRepo,
+				tenantRepo,
+				cfg.App.Environment,
+				logger,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: UserService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			userRepo := ctn.Get("userRepository").(repo.UserRepo)
+			tokenRepo := ctn.Get("tokenRepository").(repo.TokenRepo)
+			groupRepo := ctn.Get("groupRepository").(repo.GroupRepo)
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			outboxService := ctn.Get("outboxService").(*outboxService.OutboxService)
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return userService.New(
+				userRepo,
+				tokenRepo,
+				groupRepo,
+				auditService,
+				outboxService,
+				mongoClient,
+				logger,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: RoleService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			roleRepo := ctn.Get("roleRepository").(repo.RoleRepo)
+			userRepo := ctn.Get("userRepository").(repo.UserRepo)
+			groupRepo := ctn.Get("groupRepository").(repo.GroupRepo)
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			return roleService.New(
+				roleRepo,
+				userRepo,
+				groupRepo,
+				auditService,
+				mongoClient,
+				cfg.Rbac.CacheTTL,
+				logger,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: GroupService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			groupRepo := ctn.Get("groupRepository").(repo.GroupRepo)
+			roleRepo := ctn.Get("roleRepository").(repo.RoleRepo)
+			userRepo := ctn.Get("userRepository").(repo.UserRepo)
+			roleService := ctn.Get("roleService").(*roleService.RoleService)
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			return groupService.New(
+				groupRepo,
+				roleRepo,
+				userRepo,
+				roleService,
+				auditService,
+				mongoClient,
+				logger,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: TenantService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			tenantRepo := ctn.Get("tenantRepository").(repo.TenantRepo)
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			return tenantService.New(
+				tenantRepo,
+				auditService,
+				cfg.Tenant.CacheTTL,
+				cfg.Tenant.Fallback,
+				logger,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: PolicyService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			return policyService.New(
+				cfg.Policy.Path,
+				cfg.Policy.DryRun,
+				cfg.Policy.DecisionLogSize,
+				auditService,
+				logger,
+			)
+		},
+	})
+	b.Add(di.Def{
+		Name: ForwardAuthService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			tokenManager := ctn.Get("tokenManager").(*tokenManager.TokenManager)
+			policyService := ctn.Get("policyService").(*policyService.PolicyService)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			return forwardAuthService.New(
+				tokenManager,
+				policyService,
+				forwardAuthPolicy(cfg),
+				logger,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: WebhookService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			webhookRepo := ctn.Get("webhookRepository").(*webhookRepository.WebhookRepo)
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			senderOpts := make([]webhook.SenderOpt, 0)
+			if cfg.Webhook.AllowPrivate {
+				senderOpts = append(senderOpts, webhook.WithPrivateNetworks())
+			}
+
+			return webhookService.New(
+				webhookRepo,
+				webhook.NewSender(cfg.Webhook.Timeout, senderOpts...),
+				auditService,
+				webhookService.Policy{
+					MaxAttempts:  cfg.Webhook.MaxAttempts,
+					BaseBackoff:  cfg.Webhook.BaseBackoff,
+					MaxBackoff:   cfg.Webhook.MaxBackoff,
+					PollInterval: cfg.Webhook.PollInterval,
+					Lease:        cfg.Webhook.Lease,
+					BatchSize:    cfg.Webhook.BatchSize,
+				},
+				logger,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: AuditService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			auditRepo := ctn.Get("auditRepository").(*auditRepository.AuditRepo)
+			tokenManager := ctn.Get("tokenManager").(*tokenManager.TokenManager)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			return auditService.New(
+				auditRepo,
+				tokenManager,
+				cfg.Audit.CheckpointInterval,
+				logger,
+			), nil
+		},
+	})
+	b.Add(di.Def{
+		Name: LockoutService,
+		Build: func(ctn di.Container) (interface{}, error) {
+			lockoutRepo := ctn.Get("lockoutRepository").(*lockoutRepository.LockoutRepo)
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			logger := ctn.Get("logger").(*slog.Logger)
+			cfg := ctn.Get("config").(*config.Config)
+
+			return lockoutService.New(
+				lockoutRepo,
+				auditService,
+				lockoutService.Policy{
+					Threshold:     cfg.Lockout.Threshold,
+					Duration:      cfg.Lockout.Duration,
+					BaseDelay:     cfg.Lockout.BaseDelay,
+					MaxDelay:      cfg.Lockout.MaxDelay,
+					FailureWindow: cfg.Lockout.FailureWindow,
+				},
+				logger,
+			), nil
+		},
+	})
 	b.Add(di.Def{
 		Name: AuthService,
 		Build: func(ctn di.Container) (interface{}, error) {
-			userRepo := ctn.Get("userRepository").(*userRepository.UserRepo)
-			tokenRepo := ctn.Get("tokenRepository").(*tokenRepository.TokenRepo)
+			userRepo := ctn.Get("userRepository").(repo.UserRepo)
+			tokenRepo := ctn.Get("tokenRepository").(repo.TokenRepo)
 			logger := ctn.Get("logger").(*slog.Logger)
 			tokenManager := ctn.Get("tokenManager").(*tokenManager.TokenManager)
+			lockoutService := ctn.Get("lockoutService").(*lockoutService.LockoutService)
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			webhookService := ctn.Get("webhookService").(*webhookService.WebhookService)
+			outboxService := ctn.Get("outboxService").(*outboxService.OutboxService)
+			roleService := ctn.Get("roleService").(*roleService.RoleService)
+			groupService := ctn.Get("groupService").(*groupService.GroupService)
+			tenantService := ctn.Get("tenantService").(*tenantService.TenantService)
+			mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+			cfg := ctn.Get("config").(*config.Config)
 
-			return authService.New(
+			service := authService.New(
 				userRepo,
 				tokenRepo,
 				logger,
 				tokenManager,
+				lockoutService,
+				auditService,
+				outboxService,
+				roleService,
+				groupService,
+				tenantService,
+				mongoClient,
+				authPolicy(cfg),
+			)
+			service.AddHook(webhookService)
+
+			return service, nil
+		},
+	})
+
+	//building rate limiter
+	b.Add(di.Def{
+		Name: RateLimiter,
+		Build: func(ctn di.Container) (interface{}, error) {
+			cfg := ctn.Get("config").(*config.Config)
+			logger := ctn.Get("logger").(*slog.Logger)
+
+			allowlist, err := ratelimit.ParseAllowlist(cfg.RateLimit.Allowlist)
+			if err != nil {
+				return nil, err
+			}
+
+			var store ratelimit.Store
+			switch cfg.RateLimit.Store {
+			case "mongo":
+				mongoClient := ctn.Get("mongo").(*mongo.MongoClient)
+				store = ratelimit.NewMongoStore(mongoClient.MongoDatabase.Collection("rate_limits"))
+			case "memory":
+				store = ratelimit.NewMemoryStore()
+			default:
+				return nil, fmt.Errorf("unknown rate limit store: %s", cfg.RateLimit.Store)
+			}
+
+			return rateLimitMiddleware.New(
+				store,
+				allowlist,
+				rateLimitRules(cfg),
+				logger,
 			), nil
 		},
 	})
@@ -162,11 +834,39 @@ func RegisterDeps(b *di.Builder) {
 			logger := ctn.Get("logger").(*slog.Logger)
 			tokenManager := ctn.Get("tokenManager").(*tokenManager.TokenManager)
 			authService := ctn.Get("authService").(*authService.AuthService)
+			catalog := ctn.Get("i18n").(*i18n.Catalog)
+			limiter := ctn.Get("rateLimiter").(*rateLimitMiddleware.Limiter)
+			lockoutService := ctn.Get("lockoutService").(*lockoutService.LockoutService)
+			auditService := ctn.Get("auditService").(*auditService.AuditService)
+			webhookService := ctn.Get("webhookService").(*webhookService.WebhookService)
+			userService := ctn.Get("userService").(*userService.UserService)
+			roleService := ctn.Get("roleService").(*roleService.RoleService)
+			groupService := ctn.Get("groupService").(*groupService.GroupService)
+			tenantService := ctn.Get("tenantService").(*tenantService.TenantService)
+			policyService := ctn.Get("policyService").(*policyService.PolicyService)
+			forwardAuthService := ctn.Get("forwardAuthService").(*forwardAuthService.ForwardAuthService)
+			executor := ctn.Get("repoExecutor").(*resilience.Executor)
+			cfg := ctn.Get("config").(*config.Config)
 
 			return httpRouter.InitRoutes(
 				logger,
 				tokenManager,
 				authService,
+				catalog,
+				limiter,
+				lockoutService,
+				auditService,
+				webhookService,
+				userService,
+				roleService,
+				groupService,
+				tenantService,
+				policyService,
+				forwardAuthService,
+				cfg.Tenant.Header,
+				cfg.ForwardAuth.Cookie,
+				cfg.Http.TrustedProxies,
+				[]*resilience.Breaker{executor.Breaker()},
 			), nil
 		},
 	})
@@ -196,11 +896,71 @@ func RegisterDeps(b *di.Builder) {
 		Build: func(ctn di.Container) (interface{}, error) {
 			server := ctn.Get("httpserver").(*httpserver.HttpServer)
 			logger := ctn.Get("logger").(*slog.Logger)
+			webhookService := ctn.Get("webhookService").(*webhookService.WebhookService)
+			outboxService := ctn.Get("outboxService").(*outboxService.OutboxService)
+			keyService := ctn.Get("keyService").(*keyService.KeyService)
+			reloader := ctn.Get("configReloader").(*config.Reloader)
 
 			return app.New(
 				server,
 				logger,
+				webhookService,
+				outboxService,
+				keyService,
+				reloader,
 			), nil
 		},
 	})
 }
+
+// settings below are built from config at start and again on every reload
+
+func authPolicy(cfg *config.Config) authService.Policy {
+	// both are checked by config validation
+	clients, _ := scope.ParseClients(cfg.OAuth.Clients)
+	defaultScopes, _ := scope.Parse(strings.Join(cfg.OAuth.DefaultScopes, " "))
+
+	return authService.Policy{
+		RefreshLifeTime: cfg.Jwt.RefreshLifeTime,
+		SessionMaxAge:   cfg.Jwt.SessionMaxAge,
+		SlidingRenewal:  cfg.Jwt.SlidingRenewal,
+		Clients:         clients,
+		DefaultScopes:   defaultScopes,
+		GroupsClaim:     cfg.Rbac.GroupsClaim,
+	}
+}
+
+func forwardAuthPolicy(cfg *config.Config) forwardAuthService.Policy {
+	return forwardAuthService.Policy{
+		CacheTTL:  cfg.ForwardAuth.CacheTTL,
+		CacheSize: cfg.ForwardAuth.CacheSize,
+		Headers: forwardAuthService.Headers{
+			User:   cfg.ForwardAuth.UserHeader,
+			Tenant: cfg.ForwardAuth.TenantHeader,
+			Roles:  cfg.ForwardAuth.RolesHeader,
+			Groups: cfg.ForwardAuth.GroupsHeader,
+			Scope:  cfg.ForwardAuth.ScopeHeader,
+			Client: cfg.ForwardAuth.ClientHeader,
+		},
+	}
+}
+
+func keyPolicy(cfg *config.Config) keyService.Policy {
+	return keyService.Policy{
+		ReloadInterval: cfg.Keys.ReloadInterval,
+		RetiredKeyTTL:  cfg.Keys.RetiredKeyTTL,
+	}
+}
+
+func rateLimitRules(cfg *config.Config) map[string]rateLimitMiddleware.Rule {
+	return map[string]rateLimitMiddleware.Rule{
+		authRouter.SignInRoute: {
+			PerIP:   cfg.RateLimit.SignInIP,
+			PerUser: cfg.RateLimit.SignInUser,
+		},
+		authRouter.RefreshRoute: {
+			PerIP:   cfg.RateLimit.RefreshIP,
+			PerUser: cfg.RateLimit.RefreshUser,
+		},
+	}
+}
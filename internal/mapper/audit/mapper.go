@@ -0,0 +1,80 @@
+package audit
+
+import (
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	auditRepo "github.com/elusiv0/medods_test/internal/repo/audit/model"
+)
+
+func ModelToEntry(entryModel auditRepo.Entry) auditDto.Entry {
+	return auditDto.Entry{
+		ID:        entryModel.ID.Hex(),
+		Seq:       entryModel.Seq,
+		Tenant:    entryModel.Tenant,
+		Type:      entryModel.Type,
+		Action:    entryModel.Action,
+		Actor:     entryModel.Actor,
+		Subject:   entryModel.Subject,
+		IP:        entryModel.IP,
+		UserAgent: entryModel.UserAgent,
+		Outcome:   entryModel.Outcome,
+		Reason:    entryModel.Reason,
+		CreatedAt: entryModel.CreatedAt,
+		PrevHash:  entryModel.PrevHash,
+		Hash:      entryModel.Hash,
+	}
+}
+
+func EntryToModel(entry auditDto.Entry) auditRepo.Entry {
+	return auditRepo.Entry{
+		Tenant:    entry.Tenant,
+		Type:      entry.Type,
+		Action:    entry.Action,
+		Actor:     entry.Actor,
+		Subject:   entry.Subject,
+		IP:        entry.IP,
+		UserAgent: entry.UserAgent,
+		Outcome:   entry.Outcome,
+		Reason:    entry.Reason,
+		CreatedAt: entry.CreatedAt,
+		Seq:       entry.Seq,
+		PrevHash:  entry.PrevHash,
+		Hash:      entry.Hash,
+	}
+}
+
+func ModelToCheckpoint(checkpointModel auditRepo.Checkpoint) auditDto.Checkpoint {
+	return auditDto.Checkpoint{
+		Seq:       checkpointModel.Seq,
+		Hash:      checkpointModel.Hash,
+		Signature: checkpointModel.Signature,
+		CreatedAt: checkpointModel.CreatedAt,
+	}
+}
+
+func CheckpointToModel(checkpoint auditDto.Checkpoint) auditRepo.Checkpoint {
+	return auditRepo.Checkpoint{
+		Seq:       checkpoint.Seq,
+		Hash:      checkpoint.Hash,
+		Signature: checkpoint.Signature,
+		CreatedAt: checkpoint.CreatedAt,
+	}
+}
+
+func ModelToHead(headModel auditRepo.Head) auditDto.Checkpoint {
+	return auditDto.Checkpoint{
+		Seq:       headModel.Seq,
+		Hash:      headModel.Hash,
+		Signature: headModel.Signature,
+		CreatedAt: headModel.CreatedAt,
+	}
+}
+
+func HeadToModel(id string, head auditDto.Checkpoint) auditRepo.Head {
+	return auditRepo.Head{
+		ID:        id,
+		Seq:       head.Seq,
+		Hash:      head.Hash,
+		Signature: head.Signature,
+		CreatedAt: head.CreatedAt,
+	}
+}
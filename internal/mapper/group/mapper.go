@@ -0,0 +1,39 @@
+package group
+
+import (
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	groupModel "github.com/elusiv0/medods_test/internal/repo/group/model"
+)
+
+func ModelToGroup(model groupModel.Group) groupDto.Group {
+	return groupDto.Group{
+		ID:          model.ID,
+		Name:        model.Name,
+		Description: model.Description,
+		Roles:       orEmpty(model.Roles),
+		Parents:     orEmpty(model.Parents),
+		Members:     orEmpty(model.Members),
+		CreatedAt:   model.CreatedAt,
+	}
+}
+
+func GroupToModel(group groupDto.Group, tenant string) groupModel.Group {
+	return groupModel.Group{
+		ID:          group.ID,
+		Tenant:      tenant,
+		Name:        group.Name,
+		Description: group.Description,
+		Roles:       orEmpty(group.Roles),
+		Parents:     orEmpty(group.Parents),
+		Members:     orEmpty(group.Members),
+		CreatedAt:   group.CreatedAt,
+	}
+}
+
+func orEmpty(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+
+	return list
+}
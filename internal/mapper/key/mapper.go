@@ -0,0 +1,31 @@
+package key
+
+import (
+	keyDto "github.com/elusiv0/medods_test/internal/model/key"
+	keyRepo "github.com/elusiv0/medods_test/internal/repo/key/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+)
+
+func ModelToKey(keyModel keyRepo.Key) keyDto.Key {
+	return keyDto.Key{
+		ID:          keyModel.ID,
+		Tenant:      tenantUtil.Normalize(keyModel.Tenant),
+		Secret:      keyModel.Secret,
+		Status:      keyModel.Status,
+		CreatedAt:   keyModel.CreatedAt,
+		ActivatedAt: keyModel.ActivatedAt,
+		RetiredAt:   keyModel.RetiredAt,
+	}
+}
+
+func KeyToModel(key keyDto.Key) keyRepo.Key {
+	return keyRepo.Key{
+		ID:          key.ID,
+		Tenant:      key.Tenant,
+		Secret:      key.Secret,
+		Status:      key.Status,
+		CreatedAt:   key.CreatedAt,
+		ActivatedAt: key.ActivatedAt,
+		RetiredAt:   key.RetiredAt,
+	}
+}
@@ -0,0 +1,15 @@
+package lockout
+
+import (
+	lockoutDto "github.com/elusiv0/medods_test/internal/model/lockout"
+	lockoutRepo "github.com/elusiv0/medods_test/internal/repo/lockout/model"
+)
+
+func ModelToLockout(lockoutModel lockoutRepo.Lockout) lockoutDto.Lockout {
+	return lockoutDto.Lockout{
+		UUID:          lockoutModel.UUID,
+		Failures:      lockoutModel.Failures,
+		LastFailureAt: lockoutModel.LastFailureAt,
+		LockedUntil:   lockoutModel.LockedUntil,
+	}
+}
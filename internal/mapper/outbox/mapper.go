@@ -0,0 +1,40 @@
+package outbox
+
+import (
+	outboxDto "github.com/elusiv0/medods_test/internal/model/outbox"
+	outboxRepo "github.com/elusiv0/medods_test/internal/repo/outbox/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+)
+
+func ModelToMessage(messageModel outboxRepo.Message) outboxDto.Message {
+	message := outboxDto.Message{
+		ID:            messageModel.ID.Hex(),
+		Tenant:        tenantUtil.Normalize(messageModel.Tenant),
+		EventID:       messageModel.EventID,
+		EventType:     messageModel.EventType,
+		Key:           messageModel.Key,
+		Payload:       messageModel.Payload,
+		Attempts:      messageModel.Attempts,
+		NextAttemptAt: messageModel.NextAttemptAt,
+		LastError:     messageModel.LastError,
+		CreatedAt:     messageModel.CreatedAt,
+	}
+	if messageModel.DeliveredAt != nil {
+		message.DeliveredAt = *messageModel.DeliveredAt
+	}
+
+	return message
+}
+
+func MessageToModel(message outboxDto.Message) outboxRepo.Message {
+	return outboxRepo.Message{
+		Tenant:        message.Tenant,
+		EventID:       message.EventID,
+		EventType:     message.EventType,
+		Key:           message.Key,
+		Payload:       message.Payload,
+		Attempts:      message.Attempts,
+		NextAttemptAt: message.NextAttemptAt.UTC(),
+		CreatedAt:     message.CreatedAt.UTC(),
+	}
+}
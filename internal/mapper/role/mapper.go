@@ -0,0 +1,29 @@
+package role
+
+import (
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	roleModel "github.com/elusiv0/medods_test/internal/repo/role/model"
+)
+
+func ModelToRole(model roleModel.Role) roleDto.Role {
+	return roleDto.Role{
+		Name:        model.Name,
+		Description: model.Description,
+		Permissions: model.Permissions,
+		CreatedAt:   model.CreatedAt,
+	}
+}
+
+func RoleToModel(role roleDto.Role) roleModel.Role {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return roleModel.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+	}
+}
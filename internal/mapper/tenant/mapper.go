@@ -0,0 +1,60 @@
+package tenant
+
+import (
+	"time"
+
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
+	tenantModel "github.com/elusiv0/medods_test/internal/repo/tenant/model"
+)
+
+func ModelToTenant(model tenantModel.Tenant) tenantDto.Tenant {
+	hosts := model.Hosts
+	if hosts == nil {
+		hosts = []string{}
+	}
+
+	return tenantDto.Tenant{
+		ID:        model.ID,
+		Name:      model.Name,
+		Hosts:     hosts,
+		Disabled:  model.Disabled,
+		Policy:    ModelToPolicy(model.Policy),
+		CreatedAt: model.CreatedAt,
+	}
+}
+
+func TenantToModel(tenant tenantDto.Tenant) tenantModel.Tenant {
+	hosts := tenant.Hosts
+	if hosts == nil {
+		hosts = []string{}
+	}
+
+	return tenantModel.Tenant{
+		ID:        tenant.ID,
+		Name:      tenant.Name,
+		Hosts:     hosts,
+		Disabled:  tenant.Disabled,
+		Policy:    PolicyToModel(tenant.Policy),
+		CreatedAt: tenant.CreatedAt,
+	}
+}
+
+func ModelToPolicy(model tenantModel.Policy) tenantDto.Policy {
+	return tenantDto.Policy{
+		AccessLifeTime:  tenantDto.Duration(model.AccessLifeTime),
+		RefreshLifeTime: tenantDto.Duration(model.RefreshLifeTime),
+		SessionMaxAge:   tenantDto.Duration(model.SessionMaxAge),
+		Clients:         model.Clients,
+		DefaultScopes:   model.DefaultScopes,
+	}
+}
+
+func PolicyToModel(policy tenantDto.Policy) tenantModel.Policy {
+	return tenantModel.Policy{
+		AccessLifeTime:  time.Duration(policy.AccessLifeTime),
+		RefreshLifeTime: time.Duration(policy.RefreshLifeTime),
+		SessionMaxAge:   time.Duration(policy.SessionMaxAge),
+		Clients:         policy.Clients,
+		DefaultScopes:   policy.DefaultScopes,
+	}
+}
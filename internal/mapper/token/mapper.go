@@ -0,0 +1,19 @@
+package token
+
+import (
+	"strings"
+
+	tokenDto "github.com/elusiv0/medods_test/internal/model/token"
+	tokenRepo "github.com/elusiv0/medods_test/internal/repo/token/model"
+)
+
+func ModelToSession(tokenModel tokenRepo.Token) tokenDto.Session {
+	return tokenDto.Session{
+		ID:        tokenModel.ID.Hex(),
+		UserUUID:  tokenModel.UserUUID,
+		ClientID:  tokenModel.ClientID,
+		Scope:     strings.Join(tokenModel.Scope, " "),
+		StartedAt: tokenModel.SessionStartedAt,
+		ExpiresAt: tokenModel.ExpiresAt,
+	}
+}
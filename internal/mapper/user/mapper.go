@@ -3,13 +3,22 @@ package user
 import (
 	userDto "github.com/elusiv0/medods_test/internal/model/user"
 	userRepo "github.com/elusiv0/medods_test/internal/repo/user/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
 	uuidUtil "github.com/google/uuid"
 )
 
 func ModelToUser(userModel userRepo.User) userDto.User {
+	roles := userModel.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+
 	return userDto.User{
-		UUID: userModel.UUID,
-		Name: userModel.Name,
+		UUID:     userModel.UUID,
+		Tenant:   tenantUtil.Normalize(userModel.Tenant),
+		Name:     userModel.Name,
+		Disabled: userModel.Disabled,
+		Roles:    roles,
 	}
 }
 
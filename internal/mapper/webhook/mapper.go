@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	webhookDto "github.com/elusiv0/medods_test/internal/model/webhook"
+	webhookRepo "github.com/elusiv0/medods_test/internal/repo/webhook/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func ModelToSubscription(subscriptionModel webhookRepo.Subscription) webhookDto.Subscription {
+	return webhookDto.Subscription{
+		ID:        subscriptionModel.ID.Hex(),
+		Tenant:    tenantUtil.Normalize(subscriptionModel.Tenant),
+		URL:       subscriptionModel.URL,
+		Events:    subscriptionModel.Events,
+		Secret:    subscriptionModel.Secret,
+		Active:    subscriptionModel.Active,
+		CreatedAt: subscriptionModel.CreatedAt,
+	}
+}
+
+func SubscriptionToModel(subscription webhookDto.Subscription) webhookRepo.Subscription {
+	return webhookRepo.Subscription{
+		Tenant:    subscription.Tenant,
+		URL:       subscription.URL,
+		Events:    subscription.Events,
+		Secret:    subscription.Secret,
+		Active:    subscription.Active,
+		CreatedAt: subscription.CreatedAt,
+	}
+}
+
+func ModelToDelivery(deliveryModel webhookRepo.Delivery) webhookDto.Delivery {
+	return webhookDto.Delivery{
+		ID:             deliveryModel.ID.Hex(),
+		Tenant:         tenantUtil.Normalize(deliveryModel.Tenant),
+		SubscriptionID: deliveryModel.SubscriptionID.Hex(),
+		EventID:        deliveryModel.EventID,
+		EventType:      deliveryModel.EventType,
+		Payload:        deliveryModel.Payload,
+		Status:         deliveryModel.Status,
+		Attempts:       deliveryModel.Attempts,
+		NextAttemptAt:  deliveryModel.NextAttemptAt,
+		LastStatusCode: deliveryModel.LastStatusCode,
+		LastError:      deliveryModel.LastError,
+		CreatedAt:      deliveryModel.CreatedAt,
+		UpdatedAt:      deliveryModel.UpdatedAt,
+	}
+}
+
+func DeliveryToModel(delivery webhookDto.Delivery) (webhookRepo.Delivery, error) {
+	subscriptionID, err := primitive.ObjectIDFromHex(delivery.SubscriptionID)
+	if err != nil {
+		return webhookRepo.Delivery{}, err
+	}
+
+	return webhookRepo.Delivery{
+		Tenant:         delivery.Tenant,
+		SubscriptionID: subscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}, nil
+}
@@ -5,10 +5,14 @@ import (
 	"strings"
 
 	"github.com/elusiv0/medods_test/internal/model/api"
+	reqUtils "github.com/elusiv0/medods_test/internal/util/request"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
 	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
 	"github.com/gin-gonic/gin"
 )
 
+// Auth must be used after tenant middleware, it lets through only valid tokens
+// issued for tenant of request.
 func Auth(
 	tokenManager *tokenManager.TokenManager,
 	logger *slog.Logger,
@@ -32,11 +36,24 @@ func Auth(
 
 		tokenInfo, err := tokenManager.ValidateJWT(token[1])
 		if err != nil {
-			logger.Error("AuthMiddleware: ", err.Error())
+			logger.Error("AuthMiddleware: " + err.Error())
 			c.Error(err)
+			c.Abort()
+			return
+		}
+		if tenant := tenantUtil.FromContext(c.Request.Context()); tenantUtil.Normalize(tokenInfo.Tenant) != tenant {
+			logger.Warn(
+				"AuthMiddleware: token of another tenant",
+				slog.String("uuid", tokenInfo.UUID),
+				slog.String("token_tenant", tokenInfo.Tenant),
+				slog.String("tenant", tenant),
+			)
+			c.Error(api.ErrTenantMismatch)
+			c.Abort()
 			return
 		}
 		c.Set("tokenInfo", tokenInfo)
+		reqUtils.SetInfo(c, tokenInfo.UUID)
 
 		c.Next()
 	}
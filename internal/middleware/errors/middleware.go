@@ -3,36 +3,108 @@ package errors
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 
 	api "github.com/elusiv0/medods_test/internal/model/api"
+	audit "github.com/elusiv0/medods_test/internal/model/audit"
+	group "github.com/elusiv0/medods_test/internal/model/group"
+	lockout "github.com/elusiv0/medods_test/internal/model/lockout"
+	policy "github.com/elusiv0/medods_test/internal/model/policy"
+	role "github.com/elusiv0/medods_test/internal/model/role"
+	tenant "github.com/elusiv0/medods_test/internal/model/tenant"
 	token "github.com/elusiv0/medods_test/internal/model/token"
 	user "github.com/elusiv0/medods_test/internal/model/user"
+	webhook "github.com/elusiv0/medods_test/internal/model/webhook"
+	"github.com/elusiv0/medods_test/pkg/i18n"
+	policyEngine "github.com/elusiv0/medods_test/pkg/policy"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	problemContentType = "application/problem+json"
+)
+
+type ErrorInfo struct {
+	Status int
+	Code   string
+}
+
 type response struct {
+	Code  string `json:"error_code"`
 	Error string `json:"error_message"`
 }
 
-func InitErrors() map[error]int {
-	errs := make(map[error]int)
-
-	errs[api.ErrNoUUID] = http.StatusBadRequest
-	errs[api.ErrNoAccessTokenFound] = http.StatusUnauthorized
-	errs[api.ErrInvalidAccessToken] = http.StatusUnauthorized
-	errs[api.ErrAccessTokenExpired] = http.StatusUnauthorized
-	errs[api.ErrBadRefreshRequest] = http.StatusUnauthorized
-	errs[api.ErrTokenMismatch] = http.StatusUnauthorized
-
-	errs[token.ErrRefreshTokenNotRegistered] = http.StatusUnauthorized
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+	Code   string `json:"code"`
+}
 
-	errs[user.ErrUserNotFound] = http.StatusUnauthorized
+func InitErrors() map[error]ErrorInfo {
+	errs := make(map[error]ErrorInfo)
+
+	errs[api.ErrNoUUID] = ErrorInfo{http.StatusBadRequest, "no_uuid"}
+	errs[api.ErrNoAccessTokenFound] = ErrorInfo{http.StatusUnauthorized, "no_access_token"}
+	errs[api.ErrInvalidAccessToken] = ErrorInfo{http.StatusUnauthorized, "invalid_access_token"}
+	errs[api.ErrAccessTokenExpired] = ErrorInfo{http.StatusUnauthorized, "access_token_expired"}
+	errs[api.ErrBadRefreshRequest] = ErrorInfo{http.StatusUnauthorized, "bad_refresh_request"}
+	errs[api.ErrTokenMismatch] = ErrorInfo{http.StatusUnauthorized, "token_mismatch"}
+	errs[api.ErrTooManyRequests] = ErrorInfo{http.StatusTooManyRequests, "too_many_requests"}
+	errs[api.ErrForbidden] = ErrorInfo{http.StatusForbidden, "forbidden"}
+	errs[api.ErrBadPagination] = ErrorInfo{http.StatusBadRequest, "bad_pagination"}
+	errs[api.ErrServiceUnavailable] = ErrorInfo{http.StatusServiceUnavailable, "service_unavailable"}
+	errs[api.ErrInsufficientScope] = ErrorInfo{http.StatusForbidden, "insufficient_scope"}
+	errs[api.ErrTenantMismatch] = ErrorInfo{http.StatusUnauthorized, "tenant_mismatch"}
+
+	errs[token.ErrRefreshTokenNotRegistered] = ErrorInfo{http.StatusUnauthorized, "refresh_token_not_registered"}
+	errs[token.ErrRefreshTokenExpired] = ErrorInfo{http.StatusUnauthorized, "refresh_token_expired"}
+	errs[token.ErrInvalidScope] = ErrorInfo{http.StatusBadRequest, "invalid_scope"}
+	errs[token.ErrInvalidClient] = ErrorInfo{http.StatusUnauthorized, "invalid_client"}
+
+	errs[user.ErrUserNotFound] = ErrorInfo{http.StatusUnauthorized, "user_not_found"}
+	errs[user.ErrUserDisabled] = ErrorInfo{http.StatusForbidden, "user_disabled"}
+	errs[user.ErrNoSuchUser] = ErrorInfo{http.StatusNotFound, "no_such_user"}
+	errs[user.ErrBadUser] = ErrorInfo{http.StatusBadRequest, "bad_user"}
+	errs[user.ErrBadFilter] = ErrorInfo{http.StatusBadRequest, "bad_users_filter"}
+
+	errs[role.ErrRoleNotFound] = ErrorInfo{http.StatusNotFound, "role_not_found"}
+	errs[role.ErrRoleExists] = ErrorInfo{http.StatusConflict, "role_exists"}
+	errs[role.ErrBadRole] = ErrorInfo{http.StatusBadRequest, "bad_role"}
+	errs[role.ErrUnknownRole] = ErrorInfo{http.StatusBadRequest, "unknown_role"}
+
+	errs[group.ErrGroupNotFound] = ErrorInfo{http.StatusNotFound, "group_not_found"}
+	errs[group.ErrGroupExists] = ErrorInfo{http.StatusConflict, "group_exists"}
+	errs[group.ErrBadGroup] = ErrorInfo{http.StatusBadRequest, "bad_group"}
+	errs[group.ErrGroupCycle] = ErrorInfo{http.StatusConflict, "group_cycle"}
+
+	errs[tenant.ErrTenantNotFound] = ErrorInfo{http.StatusNotFound, "tenant_not_found"}
+	errs[tenant.ErrTenantExists] = ErrorInfo{http.StatusConflict, "tenant_exists"}
+	errs[tenant.ErrBadTenant] = ErrorInfo{http.StatusBadRequest, "bad_tenant"}
+	errs[tenant.ErrUnknownTenant] = ErrorInfo{http.StatusNotFound, "unknown_tenant"}
+	errs[tenant.ErrTenantDisabled] = ErrorInfo{http.StatusForbidden, "tenant_disabled"}
+
+	errs[lockout.ErrAccountLocked] = ErrorInfo{http.StatusLocked, "account_locked"}
+	errs[lockout.ErrAuthenticationDelayed] = ErrorInfo{http.StatusTooManyRequests, "authentication_delayed"}
+	errs[lockout.ErrLockoutNotFound] = ErrorInfo{http.StatusNotFound, "lockout_not_found"}
+
+	errs[policy.ErrBadFilter] = ErrorInfo{http.StatusBadRequest, "bad_decisions_filter"}
+	errs[policy.ErrNoPolicyPath] = ErrorInfo{http.StatusConflict, "no_policy_path"}
+	errs[policyEngine.ErrInvalidPolicy] = ErrorInfo{http.StatusUnprocessableEntity, "invalid_policy"}
+
+	errs[audit.ErrBadFilter] = ErrorInfo{http.StatusBadRequest, "bad_audit_filter"}
+
+	errs[webhook.ErrSubscriptionNotFound] = ErrorInfo{http.StatusNotFound, "webhook_subscription_not_found"}
+	errs[webhook.ErrDeliveryNotFound] = ErrorInfo{http.StatusNotFound, "webhook_delivery_not_found"}
+	errs[webhook.ErrBadSubscription] = ErrorInfo{http.StatusBadRequest, "bad_webhook_subscription"}
 
 	return errs
 }
 
-func ErrorsMiddleware(errs map[error]int) gin.HandlerFunc {
+func ErrorsMiddleware(errs map[error]ErrorInfo, catalog *i18n.Catalog) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
@@ -42,6 +114,11 @@ func ErrorsMiddleware(errs map[error]int) gin.HandlerFunc {
 
 		err := c.Errors.Last().Err
 
+		var retryErr *api.RetryError
+		if errors.As(err, &retryErr) && retryErr.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(retryErr.RetryAfter.Seconds()+0.999)))
+		}
+
 		firstError := err
 
 		for err != nil {
@@ -49,10 +126,29 @@ func ErrorsMiddleware(errs map[error]int) gin.HandlerFunc {
 			err = errors.Unwrap(err)
 		}
 
-		if code, ok := errs[firstError]; ok {
-			c.JSON(code, &response{
-				Error: firstError.Error(),
-			})
+		if info, ok := errs[firstError]; ok {
+			locale := catalog.Locale(c.GetHeader("Accept-Language"))
+			message, ok := catalog.Message(locale, info.Code)
+			if !ok {
+				message = firstError.Error()
+			}
+			c.Header("Content-Language", locale)
+
+			if strings.Contains(c.GetHeader("Accept"), problemContentType) {
+				c.Header("Content-Type", problemContentType)
+				c.JSON(info.Status, &problem{
+					Type:   "about:blank",
+					Title:  http.StatusText(info.Status),
+					Status: info.Status,
+					Detail: message,
+					Code:   info.Code,
+				})
+			} else {
+				c.JSON(info.Status, &response{
+					Code:  info.Code,
+					Error: message,
+				})
+			}
 		} else {
 			c.Status(http.StatusInternalServerError)
 		}
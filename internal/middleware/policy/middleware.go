@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"github.com/elusiv0/medods_test/internal/model/api"
+	policyDto "github.com/elusiv0/medods_test/internal/model/policy"
+	policyService "github.com/elusiv0/medods_test/internal/service/policy"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	"github.com/elusiv0/medods_test/pkg/policy"
+	"github.com/gin-gonic/gin"
+)
+
+// Authorize must be used after auth middleware, it evaluates policies against
+// token claims, route and request environment and rejects denied requests
+// unless policy service runs in dry-run mode. Every decision is logged by
+// policy service.
+func Authorize(
+	policyService *policyService.PolicyService,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenInfo, ok := c.MustGet("tokenInfo").(tokenManager.TokenInfo)
+		if !ok {
+			c.Error(api.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		request := policyDto.Request{
+			Method:     c.Request.Method,
+			Route:      c.FullPath(),
+			Path:       c.Request.URL.Path,
+			Attributes: attributes(c, policyService, tokenInfo),
+		}
+		decision := policyService.Decide(c.Request.Context(), request)
+		if !decision.Allowed && decision.Enforced {
+			c.Error(api.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func attributes(
+	c *gin.Context,
+	policyService *policyService.PolicyService,
+	tokenInfo tokenManager.TokenInfo,
+) policy.Attributes {
+	attributes := policyService.Attributes(
+		tokenInfo,
+		c.Request.Method,
+		c.FullPath(),
+		c.Request.URL.Path,
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+	for _, param := range c.Params {
+		attributes.Set(policyDto.AttributeResourcePrefix+param.Key, param.Value)
+	}
+	// owner of resource is user named by uuid path or query parameter
+	if owner := c.Param("uuid"); owner != "" {
+		attributes.Set(policyDto.AttributeResourceOwner, owner)
+	} else if owner := c.Query("uuid"); owner != "" {
+		attributes.Set(policyDto.AttributeResourceOwner, owner)
+	}
+
+	return attributes
+}
@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"strconv"
+	"sync"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	tokenDto "github.com/elusiv0/medods_test/internal/model/token"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	"github.com/elusiv0/medods_test/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type Rule struct {
+	PerIP   ratelimit.Limit
+	PerUser ratelimit.Limit
+}
+
+type KeyFunc func(c *gin.Context) string
+
+type Limiter struct {
+	store  ratelimit.Store
+	logger *slog.Logger
+
+	mu        sync.RWMutex
+	allowlist ratelimit.Allowlist
+	rules     map[string]Rule
+}
+
+func New(
+	store ratelimit.Store,
+	allowlist ratelimit.Allowlist,
+	rules map[string]Rule,
+	log *slog.Logger,
+) *Limiter {
+	return &Limiter{
+		store:     store,
+		allowlist: allowlist,
+		rules:     rules,
+		logger:    log,
+	}
+}
+
+// SetRules replaces allowlist and rules, buckets already taken are kept.
+func (limiter *Limiter) SetRules(allowlist ratelimit.Allowlist, rules map[string]Rule) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	limiter.allowlist = allowlist
+	limiter.rules = rules
+}
+
+// Limit returns a middleware enforcing rule of route, userKey extracts user identity
+// from request and may be nil when route has no per-user limit.
+func (limiter *Limiter) Limit(route string, userKey KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter.mu.RLock()
+		allowlist, rule := limiter.allowlist, limiter.rules[route]
+		limiter.mu.RUnlock()
+
+		ip := c.ClientIP()
+		if allowlist.Contains(ip) {
+			c.Next()
+			return
+		}
+
+		results := make([]ratelimit.Result, 0, 2)
+		if rule.PerIP.Enabled() {
+			results = limiter.take(c, results, "ratelimit:"+route+":ip:"+ip, rule.PerIP)
+		}
+		if rule.PerUser.Enabled() && userKey != nil {
+			if user := userKey(c); user != "" {
+				results = limiter.take(c, results, "ratelimit:"+route+":user:"+user, rule.PerUser)
+			}
+		}
+
+		if len(results) == 0 {
+			c.Next()
+			return
+		}
+
+		strictest := results[0]
+		for _, res := range results[1:] {
+			if !res.Allowed && (strictest.Allowed || res.RetryAfter > strictest.RetryAfter) {
+				strictest = res
+			} else if strictest.Allowed && res.Remaining < strictest.Remaining {
+				strictest = res
+			}
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(strictest.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(strictest.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(strictest.Reset.Seconds())))
+
+		if !strictest.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(strictest.RetryAfter.Seconds())))
+			limiter.logger.Warn("RateLimitMiddleware: request rejected", slog.String("route", route), slog.String("ip", ip))
+			c.Error(api.ErrTooManyRequests)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// take fails open, storage outages must not lock everyone out of sign in.
+func (limiter *Limiter) take(c *gin.Context, results []ratelimit.Result, key string, limit ratelimit.Limit) []ratelimit.Result {
+	res, err := limiter.store.Take(c.Request.Context(), key, limit)
+	if err != nil {
+		limiter.logger.Error("RateLimitMiddleware: " + err.Error())
+		return results
+	}
+
+	return append(results, res)
+}
+
+func QueryKey(name string) KeyFunc {
+	return func(c *gin.Context) string {
+		return c.Query(name)
+	}
+}
+
+// AccessTokenKey identifies user of a refresh request by its access token, expired tokens are accepted.
+func AccessTokenKey(tokenM *tokenManager.TokenManager) KeyFunc {
+	return func(c *gin.Context) string {
+		request := tokenDto.RefreshRequest{}
+		if err := c.ShouldBindBodyWith(&request, binding.JSON); err != nil {
+			return ""
+		}
+
+		tokenInfo, _ := tokenM.ValidateJWT(request.AccessToken)
+
+		return tokenInfo.UUID
+	}
+}
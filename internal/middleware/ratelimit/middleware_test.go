@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	"github.com/elusiv0/medods_test/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("unavailable")
+}
+
+func newTestLimiter(store ratelimit.Store, allowlist ratelimit.Allowlist, rule Rule) *Limiter {
+	gin.SetMode(gin.TestMode)
+
+	return New(store, allowlist, map[string]Rule{"sign_in": rule}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func newTestEngine(store ratelimit.Store, allowlist ratelimit.Allowlist, rule Rule) *gin.Engine {
+	engine := gin.New()
+	engine.GET("/", newTestLimiter(store, allowlist, rule).Limit("sign_in", QueryKey("uuid")), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	return engine
+}
+
+func serve(engine *gin.Engine, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	request.RemoteAddr = "203.0.113.1:1234"
+	engine.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+func TestLimitSetsHeaders(t *testing.T) {
+	engine := newTestEngine(ratelimit.NewMemoryStore(), nil, Rule{
+		PerIP:   ratelimit.Limit{Requests: 5, Period: time.Minute},
+		PerUser: ratelimit.Limit{Requests: 1, Period: time.Minute},
+	})
+
+	recorder := serve(engine, "/?uuid=u-1")
+	if recorder.Code != http.StatusNoContent {
+		t.Fatalf("status = %d", recorder.Code)
+	}
+	for header, want := range map[string]string{
+		"RateLimit-Limit":     "1",
+		"RateLimit-Remaining": "0",
+		"RateLimit-Reset":     "60",
+		"Retry-After":         "",
+	} {
+		if got := recorder.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q of the strictest limit", header, got, want)
+		}
+	}
+
+	recorder = serve(engine, "/?uuid=u-1")
+	if recorder.Code == http.StatusNoContent {
+		t.Fatal("request over user limit is passed")
+	}
+	if got := recorder.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want 60", got)
+	}
+
+	if recorder = serve(engine, "/?uuid=u-2"); recorder.Code != http.StatusNoContent {
+		t.Errorf("another user of the same ip is rejected")
+	}
+}
+
+func TestLimitRejectsWithError(t *testing.T) {
+	limiter := newTestLimiter(ratelimit.NewMemoryStore(), nil, Rule{PerIP: ratelimit.Limit{Requests: 1, Period: time.Minute}})
+
+	var errs []error
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Next()
+		for _, err := range c.Errors {
+			errs = append(errs, err.Err)
+		}
+	})
+	engine.GET("/", limiter.Limit("sign_in", nil), func(c *gin.Context) {})
+
+	serve(engine, "/")
+	serve(engine, "/")
+	if len(errs) != 1 || !errors.Is(errs[0], api.ErrTooManyRequests) {
+		t.Fatalf("errors = %v, want single ErrTooManyRequests", errs)
+	}
+}
+
+func TestLimitSkipsAllowlistAndFailsOpen(t *testing.T) {
+	rule := Rule{PerIP: ratelimit.Limit{Requests: 1, Period: time.Minute}}
+	allowlist, _ := ratelimit.ParseAllowlist([]string{"203.0.113.0/24"})
+
+	for name, engine := range map[string]*gin.Engine{
+		"allowlist":     newTestEngine(ratelimit.NewMemoryStore(), allowlist, rule),
+		"store failure": newTestEngine(failingStore{}, nil, rule),
+	} {
+		for i := 0; i < 3; i++ {
+			recorder := serve(engine, "/")
+			if recorder.Code != http.StatusNoContent {
+				t.Fatalf("%s: request %d is rejected", name, i)
+			}
+			if recorder.Header().Get("RateLimit-Limit") != "" {
+				t.Errorf("%s: headers are set without a limit taken", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package rbac
+
+import (
+	"log/slog"
+	"strings"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	roleService "github.com/elusiv0/medods_test/internal/service/role"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission must be used after auth middleware, it lets through only
+// users whose token roles grant every one of permissions.
+func RequirePermission(
+	roleService *roleService.RoleService,
+	logger *slog.Logger,
+	permissions ...string,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenInfo, ok := c.MustGet("tokenInfo").(tokenManager.TokenInfo)
+		if !ok {
+			c.Error(api.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		allowed, err := roleService.Allowed(c.Request.Context(), tokenInfo.Roles, permissions...)
+		if err != nil {
+			logger.Error("RbacMiddleware: " + err.Error())
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			logger.Warn(
+				"RbacMiddleware: access denied",
+				slog.String("uuid", tokenInfo.UUID),
+				slog.String("permissions", strings.Join(permissions, ",")),
+			)
+			c.Error(api.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,14 @@
+package requestinfo
+
+import (
+	reqUtils "github.com/elusiv0/medods_test/internal/util/request"
+	"github.com/gin-gonic/gin"
+)
+
+func RequestInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqUtils.SetInfo(c, "")
+
+		c.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package scope
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	"github.com/elusiv0/medods_test/pkg/scope"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScopes must be used after auth middleware, it lets through only tokens
+// whose scope covers every one of scopes. Rejection carries WWW-Authenticate
+// challenge of RFC 6750 naming the required scopes.
+func RequireScopes(
+	logger *slog.Logger,
+	scopes ...string,
+) gin.HandlerFunc {
+	challenge := fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(scopes, " "))
+
+	return func(c *gin.Context) {
+		tokenInfo, ok := c.MustGet("tokenInfo").(tokenManager.TokenInfo)
+		if !ok {
+			c.Error(api.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		granted, err := scope.Parse(tokenInfo.Scope)
+		if err != nil {
+			logger.Error("ScopeMiddleware: " + err.Error())
+		}
+		for _, required := range scopes {
+			if !granted.Covers(required) {
+				logger.Warn(
+					"ScopeMiddleware: insufficient scope",
+					slog.String("uuid", tokenInfo.UUID),
+					slog.String("scope", tokenInfo.Scope),
+					slog.String("required", required),
+				)
+				c.Header("WWW-Authenticate", challenge)
+				c.Error(api.ErrInsufficientScope)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
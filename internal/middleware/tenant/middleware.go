@@ -0,0 +1,49 @@
+package tenant
+
+import (
+	"log/slog"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	tenantService "github.com/elusiv0/medods_test/internal/service/tenant"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+// Resolve finds tenant of request by its host or header and stores its id into
+// request context, every repository query of the request is scoped to it.
+func Resolve(
+	tenantService *tenantService.TenantService,
+	header string,
+	logger *slog.Logger,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		tenant, err := tenantService.Resolve(ctx, c.Request.Host, c.GetHeader(header))
+		if err != nil {
+			logger.Warn("TenantMiddleware: " + err.Error())
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Set("tenant", tenant.ID)
+		c.Request = c.Request.WithContext(tenantUtil.WithTenant(ctx, tenant.ID))
+
+		c.Next()
+	}
+}
+
+// RequireDefault must be used after Resolve, it lets through only requests of
+// the default tenant. Admins of other tenants must not manage what all tenants
+// share, such as tenants themselves.
+func RequireDefault(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tenant := tenantUtil.FromContext(c.Request.Context()); tenant != tenantUtil.DefaultID {
+			logger.Warn("TenantMiddleware: global endpoint requested by tenant", slog.String("tenant", tenant))
+			c.Error(api.ErrForbidden)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,222 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"github.com/elusiv0/medods_test/pkg/ratelimit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Settings carries configured values migrations depend on.
+type Settings struct {
+	// OutboxRetention is how long delivered outbox messages are kept.
+	OutboxRetention time.Duration
+}
+
+// All returns schema migrations of the service. New migrations are appended with
+// the next version, released ones are never edited.
+func All(settings Settings) []mongoClient.Migration {
+	return []mongoClient.Migration{
+		{
+			Version:     1,
+			Description: "validate users and tokens",
+			Up:          validateUsersAndTokens,
+		},
+		{
+			Version:     2,
+			Description: "index tokens by user and expire them by expires_at",
+			Up: createIndexes("tokens",
+				mongo.IndexModel{Keys: bson.D{{Key: "user_uuid", Value: 1}}},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "expires_at", Value: 1}},
+					Options: options.Index().SetExpireAfterSeconds(0),
+				},
+			),
+		},
+		{
+			Version:     3,
+			Description: "unique audit seq",
+			// unique seq is what keeps concurrent writers from forking the chain
+			Up: createIndexes("audit",
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "seq", Value: 1}},
+					Options: options.Index().
+						SetUnique(true).
+						SetPartialFilterExpression(bson.M{"seq": bson.M{"$exists": true}}),
+				},
+				mongo.IndexModel{Keys: bson.D{{Key: "subject", Value: 1}, {Key: "_id", Value: -1}}},
+			),
+		},
+		{
+			Version:     4,
+			Description: "index pending webhook deliveries",
+			Up: createIndexes("webhook_deliveries",
+				mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "subscription_id", Value: 1}, {Key: "_id", Value: -1}}},
+			),
+		},
+		{
+			Version:     5,
+			Description: "index pending outbox messages and expire delivered ones",
+			Up: createIndexes("outbox",
+				mongo.IndexModel{
+					Keys: bson.D{{Key: "next_attempt_at", Value: 1}},
+					Options: options.Index().
+						SetPartialFilterExpression(bson.M{"delivered_at": bson.M{"$exists": false}}),
+				},
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "delivered_at", Value: 1}},
+					Options: options.Index().SetExpireAfterSeconds(int32(settings.OutboxRetention.Seconds())),
+				},
+			),
+		},
+		{
+			Version:     6,
+			Description: "expire rate limit buckets",
+			Up: func(ctx context.Context, db *mongo.Database) error {
+				return ratelimit.NewMongoStore(db.Collection("rate_limits")).EnsureIndexes(ctx)
+			},
+		},
+		{
+			Version:     7,
+			Description: "index users by roles",
+			Up: createIndexes("users",
+				mongo.IndexModel{Keys: bson.D{{Key: "roles", Value: 1}}},
+			),
+		},
+		{
+			Version:     8,
+			Description: "assign existing data to default tenant and index it by tenant",
+			Up:          assignDefaultTenant,
+		},
+		{
+			Version:     9,
+			Description: "unique group names of tenant, index groups by members",
+			Up: createIndexes("groups",
+				mongo.IndexModel{
+					Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "members", Value: 1}}},
+				mongo.IndexModel{Keys: bson.D{{Key: "roles", Value: 1}}},
+			),
+		},
+		{
+			Version:     10,
+			Description: "assign lockouts, audit, webhooks and outbox to default tenant and index them by tenant",
+			Up:          assignDefaultTenantToEvents,
+		},
+	}
+}
+
+// assignDefaultTenant stamps users, tokens and signing keys stored before tenants
+// were introduced with the default tenant, queries are always scoped to tenant.
+func assignDefaultTenant(ctx context.Context, db *mongo.Database) error {
+	filter := bson.M{"tenant_id": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"tenant_id": tenantUtil.DefaultID}}
+	for _, collection := range []string{"users", "tokens", "signing_keys"} {
+		if _, err := db.Collection(collection).UpdateMany(ctx, filter, update); err != nil {
+			return fmt.Errorf("assign default tenant to %s: %w", collection, err)
+		}
+	}
+
+	if err := createIndexes("users",
+		mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "_id", Value: 1}}},
+	)(ctx, db); err != nil {
+		return err
+	}
+	if err := createIndexes("tokens",
+		mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_uuid", Value: 1}}},
+	)(ctx, db); err != nil {
+		return err
+	}
+
+	return createIndexes("tenants",
+		mongo.IndexModel{Keys: bson.D{{Key: "hosts", Value: 1}}},
+	)(ctx, db)
+}
+
+// assignDefaultTenantToEvents stamps lockouts, audit entries, webhooks and outbox
+// messages stored before they were scoped to tenant with the default tenant.
+// Lockouts were keyed by user uuid, now they are keyed by tenant and uuid.
+func assignDefaultTenantToEvents(ctx context.Context, db *mongo.Database) error {
+	filter := bson.M{"tenant_id": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"tenant_id": tenantUtil.DefaultID}}
+	for _, collection := range []string{"audit", "webhook_subscriptions", "webhook_deliveries", "outbox"} {
+		if _, err := db.Collection(collection).UpdateMany(ctx, filter, update); err != nil {
+			return fmt.Errorf("assign default tenant to %s: %w", collection, err)
+		}
+	}
+
+	lockouts := mongo.Pipeline{{{Key: "$set", Value: bson.M{"uuid": "$_id", "tenant_id": tenantUtil.DefaultID}}}}
+	if _, err := db.Collection("lockouts").UpdateMany(ctx, filter, lockouts); err != nil {
+		return fmt.Errorf("assign default tenant to lockouts: %w", err)
+	}
+
+	if err := createIndexes("lockouts",
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "uuid", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	)(ctx, db); err != nil {
+		return err
+	}
+	if err := createIndexes("audit",
+		mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "_id", Value: -1}}},
+		mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "subject", Value: 1}, {Key: "_id", Value: -1}}},
+	)(ctx, db); err != nil {
+		return err
+	}
+	if err := createIndexes("webhook_subscriptions",
+		mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "active", Value: 1}, {Key: "events", Value: 1}}},
+	)(ctx, db); err != nil {
+		return err
+	}
+
+	return createIndexes("webhook_deliveries",
+		mongo.IndexModel{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "_id", Value: -1}}},
+	)(ctx, db)
+}
+
+func validateUsersAndTokens(ctx context.Context, db *mongo.Database) error {
+	users := bson.M{
+		"bsonType": "object",
+		"required": bson.A{"_id", "name"},
+		"properties": bson.M{
+			"_id":  bson.M{"bsonType": "string"},
+			"name": bson.M{"bsonType": "string"},
+		},
+	}
+	if err := mongoClient.EnsureCollection(ctx, db, "users", users); err != nil {
+		return err
+	}
+
+	tokens := bson.M{
+		"bsonType": "object",
+		"required": bson.A{"digest", "user_uuid", "session_started_at", "expires_at"},
+		"properties": bson.M{
+			"digest":             bson.M{"bsonType": "string"},
+			"user_uuid":          bson.M{"bsonType": "string"},
+			"session_started_at": bson.M{"bsonType": "date"},
+			"expires_at":         bson.M{"bsonType": "date"},
+		},
+	}
+
+	return mongoClient.EnsureCollection(ctx, db, "tokens", tokens)
+}
+
+func createIndexes(collection string, indexes ...mongo.IndexModel) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes); err != nil {
+			return fmt.Errorf("create indexes of %s: %w", collection, err)
+		}
+
+		return nil
+	}
+}
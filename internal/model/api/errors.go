@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"time"
 )
 
 var (
@@ -11,4 +12,23 @@ var (
 	ErrAccessTokenExpired = errors.New("token is expired")
 	ErrTokenMismatch      = errors.New("tokens pair mismatch: invalid refresh token for access token")
 	ErrBadRefreshRequest  = errors.New("refresh and access token are required")
+	ErrTooManyRequests    = errors.New("too many requests, try again later")
+	ErrForbidden          = errors.New("access denied")
+	ErrBadPagination      = errors.New("invalid pagination parameters")
+	ErrServiceUnavailable = errors.New("service is temporarily unavailable, try again later")
+	ErrInsufficientScope  = errors.New("access token scope is insufficient")
+	ErrTenantMismatch     = errors.New("access token was issued for another tenant")
 )
+
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
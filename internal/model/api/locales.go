@@ -0,0 +1,10 @@
+package api
+
+import (
+	"embed"
+)
+
+//go:embed locales
+var Locales embed.FS
+
+const LocalesDir = "locales"
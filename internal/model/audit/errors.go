@@ -0,0 +1,11 @@
+package audit
+
+import (
+	"errors"
+)
+
+var (
+	ErrBadFilter     = errors.New("invalid audit filter")
+	ErrChainConflict = errors.New("audit chain head moved concurrently")
+	ErrHeadNotFound  = errors.New("signed audit chain head not found")
+)
@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"time"
+)
+
+const (
+	TypeSignIn      = "sign_in"
+	TypeRefresh     = "refresh"
+	TypeRevocation  = "revocation"
+	TypeLockout     = "lockout"
+	TypeAdminAction = "admin_action"
+
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+type Entry struct {
+	ID        string    `json:"id"`
+	Seq       int64     `json:"seq"`
+	Tenant    string    `json:"tenant"`
+	Type      string    `json:"type"`
+	Action    string    `json:"action,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	Outcome   string    `json:"outcome"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	PrevHash  string    `json:"prev_hash,omitempty"`
+	Hash      string    `json:"hash,omitempty"`
+}
+
+type Checkpoint struct {
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash"`
+	Signature string    `json:"signature"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type VerifyReport struct {
+	Checked     int64  `json:"checked"`
+	Unchained   int64  `json:"unchained"`
+	Checkpoints int64  `json:"checkpoints"`
+	LastSeq     int64  `json:"last_seq"`
+	HeadSeq     int64  `json:"head_seq"`
+	Valid       bool   `json:"valid"`
+	BrokenSeq   int64  `json:"broken_seq,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+type Filter struct {
+	User   string
+	Types  []string
+	From   time.Time
+	To     time.Time
+	Cursor string
+	Limit  int
+}
+
+type Page struct {
+	Entries    []Entry `json:"entries"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,34 @@
+package event
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	TypeSignIn     = "auth.sign_in"
+	TypeRefresh    = "auth.refresh"
+	TypeRevocation = "auth.revocation"
+	TypeLockout    = "auth.lockout"
+)
+
+var Types = []string{
+	TypeSignIn,
+	TypeRefresh,
+	TypeRevocation,
+	TypeLockout,
+}
+
+type Event struct {
+	ID         string            `json:"id"`
+	Tenant     string            `json:"tenant,omitempty"`
+	Type       string            `json:"type"`
+	Subject    string            `json:"subject"`
+	OccurredAt time.Time         `json:"occurred_at"`
+	Data       map[string]string `json:"data,omitempty"`
+}
+
+// Hook is notified about every event emitted by AuthService.
+type Hook interface {
+	Handle(ctx context.Context, event Event)
+}
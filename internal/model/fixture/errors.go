@@ -0,0 +1,10 @@
+package fixture
+
+import (
+	"errors"
+)
+
+var (
+	ErrProductionEnvironment = errors.New("fixtures are only loaded in local, dev or test environment")
+	ErrBadFixture            = errors.New("invalid fixture")
+)
@@ -0,0 +1,41 @@
+package fixture
+
+import (
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
+)
+
+// Fixtures is seed data for local and test environments.
+type Fixtures struct {
+	Tenants []Tenant `json:"tenants" yaml:"tenants"`
+	Roles   []Role   `json:"roles" yaml:"roles"`
+	Users   []User   `json:"users" yaml:"users"`
+}
+
+type Tenant struct {
+	ID     string           `json:"id" yaml:"id"`
+	Name   string           `json:"name" yaml:"name"`
+	Hosts  []string         `json:"hosts" yaml:"hosts"`
+	Policy tenantDto.Policy `json:"policy" yaml:"policy"`
+}
+
+type Role struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Permissions []string `json:"permissions" yaml:"permissions"`
+}
+
+// User belongs to the default tenant unless Tenant is set.
+type User struct {
+	UUID     string   `json:"uuid" yaml:"uuid"`
+	Tenant   string   `json:"tenant" yaml:"tenant"`
+	Name     string   `json:"name" yaml:"name"`
+	Disabled bool     `json:"disabled" yaml:"disabled"`
+	Roles    []string `json:"roles" yaml:"roles"`
+}
+
+// Result counts tenants, roles and users together.
+type Result struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+}
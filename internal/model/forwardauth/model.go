@@ -0,0 +1,13 @@
+package forwardauth
+
+// Request describes request reverse proxy asks to authorize, Method and URI are
+// taken from X-Forwarded-Method and X-Forwarded-Uri (Traefik) or X-Original-Method
+// and X-Original-URI (nginx) headers.
+type Request struct {
+	Token     string
+	Method    string
+	URI       string
+	IP        string
+	UserAgent string
+	Scopes    []string
+}
@@ -0,0 +1,12 @@
+package group
+
+import (
+	"errors"
+)
+
+var (
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("group with this name already exists")
+	ErrBadGroup      = errors.New("group requires name of lowercase letters, digits, '-' or '_', defined roles and existing parents")
+	ErrGroupCycle    = errors.New("group can not be nested into itself or its descendants")
+)
@@ -0,0 +1,43 @@
+package group
+
+import (
+	"time"
+)
+
+// Group grants its roles to members. A group nested into Parents grants their
+// roles as well, so do parents of parents.
+type Group struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Roles       []string  `json:"roles"`
+	Parents     []string  `json:"parents"`
+	Members     []string  `json:"members"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CreateGroup struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"`
+	Parents     []string `json:"parents"`
+}
+
+type UpdateGroup struct {
+	Name        *string   `json:"name"`
+	Description *string   `json:"description"`
+	Roles       *[]string `json:"roles"`
+	Parents     *[]string `json:"parents"`
+}
+
+type Member struct {
+	UUID string `json:"uuid"`
+}
+
+// Effective is what user is granted directly and through groups, Groups lists
+// groups user is member of and every group they are nested into.
+type Effective struct {
+	Roles       []string `json:"roles"`
+	Groups      []string `json:"groups"`
+	Permissions []string `json:"permissions"`
+}
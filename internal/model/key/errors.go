@@ -0,0 +1,10 @@
+package key
+
+import (
+	"errors"
+)
+
+var (
+	ErrKeyNotFound  = errors.New("signing key not found or already activated")
+	ErrKeyNotLoaded = errors.New("signing key is too new, some replicas may not have loaded it yet")
+)
@@ -0,0 +1,23 @@
+package key
+
+import (
+	"time"
+)
+
+const (
+	StatusPending = "pending"
+	StatusActive  = "active"
+	StatusRetired = "retired"
+)
+
+// Key signs access tokens of Tenant. Only one key of a tenant is active at a time,
+// retired keys still verify tokens issued before the rotation.
+type Key struct {
+	ID          string    `json:"id"`
+	Tenant      string    `json:"tenant"`
+	Secret      []byte    `json:"-"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	ActivatedAt time.Time `json:"activated_at,omitempty"`
+	RetiredAt   time.Time `json:"retired_at,omitempty"`
+}
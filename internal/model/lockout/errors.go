@@ -0,0 +1,11 @@
+package lockout
+
+import (
+	"errors"
+)
+
+var (
+	ErrAccountLocked         = errors.New("account is temporarily locked after too many failed attempts")
+	ErrAuthenticationDelayed = errors.New("too many failed attempts, try again later")
+	ErrLockoutNotFound       = errors.New("no failed attempts registered for user")
+)
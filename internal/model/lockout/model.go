@@ -0,0 +1,12 @@
+package lockout
+
+import (
+	"time"
+)
+
+type Lockout struct {
+	UUID          string    `json:"uuid"`
+	Failures      int       `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until,omitempty"`
+}
@@ -0,0 +1,9 @@
+package outbox
+
+import (
+	"errors"
+)
+
+var (
+	ErrMessageNotFound = errors.New("outbox message not found")
+)
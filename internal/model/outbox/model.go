@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"time"
+)
+
+const (
+	TypeUserCreated   = "user.created"
+	TypeUserUpdated   = "user.updated"
+	TypeUserDisabled  = "user.disabled"
+	TypeUserEnabled   = "user.enabled"
+	TypeUserDeleted   = "user.deleted"
+	TypeTokensIssued  = "tokens.issued"
+	TypeTokensRotated = "tokens.rotated"
+	TypeTokensRevoked = "tokens.revoked"
+)
+
+// Message is an event waiting in outbox to be published to the broker.
+type Message struct {
+	ID            string
+	Tenant        string
+	EventID       string
+	EventType     string
+	Key           string
+	Payload       string
+	Attempts      int
+	NextAttemptAt time.Time
+	DeliveredAt   time.Time
+	LastError     string
+	CreatedAt     time.Time
+}
@@ -0,0 +1,10 @@
+package policy
+
+import (
+	"errors"
+)
+
+var (
+	ErrBadFilter    = errors.New("invalid decision log filter")
+	ErrNoPolicyPath = errors.New("policy path is not configured")
+)
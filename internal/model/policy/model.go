@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/elusiv0/medods_test/pkg/policy"
+)
+
+// Attributes the middleware collects, path parameters are added as
+// "resource.<name>" and resource.owner mirrors the uuid one.
+const (
+	AttributeSubjectUUID    = "subject.uuid"
+	AttributeSubjectTenant  = "subject.tenant"
+	AttributeSubjectRoles   = "subject.roles"
+	AttributeSubjectGroups  = "subject.groups"
+	AttributeSubjectScope   = "subject.scope"
+	AttributeSubjectClient  = "subject.client_id"
+	AttributeResourceMethod = "resource.method"
+	AttributeResourceRoute  = "resource.route"
+	AttributeResourcePath   = "resource.path"
+	AttributeResourceOwner  = "resource.owner"
+	AttributeResourcePrefix = "resource."
+	AttributeEnvIP          = "env.ip"
+	AttributeEnvTime        = "env.time"
+	AttributeEnvWeekday     = "env.weekday"
+	AttributeEnvUserAgent   = "env.user_agent"
+)
+
+// Decision is decision log entry, Enforced is false for denials let through in
+// dry-run mode.
+type Decision struct {
+	Time     time.Time `json:"time"`
+	Subject  string    `json:"subject,omitempty"`
+	Tenant   string    `json:"tenant,omitempty"`
+	Method   string    `json:"method"`
+	Route    string    `json:"route"`
+	Path     string    `json:"path"`
+	IP       string    `json:"ip,omitempty"`
+	Allowed  bool      `json:"allowed"`
+	Enforced bool      `json:"enforced"`
+	Policy   string    `json:"policy,omitempty"`
+	Reason   string    `json:"reason"`
+}
+
+type Request struct {
+	Method     string
+	Route      string
+	Path       string
+	Attributes policy.Attributes
+}
+
+type Filter struct {
+	Denied  bool
+	Subject string
+	Limit   int
+}
+
+type Status struct {
+	Path     string          `json:"path,omitempty"`
+	DryRun   bool            `json:"dry_run"`
+	LoadedAt time.Time       `json:"loaded_at,omitempty"`
+	Policies []policy.Policy `json:"policies"`
+}
@@ -0,0 +1,12 @@
+package role
+
+import (
+	"errors"
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrRoleExists   = errors.New("role already exists")
+	ErrBadRole      = errors.New("role requires name of lowercase letters, digits, '-' or '_' and valid permissions")
+	ErrUnknownRole  = errors.New("assigned role is not defined")
+)
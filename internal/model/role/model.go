@@ -0,0 +1,30 @@
+package role
+
+import (
+	"time"
+)
+
+// PermissionAdmin grants access to api/admin, roles holding "*" grant it as well.
+const PermissionAdmin = "admin:*"
+
+type Role struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type CreateRole struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type UpdateRole struct {
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+}
+
+type Assignment struct {
+	Roles []string `json:"roles"`
+}
@@ -0,0 +1,13 @@
+package tenant
+
+import (
+	"errors"
+)
+
+var (
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrTenantExists   = errors.New("tenant already exists")
+	ErrBadTenant      = errors.New("tenant requires id of lowercase letters, digits or '-', hosts not used by other tenants and valid policy")
+	ErrUnknownTenant  = errors.New("request does not belong to any known tenant")
+	ErrTenantDisabled = errors.New("tenant is disabled")
+)
@@ -0,0 +1,56 @@
+package tenant
+
+import (
+	"time"
+)
+
+// Tenant is a product served by the service, users and sessions of one tenant
+// are never visible to another.
+type Tenant struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Hosts     []string  `json:"hosts"`
+	Disabled  bool      `json:"disabled"`
+	Policy    Policy    `json:"policy"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Policy overrides service settings for tenant, zero values keep them.
+type Policy struct {
+	AccessLifeTime  Duration `json:"access_lifetime,omitempty" yaml:"access_lifetime"`
+	RefreshLifeTime Duration `json:"refresh_lifetime,omitempty" yaml:"refresh_lifetime"`
+	SessionMaxAge   Duration `json:"session_max_age,omitempty" yaml:"session_max_age"`
+	Clients         []string `json:"clients,omitempty" yaml:"clients"`
+	DefaultScopes   []string `json:"default_scopes,omitempty" yaml:"default_scopes"`
+}
+
+type CreateTenant struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Hosts  []string `json:"hosts"`
+	Policy Policy   `json:"policy"`
+}
+
+type UpdateTenant struct {
+	Name     *string   `json:"name"`
+	Hosts    *[]string `json:"hosts"`
+	Disabled *bool     `json:"disabled"`
+	Policy   *Policy   `json:"policy"`
+}
+
+// Duration is written as "15m" in api and fixtures.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+
+	return nil
+}
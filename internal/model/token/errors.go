@@ -6,4 +6,7 @@ import (
 
 var (
 	ErrRefreshTokenNotRegistered = errors.New("refresh token not found in registered tokens")
+	ErrRefreshTokenExpired       = errors.New("refresh token expired")
+	ErrInvalidScope              = errors.New("requested scope is invalid or exceeds the granted one")
+	ErrInvalidClient             = errors.New("unknown client")
 )
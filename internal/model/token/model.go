@@ -1,11 +1,34 @@
 package token
 
+import (
+	"time"
+)
+
 type TokenResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope,omitempty"`
 }
 
+// RefreshRequest may narrow scope of the session, empty Scope keeps it.
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
 	AccessToken  string `json:"access_token"`
+	Scope        string `json:"scope"`
+}
+
+// ScopeRequest names client signing in and space delimited scopes it asks for,
+// empty Scope asks for every scope the client may get.
+type ScopeRequest struct {
+	ClientID string
+	Scope    string
+}
+
+type Session struct {
+	ID        string    `json:"id"`
+	UserUUID  string    `json:"user_uuid"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Scope     string    `json:"scope,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
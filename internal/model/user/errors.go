@@ -6,4 +6,8 @@ import (
 
 var (
 	ErrUserNotFound = errors.New("user not found")
+	ErrUserDisabled = errors.New("user is disabled")
+	ErrNoSuchUser   = errors.New("no user with given uuid")
+	ErrBadUser      = errors.New("user name must not be empty")
+	ErrBadFilter    = errors.New("invalid users filter")
 )
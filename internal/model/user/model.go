@@ -1,10 +1,31 @@
 package user
 
 type User struct {
-	UUID string `json:"uuid"`
-	Name string `json:"name"`
+	UUID     string   `json:"uuid"`
+	Tenant   string   `json:"tenant"`
+	Name     string   `json:"name"`
+	Disabled bool     `json:"disabled"`
+	Roles    []string `json:"roles"`
 }
 
 type CreateUser struct {
 	Name string `json:"name"`
 }
+
+type UpdateUser struct {
+	Name *string `json:"name"`
+}
+
+// Filter selects users, Query matches name case-insensitively and Disabled is
+// applied only when set.
+type Filter struct {
+	Query    string
+	Disabled *bool
+	Cursor   string
+	Limit    int
+}
+
+type Page struct {
+	Users      []User `json:"users"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,11 @@
+package webhook
+
+import (
+	"errors"
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrBadSubscription      = errors.New("webhook subscription requires absolute http(s) url and known event types")
+)
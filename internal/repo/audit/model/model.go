@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Entry struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Seq       int64              `bson:"seq,omitempty"`
+	Tenant    string             `bson:"tenant_id"`
+	Type      string             `bson:"type"`
+	Action    string             `bson:"action,omitempty"`
+	Actor     string             `bson:"actor,omitempty"`
+	Subject   string             `bson:"subject,omitempty"`
+	IP        string             `bson:"ip,omitempty"`
+	UserAgent string             `bson:"user_agent,omitempty"`
+	Outcome   string             `bson:"outcome"`
+	Reason    string             `bson:"reason,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+	PrevHash  string             `bson:"prev_hash,omitempty"`
+	Hash      string             `bson:"hash,omitempty"`
+}
+
+type Checkpoint struct {
+	Seq       int64     `bson:"_id"`
+	Hash      string    `bson:"hash"`
+	Signature string    `bson:"signature"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// Head is the only document of audit_head collection, it holds signed seq and
+// hash of the newest entry.
+type Head struct {
+	ID        string    `bson:"_id"`
+	Seq       int64     `bson:"seq"`
+	Hash      string    `bson:"hash"`
+	Signature string    `bson:"signature"`
+	CreatedAt time.Time `bson:"created_at"`
+}
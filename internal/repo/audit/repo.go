@@ -0,0 +1,271 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	mapper "github.com/elusiv0/medods_test/internal/mapper/audit"
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	"github.com/elusiv0/medods_test/internal/repo"
+	auditModel "github.com/elusiv0/medods_test/internal/repo/audit/model"
+	"github.com/elusiv0/medods_test/internal/util/hashchain"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditRepo struct {
+	collection  *mongo.Collection
+	checkpoints *mongo.Collection
+	head        *mongo.Collection
+	logger      *slog.Logger
+}
+
+const (
+	collectionName            = "audit"
+	checkpointsCollectionName = "audit_checkpoints"
+	headCollectionName        = "audit_head"
+	headID                    = "head"
+	maxAppendAttempts         = 10
+)
+
+var _ repo.AuditRepo = (*AuditRepo)(nil)
+
+func New(
+	client *mongoClient.MongoClient,
+	log *slog.Logger,
+) *AuditRepo {
+	collection := client.MongoDatabase.Collection(collectionName)
+	checkpoints := client.MongoDatabase.Collection(checkpointsCollectionName)
+	head := client.MongoDatabase.Collection(headCollectionName)
+
+	return &AuditRepo{
+		collection:  collection,
+		checkpoints: checkpoints,
+		head:        head,
+		logger:      log,
+	}
+}
+
+// InsertEntry appends entry of tenant of ctx to the end of hash chain, the chain
+// is shared by all tenants. When another writer takes the same seq first,
+// insert is retried on top of the new head.
+func (repo *AuditRepo) InsertEntry(ctx context.Context, entry auditDto.Entry) (auditDto.Entry, error) {
+	entry.Tenant = tenantUtil.FromContext(ctx)
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		head := auditModel.Entry{}
+		opts := options.FindOne().SetSort(bson.D{{Key: "seq", Value: -1}})
+		err := repo.collection.FindOne(ctx, bson.M{"seq": bson.M{"$exists": true}}, opts).Decode(&head)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return auditDto.Entry{}, fmt.Errorf("AuditRepo - InsertEntry - FindOne: %w", err)
+		}
+
+		entry.Seq = head.Seq + 1
+		entry.PrevHash = head.Hash
+		entry.Hash = hashchain.Hash(entry)
+
+		result, err := repo.collection.InsertOne(ctx, mapper.EntryToModel(entry))
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return auditDto.Entry{}, fmt.Errorf("AuditRepo - InsertEntry - InsertOne: %w", err)
+		}
+
+		entry.ID = result.InsertedID.(primitive.ObjectID).Hex()
+		return entry, nil
+	}
+
+	return auditDto.Entry{}, fmt.Errorf("AuditRepo - InsertEntry: %w", auditDto.ErrChainConflict)
+}
+
+// ListEntries returns entries of tenant of ctx newest first, starting right
+// after filter.Cursor.
+func (repo *AuditRepo) ListEntries(ctx context.Context, filter auditDto.Filter) ([]auditDto.Entry, error) {
+	query, err := buildQuery(filter)
+	if err != nil {
+		return nil, fmt.Errorf("AuditRepo - ListEntries: %w", err)
+	}
+	query = scoped(ctx, query)
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := repo.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("AuditRepo - ListEntries - Find: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	entries := make([]auditDto.Entry, 0)
+	for cursor.Next(ctx) {
+		entryModel := auditModel.Entry{}
+		if err := cursor.Decode(&entryModel); err != nil {
+			return nil, fmt.Errorf("AuditRepo - ListEntries - Decode: %w", err)
+		}
+		entries = append(entries, mapper.ModelToEntry(entryModel))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("AuditRepo - ListEntries - Cursor: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ExportEntries streams every matching entry of tenant of ctx in chain order.
+func (repo *AuditRepo) ExportEntries(ctx context.Context, filter auditDto.Filter, fn func(auditDto.Entry) error) error {
+	query, err := buildQuery(filter)
+	if err != nil {
+		return fmt.Errorf("AuditRepo - ExportEntries: %w", err)
+	}
+
+	if err := repo.walk(ctx, scoped(ctx, query), fn); err != nil {
+		return fmt.Errorf("AuditRepo - ExportEntries - %w", err)
+	}
+
+	return nil
+}
+
+// WalkChain streams entries of every tenant in chain order, the chain can only
+// be verified as a whole.
+func (repo *AuditRepo) WalkChain(ctx context.Context, fn func(auditDto.Entry) error) error {
+	if err := repo.walk(ctx, bson.M{}, fn); err != nil {
+		return fmt.Errorf("AuditRepo - WalkChain - %w", err)
+	}
+
+	return nil
+}
+
+func (repo *AuditRepo) walk(ctx context.Context, query bson.M, fn func(auditDto.Entry) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "seq", Value: 1}, {Key: "_id", Value: 1}})
+
+	cursor, err := repo.collection.Find(ctx, query, opts)
+	if err != nil {
+		return fmt.Errorf("Find: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		entryModel := auditModel.Entry{}
+		if err := cursor.Decode(&entryModel); err != nil {
+			return fmt.Errorf("Decode: %w", err)
+		}
+		if err := fn(mapper.ModelToEntry(entryModel)); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("Cursor: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *AuditRepo) InsertCheckpoint(ctx context.Context, checkpoint auditDto.Checkpoint) error {
+	if _, err := repo.checkpoints.InsertOne(ctx, mapper.CheckpointToModel(checkpoint)); err != nil {
+		return fmt.Errorf("AuditRepo - InsertCheckpoint - InsertOne: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *AuditRepo) ListCheckpoints(ctx context.Context) ([]auditDto.Checkpoint, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+
+	cursor, err := repo.checkpoints.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("AuditRepo - ListCheckpoints - Find: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	checkpoints := make([]auditDto.Checkpoint, 0)
+	for cursor.Next(ctx) {
+		checkpointModel := auditModel.Checkpoint{}
+		if err := cursor.Decode(&checkpointModel); err != nil {
+			return nil, fmt.Errorf("AuditRepo - ListCheckpoints - Decode: %w", err)
+		}
+		checkpoints = append(checkpoints, mapper.ModelToCheckpoint(checkpointModel))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("AuditRepo - ListCheckpoints - Cursor: %w", err)
+	}
+
+	return checkpoints, nil
+}
+
+// SetHead moves signed head forward, head of a newer entry stored by another
+// writer is kept.
+func (repo *AuditRepo) SetHead(ctx context.Context, head auditDto.Checkpoint) error {
+	_, err := repo.head.ReplaceOne(
+		ctx,
+		bson.M{"_id": headID, "seq": bson.M{"$lt": head.Seq}},
+		mapper.HeadToModel(headID, head),
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("AuditRepo - SetHead - ReplaceOne: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *AuditRepo) GetHead(ctx context.Context) (auditDto.Checkpoint, error) {
+	headModel := auditModel.Head{}
+	if err := repo.head.FindOne(ctx, bson.M{"_id": headID}).Decode(&headModel); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return auditDto.Checkpoint{}, fmt.Errorf("AuditRepo - GetHead: %w", auditDto.ErrHeadNotFound)
+		}
+		return auditDto.Checkpoint{}, fmt.Errorf("AuditRepo - GetHead - FindOne: %w", err)
+	}
+
+	return mapper.ModelToHead(headModel), nil
+}
+
+func buildQuery(filter auditDto.Filter) (bson.M, error) {
+	query := bson.M{}
+
+	if filter.User != "" {
+		query["$or"] = bson.A{
+			bson.M{"subject": filter.User},
+			bson.M{"actor": filter.User},
+		}
+	}
+	if len(filter.Types) > 0 {
+		query["type"] = bson.M{"$in": filter.Types}
+	}
+
+	createdAt := bson.M{}
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	if filter.Cursor != "" {
+		id, err := primitive.ObjectIDFromHex(filter.Cursor)
+		if err != nil {
+			return nil, auditDto.ErrBadFilter
+		}
+		query["_id"] = bson.M{"$lt": id}
+	}
+
+	return query, nil
+}
+
+// scoped restricts filter to entries of tenant of ctx.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	filter["tenant_id"] = tenantUtil.FromContext(ctx)
+
+	return filter
+}
@@ -0,0 +1,16 @@
+package group
+
+import (
+	"time"
+)
+
+type Group struct {
+	ID          string    `bson:"_id"`
+	Tenant      string    `bson:"tenant_id"`
+	Name        string    `bson:"name"`
+	Description string    `bson:"description,omitempty"`
+	Roles       []string  `bson:"roles"`
+	Parents     []string  `bson:"parents"`
+	Members     []string  `bson:"members"`
+	CreatedAt   time.Time `bson:"created_at"`
+}
@@ -0,0 +1,226 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	mapper "github.com/elusiv0/medods_test/internal/mapper/group"
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	"github.com/elusiv0/medods_test/internal/repo"
+	groupModel "github.com/elusiv0/medods_test/internal/repo/group/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GroupRepo struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+const (
+	collectionName = "groups"
+)
+
+var _ repo.GroupRepo = (*GroupRepo)(nil)
+
+// withoutMembers leaves out members of groups resolved on every sign-in, a
+// large group would otherwise be read in full for each of its members.
+var withoutMembers = bson.M{"members": 0}
+
+func New(
+	client *mongoClient.MongoClient,
+	log *slog.Logger,
+) *GroupRepo {
+	return &GroupRepo{
+		collection: client.MongoDatabase.Collection(collectionName),
+		logger:     log,
+	}
+}
+
+func (repo *GroupRepo) InsertGroup(ctx context.Context, group groupDto.Group) error {
+	model := mapper.GroupToModel(group, tenantUtil.FromContext(ctx))
+	if _, err := repo.collection.InsertOne(ctx, model); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = groupDto.ErrGroupExists
+		}
+		return fmt.Errorf("GroupRepo - InsertGroup - InsertOne: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *GroupRepo) GetGroup(ctx context.Context, id string) (groupDto.Group, error) {
+	model := groupModel.Group{}
+	if err := repo.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&model); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = groupDto.ErrGroupNotFound
+		}
+		return groupDto.Group{}, fmt.Errorf("GroupRepo - GetGroup - FindOne: %w", err)
+	}
+
+	return mapper.ModelToGroup(model), nil
+}
+
+// ListGroups returns every group of tenant of ctx ordered by name.
+func (repo *GroupRepo) ListGroups(ctx context.Context) ([]groupDto.Group, error) {
+	return repo.find(ctx, "ListGroups", scoped(ctx, bson.M{}), nil)
+}
+
+// ListUserGroups returns groups user is a direct member of, without their members.
+func (repo *GroupRepo) ListUserGroups(ctx context.Context, uuid string) ([]groupDto.Group, error) {
+	return repo.find(ctx, "ListUserGroups", scoped(ctx, bson.M{"members": uuid}), withoutMembers)
+}
+
+// GetGroups returns groups of ids that exist, without their members.
+func (repo *GroupRepo) GetGroups(ctx context.Context, ids []string) ([]groupDto.Group, error) {
+	return repo.find(ctx, "GetGroups", scoped(ctx, bson.M{"_id": bson.M{"$in": ids}}), withoutMembers)
+}
+
+func (repo *GroupRepo) UpdateGroup(ctx context.Context, id string, update groupDto.UpdateGroup) (groupDto.Group, error) {
+	set := bson.M{}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.Description != nil {
+		set["description"] = *update.Description
+	}
+	if update.Roles != nil {
+		set["roles"] = orEmpty(*update.Roles)
+	}
+	if update.Parents != nil {
+		set["parents"] = orEmpty(*update.Parents)
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	model := groupModel.Group{}
+
+	var result *mongo.SingleResult
+	if len(set) == 0 {
+		result = repo.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id}))
+	} else {
+		result = repo.collection.FindOneAndUpdate(ctx, scoped(ctx, bson.M{"_id": id}), bson.M{"$set": set}, opts)
+	}
+	if err := result.Decode(&model); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			err = groupDto.ErrGroupNotFound
+		case mongo.IsDuplicateKeyError(err):
+			err = groupDto.ErrGroupExists
+		}
+		return groupDto.Group{}, fmt.Errorf("GroupRepo - UpdateGroup - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToGroup(model), nil
+}
+
+func (repo *GroupRepo) DeleteGroup(ctx context.Context, id string) error {
+	result, err := repo.collection.DeleteOne(ctx, scoped(ctx, bson.M{"_id": id}))
+	if err != nil {
+		return fmt.Errorf("GroupRepo - DeleteGroup - DeleteOne: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("GroupRepo - DeleteGroup: %w", groupDto.ErrGroupNotFound)
+	}
+
+	return nil
+}
+
+// RemoveParent takes group id out of parents of every group nested into it,
+// returns amount of changed groups.
+func (repo *GroupRepo) RemoveParent(ctx context.Context, id string) (int64, error) {
+	result, err := repo.collection.UpdateMany(ctx, scoped(ctx, bson.M{"parents": id}), bson.M{"$pull": bson.M{"parents": id}})
+	if err != nil {
+		return 0, fmt.Errorf("GroupRepo - RemoveParent - UpdateMany: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (repo *GroupRepo) AddMember(ctx context.Context, id string, uuid string) (groupDto.Group, error) {
+	return repo.updateMembers(ctx, "AddMember", id, bson.M{"$addToSet": bson.M{"members": uuid}})
+}
+
+func (repo *GroupRepo) RemoveMember(ctx context.Context, id string, uuid string) (groupDto.Group, error) {
+	return repo.updateMembers(ctx, "RemoveMember", id, bson.M{"$pull": bson.M{"members": uuid}})
+}
+
+// RemoveUser takes user out of every group of tenant, returns amount of changed groups.
+func (repo *GroupRepo) RemoveUser(ctx context.Context, uuid string) (int64, error) {
+	result, err := repo.collection.UpdateMany(ctx, scoped(ctx, bson.M{"members": uuid}), bson.M{"$pull": bson.M{"members": uuid}})
+	if err != nil {
+		return 0, fmt.Errorf("GroupRepo - RemoveUser - UpdateMany: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// UnassignRole removes role from every group granting it, returns amount of
+// changed groups. Roles are shared by tenants, so the query is not scoped.
+func (repo *GroupRepo) UnassignRole(ctx context.Context, role string) (int64, error) {
+	result, err := repo.collection.UpdateMany(ctx, bson.M{"roles": role}, bson.M{"$pull": bson.M{"roles": role}})
+	if err != nil {
+		return 0, fmt.Errorf("GroupRepo - UnassignRole - UpdateMany: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (repo *GroupRepo) updateMembers(ctx context.Context, method string, id string, update bson.M) (groupDto.Group, error) {
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	model := groupModel.Group{}
+
+	if err := repo.collection.FindOneAndUpdate(ctx, scoped(ctx, bson.M{"_id": id}), update, opts).Decode(&model); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = groupDto.ErrGroupNotFound
+		}
+		return groupDto.Group{}, fmt.Errorf("GroupRepo - %s - FindOneAndUpdate: %w", method, err)
+	}
+
+	return mapper.ModelToGroup(model), nil
+}
+
+func (repo *GroupRepo) find(ctx context.Context, method string, filter bson.M, projection bson.M) ([]groupDto.Group, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	cursor, err := repo.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("GroupRepo - %s - Find: %w", method, err)
+	}
+	defer cursor.Close(ctx)
+
+	groups := make([]groupDto.Group, 0)
+	for cursor.Next(ctx) {
+		model := groupModel.Group{}
+		if err := cursor.Decode(&model); err != nil {
+			return nil, fmt.Errorf("GroupRepo - %s - Decode: %w", method, err)
+		}
+		groups = append(groups, mapper.ModelToGroup(model))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("GroupRepo - %s - Cursor: %w", method, err)
+	}
+
+	return groups, nil
+}
+
+func orEmpty(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+
+	return list
+}
+
+// scoped restricts filter to groups of tenant of ctx.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	filter["tenant_id"] = tenantUtil.FromContext(ctx)
+
+	return filter
+}
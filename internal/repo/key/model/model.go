@@ -0,0 +1,15 @@
+package key
+
+import (
+	"time"
+)
+
+type Key struct {
+	ID          string    `bson:"_id"`
+	Tenant      string    `bson:"tenant_id"`
+	Secret      []byte    `bson:"secret"`
+	Status      string    `bson:"status"`
+	CreatedAt   time.Time `bson:"created_at"`
+	ActivatedAt time.Time `bson:"activated_at,omitempty"`
+	RetiredAt   time.Time `bson:"retired_at,omitempty"`
+}
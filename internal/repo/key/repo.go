@@ -0,0 +1,107 @@
+package key
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	mapper "github.com/elusiv0/medods_test/internal/mapper/key"
+	keyDto "github.com/elusiv0/medods_test/internal/model/key"
+	"github.com/elusiv0/medods_test/internal/repo"
+	keyModel "github.com/elusiv0/medods_test/internal/repo/key/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type KeyRepo struct {
+	client     *mongoClient.MongoClient
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+const (
+	collectionName = "signing_keys"
+)
+
+var _ repo.KeyRepo = (*KeyRepo)(nil)
+
+func New(
+	client *mongoClient.MongoClient,
+	log *slog.Logger,
+) *KeyRepo {
+	collection := client.MongoDatabase.Collection(collectionName)
+
+	return &KeyRepo{
+		client:     client,
+		collection: collection,
+		logger:     log,
+	}
+}
+
+func (repo *KeyRepo) InsertKey(ctx context.Context, key keyDto.Key) error {
+	if _, err := repo.collection.InsertOne(ctx, mapper.KeyToModel(key)); err != nil {
+		return fmt.Errorf("KeyRepo - InsertKey - InsertOne: %w", err)
+	}
+
+	return nil
+}
+
+// ListKeys returns keys of every tenant, they are all loaded into token manager.
+func (repo *KeyRepo) ListKeys(ctx context.Context) ([]keyDto.Key, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := repo.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("KeyRepo - ListKeys - Find: %w", err)
+	}
+
+	models := make([]keyModel.Key, 0)
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, fmt.Errorf("KeyRepo - ListKeys - All: %w", err)
+	}
+
+	keys := make([]keyDto.Key, 0, len(models))
+	for _, model := range models {
+		keys = append(keys, mapper.ModelToKey(model))
+	}
+
+	return keys, nil
+}
+
+// ActivateKey makes pending key id of tenant of ctx active and retires the key
+// previously active for that tenant. The new key is activated first, so that
+// without transactions there is never a moment with no active key.
+func (repo *KeyRepo) ActivateKey(ctx context.Context, id string, at time.Time) error {
+	at = at.UTC()
+	tenant := tenantUtil.FromContext(ctx)
+
+	err := repo.client.WithTransaction(ctx, func(ctx context.Context) error {
+		filter := bson.M{"_id": id, "tenant_id": tenant, "status": keyDto.StatusPending}
+		update := bson.M{"$set": bson.M{"status": keyDto.StatusActive, "activated_at": at}}
+
+		result, err := repo.collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return fmt.Errorf("UpdateOne: %w", err)
+		}
+		if result.MatchedCount == 0 {
+			return keyDto.ErrKeyNotFound
+		}
+
+		filter = bson.M{"_id": bson.M{"$ne": id}, "tenant_id": tenant, "status": keyDto.StatusActive}
+		update = bson.M{"$set": bson.M{"status": keyDto.StatusRetired, "retired_at": at}}
+		if _, err := repo.collection.UpdateMany(ctx, filter, update); err != nil {
+			return fmt.Errorf("UpdateMany: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("KeyRepo - ActivateKey: %w", err)
+	}
+
+	return nil
+}
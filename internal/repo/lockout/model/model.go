@@ -0,0 +1,13 @@
+package lockout
+
+import (
+	"time"
+)
+
+type Lockout struct {
+	UUID          string    `bson:"uuid"`
+	Tenant        string    `bson:"tenant_id"`
+	Failures      int       `bson:"failures"`
+	LastFailureAt time.Time `bson:"last_failure_at"`
+	LockedUntil   time.Time `bson:"locked_until,omitempty"`
+}
@@ -0,0 +1,130 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	mapper "github.com/elusiv0/medods_test/internal/mapper/lockout"
+	lockoutDto "github.com/elusiv0/medods_test/internal/model/lockout"
+	"github.com/elusiv0/medods_test/internal/repo"
+	lockoutModel "github.com/elusiv0/medods_test/internal/repo/lockout/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LockoutRepo struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+const (
+	collectionName = "lockouts"
+)
+
+var _ repo.LockoutRepo = (*LockoutRepo)(nil)
+
+func New(
+	client *mongoClient.MongoClient,
+	log *slog.Logger,
+) *LockoutRepo {
+	collection := client.MongoDatabase.Collection(collectionName)
+
+	return &LockoutRepo{
+		collection: collection,
+		logger:     log,
+	}
+}
+
+func (repo *LockoutRepo) GetLockout(ctx context.Context, uuid string) (lockoutDto.Lockout, error) {
+	lockoutModel := lockoutModel.Lockout{}
+
+	filter := scoped(ctx, bson.M{"uuid": uuid})
+	if err := repo.collection.FindOne(ctx, filter).Decode(&lockoutModel); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = lockoutDto.ErrLockoutNotFound
+		}
+		return lockoutDto.Lockout{}, fmt.Errorf("LockoutRepo - GetLockout - FindOne: %w", err)
+	}
+
+	return mapper.ModelToLockout(lockoutModel), nil
+}
+
+// RegisterFailure increments failures counter, the counter starts over when
+// previous failure is older than window.
+func (repo *LockoutRepo) RegisterFailure(
+	ctx context.Context,
+	uuid string,
+	at time.Time,
+	window time.Duration,
+) (lockoutDto.Lockout, error) {
+	at = at.UTC()
+	stale := bson.M{"$lt": bson.A{
+		bson.M{"$ifNull": bson.A{"$last_failure_at", time.Time{}}},
+		at.Add(-window),
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"failures": bson.M{"$cond": bson.A{
+				stale,
+				1,
+				bson.M{"$add": bson.A{"$failures", 1}},
+			}},
+			"last_failure_at": at,
+		}}},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	lockoutModel := lockoutModel.Lockout{}
+	filter := scoped(ctx, bson.M{"uuid": uuid})
+	if err := repo.collection.FindOneAndUpdate(ctx, filter, pipeline, opts).Decode(&lockoutModel); err != nil {
+		return lockoutDto.Lockout{}, fmt.Errorf("LockoutRepo - RegisterFailure - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToLockout(lockoutModel), nil
+}
+
+func (repo *LockoutRepo) Lock(ctx context.Context, uuid string, until time.Time) error {
+	filter := scoped(ctx, bson.M{"uuid": uuid})
+	update := bson.M{"$set": bson.M{
+		"locked_until": until.UTC(),
+		"failures":     0,
+	}}
+
+	if _, err := repo.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("LockoutRepo - Lock - UpdateOne: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *LockoutRepo) DeleteLockout(ctx context.Context, uuid string) error {
+	filter := scoped(ctx, bson.M{"uuid": uuid})
+
+	result, err := repo.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("LockoutRepo - DeleteLockout - DeleteOne: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("LockoutRepo - DeleteLockout: %w", lockoutDto.ErrLockoutNotFound)
+	}
+
+	return nil
+}
+
+// scoped limits filter to tenant of ctx, upserts stamp the tenant from it. User
+// uuids are unique across tenants, tenant only keeps lockouts of one tenant out
+// of reach of admins of another.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	filter["tenant_id"] = tenantUtil.FromContext(ctx)
+
+	return filter
+}
@@ -0,0 +1,22 @@
+package outbox
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Message struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	Tenant        string             `bson:"tenant_id"`
+	EventID       string             `bson:"event_id"`
+	EventType     string             `bson:"event_type"`
+	Key           string             `bson:"key"`
+	Payload       string             `bson:"payload"`
+	Attempts      int                `bson:"attempts"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at"`
+	LockedUntil   time.Time          `bson:"locked_until,omitempty"`
+	DeliveredAt   *time.Time         `bson:"delivered_at,omitempty"`
+	LastError     string             `bson:"last_error,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at"`
+}
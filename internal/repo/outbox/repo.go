@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	mapper "github.com/elusiv0/medods_test/internal/mapper/outbox"
+	outboxDto "github.com/elusiv0/medods_test/internal/model/outbox"
+	"github.com/elusiv0/medods_test/internal/repo"
+	outboxModel "github.com/elusiv0/medods_test/internal/repo/outbox/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OutboxRepo struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+const (
+	collectionName = "outbox"
+)
+
+var _ repo.OutboxRepo = (*OutboxRepo)(nil)
+
+func New(
+	client *mongoClient.MongoClient,
+	log *slog.Logger,
+) *OutboxRepo {
+	collection := client.MongoDatabase.Collection(collectionName)
+
+	return &OutboxRepo{
+		collection: collection,
+		logger:     log,
+	}
+}
+
+// InsertMessage must be called with ctx of the transaction changing the state
+// message describes, so that both are committed or rolled back together.
+// Message is stamped with tenant of ctx.
+func (repo *OutboxRepo) InsertMessage(ctx context.Context, message outboxDto.Message) (string, error) {
+	message.Tenant = tenantUtil.FromContext(ctx)
+	result, err := repo.collection.InsertOne(ctx, mapper.MessageToModel(message))
+	if err != nil {
+		return "", fmt.Errorf("OutboxRepo - InsertMessage - InsertOne: %w", err)
+	}
+
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+// ClaimMessage leases the oldest due message of any tenant, so that relays of
+// several replicas do not publish it at once.
+func (repo *OutboxRepo) ClaimMessage(ctx context.Context, now time.Time, lease time.Duration) (outboxDto.Message, error) {
+	now = now.UTC()
+	filter := bson.M{
+		"delivered_at":    bson.M{"$exists": false},
+		"next_attempt_at": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"locked_until": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	messageModel := outboxModel.Message{}
+	if err := repo.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&messageModel); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = outboxDto.ErrMessageNotFound
+		}
+		return outboxDto.Message{}, fmt.Errorf("OutboxRepo - ClaimMessage - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToMessage(messageModel), nil
+}
+
+func (repo *OutboxRepo) MarkDelivered(ctx context.Context, id string, at time.Time) error {
+	update := bson.M{
+		"$set":   bson.M{"delivered_at": at.UTC()},
+		"$unset": bson.M{"locked_until": "", "last_error": ""},
+		"$inc":   bson.M{"attempts": 1},
+	}
+
+	if err := repo.update(ctx, id, update); err != nil {
+		return fmt.Errorf("OutboxRepo - MarkDelivered: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed releases the lease and postpones next publish attempt.
+func (repo *OutboxRepo) MarkFailed(ctx context.Context, id string, nextAttemptAt time.Time, lastError string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"next_attempt_at": nextAttemptAt.UTC(),
+			"last_error":      lastError,
+		},
+		"$unset": bson.M{"locked_until": ""},
+		"$inc":   bson.M{"attempts": 1},
+	}
+
+	if err := repo.update(ctx, id, update); err != nil {
+		return fmt.Errorf("OutboxRepo - MarkFailed: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *OutboxRepo) update(ctx context.Context, id string, update bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return outboxDto.ErrMessageNotFound
+	}
+
+	result, err := repo.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return fmt.Errorf("UpdateOne: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return outboxDto.ErrMessageNotFound
+	}
+
+	return nil
+}
@@ -2,19 +2,120 @@ package repo
 
 import (
 	"context"
+	"time"
 
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	keyDto "github.com/elusiv0/medods_test/internal/model/key"
+	lockoutDto "github.com/elusiv0/medods_test/internal/model/lockout"
+	outboxDto "github.com/elusiv0/medods_test/internal/model/outbox"
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
 	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	webhookDto "github.com/elusiv0/medods_test/internal/model/webhook"
 	tokenModel "github.com/elusiv0/medods_test/internal/repo/token/model"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type UserRepo interface {
 	GetUserByUUID(ctx context.Context, uuid string) (userDto.User, error)
 	InsertUser(ctx context.Context, user userDto.CreateUser) (string, error)
+	UpsertUser(ctx context.Context, user userDto.User) (bool, bool, error)
+	UpdateUser(ctx context.Context, uuid string, update userDto.UpdateUser) (userDto.User, error)
+	ListUsers(ctx context.Context, filter userDto.Filter) ([]userDto.User, error)
+	SetDisabled(ctx context.Context, uuid string, disabled bool) error
+	SetRoles(ctx context.Context, uuid string, roles []string) (userDto.User, error)
+	UnassignRole(ctx context.Context, role string) (int64, error)
+	DeleteUser(ctx context.Context, uuid string) error
+}
+
+type RoleRepo interface {
+	InsertRole(ctx context.Context, role roleDto.Role) error
+	UpsertRole(ctx context.Context, role roleDto.Role) (bool, bool, error)
+	GetRole(ctx context.Context, name string) (roleDto.Role, error)
+	ListRoles(ctx context.Context) ([]roleDto.Role, error)
+	UpdateRole(ctx context.Context, name string, update roleDto.UpdateRole) (roleDto.Role, error)
+	DeleteRole(ctx context.Context, name string) error
+}
+
+type GroupRepo interface {
+	InsertGroup(ctx context.Context, group groupDto.Group) error
+	GetGroup(ctx context.Context, id string) (groupDto.Group, error)
+	ListGroups(ctx context.Context) ([]groupDto.Group, error)
+	ListUserGroups(ctx context.Context, uuid string) ([]groupDto.Group, error)
+	GetGroups(ctx context.Context, ids []string) ([]groupDto.Group, error)
+	UpdateGroup(ctx context.Context, id string, update groupDto.UpdateGroup) (groupDto.Group, error)
+	DeleteGroup(ctx context.Context, id string) error
+	RemoveParent(ctx context.Context, id string) (int64, error)
+	AddMember(ctx context.Context, id string, uuid string) (groupDto.Group, error)
+	RemoveMember(ctx context.Context, id string, uuid string) (groupDto.Group, error)
+	RemoveUser(ctx context.Context, uuid string) (int64, error)
+	UnassignRole(ctx context.Context, role string) (int64, error)
+}
+
+type TenantRepo interface {
+	InsertTenant(ctx context.Context, tenant tenantDto.Tenant) error
+	UpsertTenant(ctx context.Context, tenant tenantDto.Tenant) (bool, bool, error)
+	GetTenant(ctx context.Context, id string) (tenantDto.Tenant, error)
+	ListTenants(ctx context.Context) ([]tenantDto.Tenant, error)
+	UpdateTenant(ctx context.Context, id string, update tenantDto.UpdateTenant) (tenantDto.Tenant, error)
 }
 
 type TokenRepo interface {
-	GetTokenInfo(ctx context.Context, token string) (tokenModel.Token, error)
-	InsertToken(ctx context.Context, token, uuid string) (primitive.ObjectID, error)
+	GetToken(ctx context.Context, id primitive.ObjectID) (tokenModel.Token, error)
+	ListUserTokens(ctx context.Context, uuid string) ([]tokenModel.Token, error)
+	InsertToken(ctx context.Context, token tokenModel.Token) error
+	RotateToken(ctx context.Context, id primitive.ObjectID, token tokenModel.Token) error
 	DeleteToken(ctx context.Context, id primitive.ObjectID) error
+	DeleteUserTokens(ctx context.Context, uuid string) (int64, error)
+}
+
+type LockoutRepo interface {
+	GetLockout(ctx context.Context, uuid string) (lockoutDto.Lockout, error)
+	RegisterFailure(ctx context.Context, uuid string, at time.Time, window time.Duration) (lockoutDto.Lockout, error)
+	Lock(ctx context.Context, uuid string, until time.Time) error
+	DeleteLockout(ctx context.Context, uuid string) error
+}
+
+type AuditRepo interface {
+	InsertEntry(ctx context.Context, entry auditDto.Entry) (auditDto.Entry, error)
+	ListEntries(ctx context.Context, filter auditDto.Filter) ([]auditDto.Entry, error)
+	ExportEntries(ctx context.Context, filter auditDto.Filter, fn func(auditDto.Entry) error) error
+	WalkChain(ctx context.Context, fn func(auditDto.Entry) error) error
+	InsertCheckpoint(ctx context.Context, checkpoint auditDto.Checkpoint) error
+	ListCheckpoints(ctx context.Context) ([]auditDto.Checkpoint, error)
+	SetHead(ctx context.Context, head auditDto.Checkpoint) error
+	GetHead(ctx context.Context) (auditDto.Checkpoint, error)
+}
+
+type WebhookRepo interface {
+	InsertSubscription(ctx context.Context, subscription webhookDto.Subscription) (string, error)
+	GetSubscription(ctx context.Context, id string) (webhookDto.Subscription, error)
+	ListSubscriptions(ctx context.Context) ([]webhookDto.Subscription, error)
+	ListActiveSubscriptions(ctx context.Context, eventType string) ([]webhookDto.Subscription, error)
+	UpdateSubscription(ctx context.Context, id string, update webhookDto.UpdateSubscription) (webhookDto.Subscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	InsertDeliveries(ctx context.Context, deliveries []webhookDto.Delivery) error
+	GetDelivery(ctx context.Context, id string) (webhookDto.Delivery, error)
+	ListDeliveries(ctx context.Context, filter webhookDto.DeliveryFilter) ([]webhookDto.Delivery, error)
+	ClaimDelivery(ctx context.Context, now time.Time, lease time.Duration) (webhookDto.Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery webhookDto.Delivery) error
+	RequeueDelivery(ctx context.Context, id string, at time.Time) (webhookDto.Delivery, error)
+}
+
+type OutboxRepo interface {
+	InsertMessage(ctx context.Context, message outboxDto.Message) (string, error)
+	ClaimMessage(ctx context.Context, now time.Time, lease time.Duration) (outboxDto.Message, error)
+	MarkDelivered(ctx context.Context, id string, at time.Time) error
+	MarkFailed(ctx context.Context, id string, nextAttemptAt time.Time, lastError string) error
+}
+
+type KeyRepo interface {
+	InsertKey(ctx context.Context, key keyDto.Key) error
+	ListKeys(ctx context.Context) ([]keyDto.Key, error)
+	ActivateKey(ctx context.Context, id string, at time.Time) error
 }
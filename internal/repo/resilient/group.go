@@ -0,0 +1,151 @@
+package resilient
+
+import (
+	"context"
+
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	"github.com/elusiv0/medods_test/internal/repo"
+	"github.com/elusiv0/medods_test/pkg/resilience"
+)
+
+// GroupRepo runs calls of wrapped repo under executor, InsertGroup is made once
+// as a retry of an applied insert would report the group as existing.
+type GroupRepo struct {
+	next     repo.GroupRepo
+	executor *resilience.Executor
+}
+
+var _ repo.GroupRepo = (*GroupRepo)(nil)
+
+func NewGroupRepo(next repo.GroupRepo, executor *resilience.Executor) *GroupRepo {
+	return &GroupRepo{
+		next:     next,
+		executor: executor,
+	}
+}
+
+func (groupRepo *GroupRepo) InsertGroup(ctx context.Context, group groupDto.Group) error {
+	err := groupRepo.executor.Do(ctx, "GroupRepo.InsertGroup", false, func(ctx context.Context) error {
+		return groupRepo.next.InsertGroup(ctx, group)
+	})
+
+	return wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) GetGroup(ctx context.Context, id string) (groupDto.Group, error) {
+	var group groupDto.Group
+	err := groupRepo.executor.Do(ctx, "GroupRepo.GetGroup", retry(ctx), func(ctx context.Context) error {
+		var err error
+		group, err = groupRepo.next.GetGroup(ctx, id)
+		return err
+	})
+
+	return group, wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) ListGroups(ctx context.Context) ([]groupDto.Group, error) {
+	var groups []groupDto.Group
+	err := groupRepo.executor.Do(ctx, "GroupRepo.ListGroups", retry(ctx), func(ctx context.Context) error {
+		var err error
+		groups, err = groupRepo.next.ListGroups(ctx)
+		return err
+	})
+
+	return groups, wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) ListUserGroups(ctx context.Context, uuid string) ([]groupDto.Group, error) {
+	var groups []groupDto.Group
+	err := groupRepo.executor.Do(ctx, "GroupRepo.ListUserGroups", retry(ctx), func(ctx context.Context) error {
+		var err error
+		groups, err = groupRepo.next.ListUserGroups(ctx, uuid)
+		return err
+	})
+
+	return groups, wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) GetGroups(ctx context.Context, ids []string) ([]groupDto.Group, error) {
+	var groups []groupDto.Group
+	err := groupRepo.executor.Do(ctx, "GroupRepo.GetGroups", retry(ctx), func(ctx context.Context) error {
+		var err error
+		groups, err = groupRepo.next.GetGroups(ctx, ids)
+		return err
+	})
+
+	return groups, wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) UpdateGroup(ctx context.Context, id string, update groupDto.UpdateGroup) (groupDto.Group, error) {
+	var group groupDto.Group
+	err := groupRepo.executor.Do(ctx, "GroupRepo.UpdateGroup", retry(ctx), func(ctx context.Context) error {
+		var err error
+		group, err = groupRepo.next.UpdateGroup(ctx, id, update)
+		return err
+	})
+
+	return group, wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) DeleteGroup(ctx context.Context, id string) error {
+	err := groupRepo.executor.Do(ctx, "GroupRepo.DeleteGroup", retry(ctx), func(ctx context.Context) error {
+		return groupRepo.next.DeleteGroup(ctx, id)
+	})
+
+	return wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) RemoveParent(ctx context.Context, id string) (int64, error) {
+	var removed int64
+	err := groupRepo.executor.Do(ctx, "GroupRepo.RemoveParent", retry(ctx), func(ctx context.Context) error {
+		var err error
+		removed, err = groupRepo.next.RemoveParent(ctx, id)
+		return err
+	})
+
+	return removed, wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) AddMember(ctx context.Context, id string, uuid string) (groupDto.Group, error) {
+	var group groupDto.Group
+	err := groupRepo.executor.Do(ctx, "GroupRepo.AddMember", retry(ctx), func(ctx context.Context) error {
+		var err error
+		group, err = groupRepo.next.AddMember(ctx, id, uuid)
+		return err
+	})
+
+	return group, wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) RemoveMember(ctx context.Context, id string, uuid string) (groupDto.Group, error) {
+	var group groupDto.Group
+	err := groupRepo.executor.Do(ctx, "GroupRepo.RemoveMember", retry(ctx), func(ctx context.Context) error {
+		var err error
+		group, err = groupRepo.next.RemoveMember(ctx, id, uuid)
+		return err
+	})
+
+	return group, wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) RemoveUser(ctx context.Context, uuid string) (int64, error) {
+	var removed int64
+	err := groupRepo.executor.Do(ctx, "GroupRepo.RemoveUser", retry(ctx), func(ctx context.Context) error {
+		var err error
+		removed, err = groupRepo.next.RemoveUser(ctx, uuid)
+		return err
+	})
+
+	return removed, wrapErr(err)
+}
+
+func (groupRepo *GroupRepo) UnassignRole(ctx context.Context, role string) (int64, error) {
+	var unassigned int64
+	err := groupRepo.executor.Do(ctx, "GroupRepo.UnassignRole", retry(ctx), func(ctx context.Context) error {
+		var err error
+		unassigned, err = groupRepo.next.UnassignRole(ctx, role)
+		return err
+	})
+
+	return unassigned, wrapErr(err)
+}
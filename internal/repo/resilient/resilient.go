@@ -0,0 +1,30 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	"github.com/elusiv0/medods_test/pkg/resilience"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// retry reports whether an idempotent call may be repeated. Calls of a
+// transaction are not, the driver retries the transaction as a whole.
+func retry(ctx context.Context) bool {
+	return mongo.SessionFromContext(ctx) == nil
+}
+
+// wrapErr reports calls rejected by open breaker as service unavailable with a
+// hint when to retry.
+func wrapErr(err error) error {
+	var openErr *resilience.OpenError
+	if errors.As(err, &openErr) {
+		return &api.RetryError{
+			Err:        api.ErrServiceUnavailable,
+			RetryAfter: openErr.RetryAfter,
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,85 @@
+package resilient
+
+import (
+	"context"
+
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	"github.com/elusiv0/medods_test/internal/repo"
+	"github.com/elusiv0/medods_test/pkg/resilience"
+)
+
+// RoleRepo runs calls of wrapped repo under executor, InsertRole is made once
+// as a retry of an applied insert would report the role as existing.
+type RoleRepo struct {
+	next     repo.RoleRepo
+	executor *resilience.Executor
+}
+
+var _ repo.RoleRepo = (*RoleRepo)(nil)
+
+func NewRoleRepo(next repo.RoleRepo, executor *resilience.Executor) *RoleRepo {
+	return &RoleRepo{
+		next:     next,
+		executor: executor,
+	}
+}
+
+func (roleRepo *RoleRepo) InsertRole(ctx context.Context, role roleDto.Role) error {
+	err := roleRepo.executor.Do(ctx, "RoleRepo.InsertRole", false, func(ctx context.Context) error {
+		return roleRepo.next.InsertRole(ctx, role)
+	})
+
+	return wrapErr(err)
+}
+
+func (roleRepo *RoleRepo) UpsertRole(ctx context.Context, role roleDto.Role) (bool, bool, error) {
+	var created, updated bool
+	err := roleRepo.executor.Do(ctx, "RoleRepo.UpsertRole", retry(ctx), func(ctx context.Context) error {
+		var err error
+		created, updated, err = roleRepo.next.UpsertRole(ctx, role)
+		return err
+	})
+
+	return created, updated, wrapErr(err)
+}
+
+func (roleRepo *RoleRepo) GetRole(ctx context.Context, name string) (roleDto.Role, error) {
+	var role roleDto.Role
+	err := roleRepo.executor.Do(ctx, "RoleRepo.GetRole", retry(ctx), func(ctx context.Context) error {
+		var err error
+		role, err = roleRepo.next.GetRole(ctx, name)
+		return err
+	})
+
+	return role, wrapErr(err)
+}
+
+func (roleRepo *RoleRepo) ListRoles(ctx context.Context) ([]roleDto.Role, error) {
+	var roles []roleDto.Role
+	err := roleRepo.executor.Do(ctx, "RoleRepo.ListRoles", retry(ctx), func(ctx context.Context) error {
+		var err error
+		roles, err = roleRepo.next.ListRoles(ctx)
+		return err
+	})
+
+	return roles, wrapErr(err)
+}
+
+func (roleRepo *RoleRepo) UpdateRole(ctx context.Context, name string, update roleDto.UpdateRole) (roleDto.Role, error) {
+	var role roleDto.Role
+	err := roleRepo.executor.Do(ctx, "RoleRepo.UpdateRole", retry(ctx), func(ctx context.Context) error {
+		var err error
+		role, err = roleRepo.next.UpdateRole(ctx, name, update)
+		return err
+	})
+
+	return role, wrapErr(err)
+}
+
+func (roleRepo *RoleRepo) DeleteRole(ctx context.Context, name string) error {
+	err := roleRepo.executor.Do(ctx, "RoleRepo.DeleteRole", retry(ctx), func(ctx context.Context) error {
+		return roleRepo.next.DeleteRole(ctx, name)
+	})
+
+	return wrapErr(err)
+}
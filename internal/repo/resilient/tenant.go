@@ -0,0 +1,77 @@
+package resilient
+
+import (
+	"context"
+
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
+	"github.com/elusiv0/medods_test/internal/repo"
+	"github.com/elusiv0/medods_test/pkg/resilience"
+)
+
+// TenantRepo runs calls of wrapped repo under executor, InsertTenant is made
+// once as a retry of an applied insert would report the tenant as existing.
+type TenantRepo struct {
+	next     repo.TenantRepo
+	executor *resilience.Executor
+}
+
+var _ repo.TenantRepo = (*TenantRepo)(nil)
+
+func NewTenantRepo(next repo.TenantRepo, executor *resilience.Executor) *TenantRepo {
+	return &TenantRepo{
+		next:     next,
+		executor: executor,
+	}
+}
+
+func (tenantRepo *TenantRepo) InsertTenant(ctx context.Context, tenant tenantDto.Tenant) error {
+	err := tenantRepo.executor.Do(ctx, "TenantRepo.InsertTenant", false, func(ctx context.Context) error {
+		return tenantRepo.next.InsertTenant(ctx, tenant)
+	})
+
+	return wrapErr(err)
+}
+
+func (tenantRepo *TenantRepo) UpsertTenant(ctx context.Context, tenant tenantDto.Tenant) (bool, bool, error) {
+	var created, updated bool
+	err := tenantRepo.executor.Do(ctx, "TenantRepo.UpsertTenant", retry(ctx), func(ctx context.Context) error {
+		var err error
+		created, updated, err = tenantRepo.next.UpsertTenant(ctx, tenant)
+		return err
+	})
+
+	return created, updated, wrapErr(err)
+}
+
+func (tenantRepo *TenantRepo) GetTenant(ctx context.Context, id string) (tenantDto.Tenant, error) {
+	var tenant tenantDto.Tenant
+	err := tenantRepo.executor.Do(ctx, "TenantRepo.GetTenant", retry(ctx), func(ctx context.Context) error {
+		var err error
+		tenant, err = tenantRepo.next.GetTenant(ctx, id)
+		return err
+	})
+
+	return tenant, wrapErr(err)
+}
+
+func (tenantRepo *TenantRepo) ListTenants(ctx context.Context) ([]tenantDto.Tenant, error) {
+	var tenants []tenantDto.Tenant
+	err := tenantRepo.executor.Do(ctx, "TenantRepo.ListTenants", retry(ctx), func(ctx context.Context) error {
+		var err error
+		tenants, err = tenantRepo.next.ListTenants(ctx)
+		return err
+	})
+
+	return tenants, wrapErr(err)
+}
+
+func (tenantRepo *TenantRepo) UpdateTenant(ctx context.Context, id string, update tenantDto.UpdateTenant) (tenantDto.Tenant, error) {
+	var tenant tenantDto.Tenant
+	err := tenantRepo.executor.Do(ctx, "TenantRepo.UpdateTenant", retry(ctx), func(ctx context.Context) error {
+		var err error
+		tenant, err = tenantRepo.next.UpdateTenant(ctx, id, update)
+		return err
+	})
+
+	return tenant, wrapErr(err)
+}
@@ -0,0 +1,84 @@
+package resilient
+
+import (
+	"context"
+
+	"github.com/elusiv0/medods_test/internal/repo"
+	tokenModel "github.com/elusiv0/medods_test/internal/repo/token/model"
+	"github.com/elusiv0/medods_test/pkg/resilience"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokenRepo runs calls of wrapped repo under executor. InsertToken and
+// RotateToken are made once: a retry after a write that reached the server
+// fails on the token it consumed or inserted itself.
+type TokenRepo struct {
+	next     repo.TokenRepo
+	executor *resilience.Executor
+}
+
+var _ repo.TokenRepo = (*TokenRepo)(nil)
+
+func NewTokenRepo(next repo.TokenRepo, executor *resilience.Executor) *TokenRepo {
+	return &TokenRepo{
+		next:     next,
+		executor: executor,
+	}
+}
+
+func (tokenRepo *TokenRepo) GetToken(ctx context.Context, id primitive.ObjectID) (tokenModel.Token, error) {
+	var token tokenModel.Token
+	err := tokenRepo.executor.Do(ctx, "TokenRepo.GetToken", retry(ctx), func(ctx context.Context) error {
+		var err error
+		token, err = tokenRepo.next.GetToken(ctx, id)
+		return err
+	})
+
+	return token, wrapErr(err)
+}
+
+func (tokenRepo *TokenRepo) ListUserTokens(ctx context.Context, uuid string) ([]tokenModel.Token, error) {
+	var tokens []tokenModel.Token
+	err := tokenRepo.executor.Do(ctx, "TokenRepo.ListUserTokens", retry(ctx), func(ctx context.Context) error {
+		var err error
+		tokens, err = tokenRepo.next.ListUserTokens(ctx, uuid)
+		return err
+	})
+
+	return tokens, wrapErr(err)
+}
+
+func (tokenRepo *TokenRepo) InsertToken(ctx context.Context, token tokenModel.Token) error {
+	err := tokenRepo.executor.Do(ctx, "TokenRepo.InsertToken", false, func(ctx context.Context) error {
+		return tokenRepo.next.InsertToken(ctx, token)
+	})
+
+	return wrapErr(err)
+}
+
+func (tokenRepo *TokenRepo) RotateToken(ctx context.Context, id primitive.ObjectID, token tokenModel.Token) error {
+	err := tokenRepo.executor.Do(ctx, "TokenRepo.RotateToken", false, func(ctx context.Context) error {
+		return tokenRepo.next.RotateToken(ctx, id, token)
+	})
+
+	return wrapErr(err)
+}
+
+func (tokenRepo *TokenRepo) DeleteToken(ctx context.Context, id primitive.ObjectID) error {
+	err := tokenRepo.executor.Do(ctx, "TokenRepo.DeleteToken", retry(ctx), func(ctx context.Context) error {
+		return tokenRepo.next.DeleteToken(ctx, id)
+	})
+
+	return wrapErr(err)
+}
+
+func (tokenRepo *TokenRepo) DeleteUserTokens(ctx context.Context, uuid string) (int64, error) {
+	var deleted int64
+	err := tokenRepo.executor.Do(ctx, "TokenRepo.DeleteUserTokens", retry(ctx), func(ctx context.Context) error {
+		var err error
+		deleted, err = tokenRepo.next.DeleteUserTokens(ctx, uuid)
+		return err
+	})
+
+	return deleted, wrapErr(err)
+}
@@ -0,0 +1,118 @@
+package resilient
+
+import (
+	"context"
+
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	"github.com/elusiv0/medods_test/internal/repo"
+	"github.com/elusiv0/medods_test/pkg/resilience"
+)
+
+// UserRepo runs calls of wrapped repo under executor, InsertUser is made once
+// as a retry could create a second user.
+type UserRepo struct {
+	next     repo.UserRepo
+	executor *resilience.Executor
+}
+
+var _ repo.UserRepo = (*UserRepo)(nil)
+
+func NewUserRepo(next repo.UserRepo, executor *resilience.Executor) *UserRepo {
+	return &UserRepo{
+		next:     next,
+		executor: executor,
+	}
+}
+
+func (userRepo *UserRepo) GetUserByUUID(ctx context.Context, uuid string) (userDto.User, error) {
+	var user userDto.User
+	err := userRepo.executor.Do(ctx, "UserRepo.GetUserByUUID", retry(ctx), func(ctx context.Context) error {
+		var err error
+		user, err = userRepo.next.GetUserByUUID(ctx, uuid)
+		return err
+	})
+
+	return user, wrapErr(err)
+}
+
+func (userRepo *UserRepo) InsertUser(ctx context.Context, user userDto.CreateUser) (string, error) {
+	var uuid string
+	err := userRepo.executor.Do(ctx, "UserRepo.InsertUser", false, func(ctx context.Context) error {
+		var err error
+		uuid, err = userRepo.next.InsertUser(ctx, user)
+		return err
+	})
+
+	return uuid, wrapErr(err)
+}
+
+func (userRepo *UserRepo) UpsertUser(ctx context.Context, user userDto.User) (bool, bool, error) {
+	var created, updated bool
+	err := userRepo.executor.Do(ctx, "UserRepo.UpsertUser", retry(ctx), func(ctx context.Context) error {
+		var err error
+		created, updated, err = userRepo.next.UpsertUser(ctx, user)
+		return err
+	})
+
+	return created, updated, wrapErr(err)
+}
+
+func (userRepo *UserRepo) UpdateUser(ctx context.Context, uuid string, update userDto.UpdateUser) (userDto.User, error) {
+	var user userDto.User
+	err := userRepo.executor.Do(ctx, "UserRepo.UpdateUser", retry(ctx), func(ctx context.Context) error {
+		var err error
+		user, err = userRepo.next.UpdateUser(ctx, uuid, update)
+		return err
+	})
+
+	return user, wrapErr(err)
+}
+
+func (userRepo *UserRepo) ListUsers(ctx context.Context, filter userDto.Filter) ([]userDto.User, error) {
+	var users []userDto.User
+	err := userRepo.executor.Do(ctx, "UserRepo.ListUsers", retry(ctx), func(ctx context.Context) error {
+		var err error
+		users, err = userRepo.next.ListUsers(ctx, filter)
+		return err
+	})
+
+	return users, wrapErr(err)
+}
+
+func (userRepo *UserRepo) SetDisabled(ctx context.Context, uuid string, disabled bool) error {
+	err := userRepo.executor.Do(ctx, "UserRepo.SetDisabled", retry(ctx), func(ctx context.Context) error {
+		return userRepo.next.SetDisabled(ctx, uuid, disabled)
+	})
+
+	return wrapErr(err)
+}
+
+func (userRepo *UserRepo) SetRoles(ctx context.Context, uuid string, roles []string) (userDto.User, error) {
+	var user userDto.User
+	err := userRepo.executor.Do(ctx, "UserRepo.SetRoles", retry(ctx), func(ctx context.Context) error {
+		var err error
+		user, err = userRepo.next.SetRoles(ctx, uuid, roles)
+		return err
+	})
+
+	return user, wrapErr(err)
+}
+
+func (userRepo *UserRepo) UnassignRole(ctx context.Context, role string) (int64, error) {
+	var unassigned int64
+	err := userRepo.executor.Do(ctx, "UserRepo.UnassignRole", retry(ctx), func(ctx context.Context) error {
+		var err error
+		unassigned, err = userRepo.next.UnassignRole(ctx, role)
+		return err
+	})
+
+	return unassigned, wrapErr(err)
+}
+
+func (userRepo *UserRepo) DeleteUser(ctx context.Context, uuid string) error {
+	err := userRepo.executor.Do(ctx, "UserRepo.DeleteUser", retry(ctx), func(ctx context.Context) error {
+		return userRepo.next.DeleteUser(ctx, uuid)
+	})
+
+	return wrapErr(err)
+}
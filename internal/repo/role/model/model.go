@@ -0,0 +1,12 @@
+package role
+
+import (
+	"time"
+)
+
+type Role struct {
+	Name        string    `bson:"_id"`
+	Description string    `bson:"description,omitempty"`
+	Permissions []string  `bson:"permissions"`
+	CreatedAt   time.Time `bson:"created_at"`
+}
@@ -0,0 +1,148 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	mapper "github.com/elusiv0/medods_test/internal/mapper/role"
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	"github.com/elusiv0/medods_test/internal/repo"
+	roleModel "github.com/elusiv0/medods_test/internal/repo/role/model"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RoleRepo struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+const (
+	collectionName = "roles"
+)
+
+var _ repo.RoleRepo = (*RoleRepo)(nil)
+
+func New(
+	client *mongoClient.MongoClient,
+	log *slog.Logger,
+) *RoleRepo {
+	return &RoleRepo{
+		collection: client.MongoDatabase.Collection(collectionName),
+		logger:     log,
+	}
+}
+
+func (repo *RoleRepo) InsertRole(ctx context.Context, role roleDto.Role) error {
+	if _, err := repo.collection.InsertOne(ctx, mapper.RoleToModel(role)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = roleDto.ErrRoleExists
+		}
+		return fmt.Errorf("RoleRepo - InsertRole - InsertOne: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertRole stores role under its name, reports whether it was created or an
+// existing one was changed. Creation time of existing role is kept.
+func (repo *RoleRepo) UpsertRole(ctx context.Context, role roleDto.Role) (bool, bool, error) {
+	model := mapper.RoleToModel(role)
+	update := bson.M{
+		"$set": bson.M{
+			"description": model.Description,
+			"permissions": model.Permissions,
+		},
+		"$setOnInsert": bson.M{"created_at": model.CreatedAt},
+	}
+
+	result, err := repo.collection.UpdateOne(ctx, bson.M{"_id": model.Name}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, false, fmt.Errorf("RoleRepo - UpsertRole - UpdateOne: %w", err)
+	}
+
+	return result.UpsertedCount > 0, result.ModifiedCount > 0, nil
+}
+
+func (repo *RoleRepo) GetRole(ctx context.Context, name string) (roleDto.Role, error) {
+	model := roleModel.Role{}
+	if err := repo.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&model); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = roleDto.ErrRoleNotFound
+		}
+		return roleDto.Role{}, fmt.Errorf("RoleRepo - GetRole - FindOne: %w", err)
+	}
+
+	return mapper.ModelToRole(model), nil
+}
+
+func (repo *RoleRepo) ListRoles(ctx context.Context) ([]roleDto.Role, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := repo.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("RoleRepo - ListRoles - Find: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	roles := make([]roleDto.Role, 0)
+	for cursor.Next(ctx) {
+		model := roleModel.Role{}
+		if err := cursor.Decode(&model); err != nil {
+			return nil, fmt.Errorf("RoleRepo - ListRoles - Decode: %w", err)
+		}
+		roles = append(roles, mapper.ModelToRole(model))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("RoleRepo - ListRoles - Cursor: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (repo *RoleRepo) UpdateRole(ctx context.Context, name string, update roleDto.UpdateRole) (roleDto.Role, error) {
+	set := bson.M{}
+	if update.Description != nil {
+		set["description"] = *update.Description
+	}
+	if update.Permissions != nil {
+		permissions := *update.Permissions
+		if permissions == nil {
+			permissions = []string{}
+		}
+		set["permissions"] = permissions
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	model := roleModel.Role{}
+
+	var result *mongo.SingleResult
+	if len(set) == 0 {
+		result = repo.collection.FindOne(ctx, bson.M{"_id": name})
+	} else {
+		result = repo.collection.FindOneAndUpdate(ctx, bson.M{"_id": name}, bson.M{"$set": set}, opts)
+	}
+	if err := result.Decode(&model); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = roleDto.ErrRoleNotFound
+		}
+		return roleDto.Role{}, fmt.Errorf("RoleRepo - UpdateRole - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToRole(model), nil
+}
+
+func (repo *RoleRepo) DeleteRole(ctx context.Context, name string) error {
+	result, err := repo.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return fmt.Errorf("RoleRepo - DeleteRole - DeleteOne: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("RoleRepo - DeleteRole: %w", roleDto.ErrRoleNotFound)
+	}
+
+	return nil
+}
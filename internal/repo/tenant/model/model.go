@@ -0,0 +1,22 @@
+package tenant
+
+import (
+	"time"
+)
+
+type Tenant struct {
+	ID        string    `bson:"_id"`
+	Name      string    `bson:"name"`
+	Hosts     []string  `bson:"hosts"`
+	Disabled  bool      `bson:"disabled"`
+	Policy    Policy    `bson:"policy"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+type Policy struct {
+	AccessLifeTime  time.Duration `bson:"access_lifetime,omitempty"`
+	RefreshLifeTime time.Duration `bson:"refresh_lifetime,omitempty"`
+	SessionMaxAge   time.Duration `bson:"session_max_age,omitempty"`
+	Clients         []string      `bson:"clients,omitempty"`
+	DefaultScopes   []string      `bson:"default_scopes,omitempty"`
+}
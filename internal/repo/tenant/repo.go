@@ -0,0 +1,144 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	mapper "github.com/elusiv0/medods_test/internal/mapper/tenant"
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
+	"github.com/elusiv0/medods_test/internal/repo"
+	tenantModel "github.com/elusiv0/medods_test/internal/repo/tenant/model"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TenantRepo struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+const (
+	collectionName = "tenants"
+)
+
+var _ repo.TenantRepo = (*TenantRepo)(nil)
+
+func New(
+	client *mongoClient.MongoClient,
+	log *slog.Logger,
+) *TenantRepo {
+	return &TenantRepo{
+		collection: client.MongoDatabase.Collection(collectionName),
+		logger:     log,
+	}
+}
+
+func (repo *TenantRepo) InsertTenant(ctx context.Context, tenant tenantDto.Tenant) error {
+	if _, err := repo.collection.InsertOne(ctx, mapper.TenantToModel(tenant)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = tenantDto.ErrTenantExists
+		}
+		return fmt.Errorf("TenantRepo - InsertTenant - InsertOne: %w", err)
+	}
+
+	return nil
+}
+
+// UpsertTenant stores tenant under its id, reports whether it was created or an
+// existing one was changed. Creation time of existing tenant is kept.
+func (repo *TenantRepo) UpsertTenant(ctx context.Context, tenant tenantDto.Tenant) (bool, bool, error) {
+	model := mapper.TenantToModel(tenant)
+	update := bson.M{
+		"$set": bson.M{
+			"name":     model.Name,
+			"hosts":    model.Hosts,
+			"disabled": model.Disabled,
+			"policy":   model.Policy,
+		},
+		"$setOnInsert": bson.M{"created_at": model.CreatedAt},
+	}
+
+	result, err := repo.collection.UpdateOne(ctx, bson.M{"_id": model.ID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, false, fmt.Errorf("TenantRepo - UpsertTenant - UpdateOne: %w", err)
+	}
+
+	return result.UpsertedCount > 0, result.ModifiedCount > 0, nil
+}
+
+func (repo *TenantRepo) GetTenant(ctx context.Context, id string) (tenantDto.Tenant, error) {
+	model := tenantModel.Tenant{}
+	if err := repo.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&model); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = tenantDto.ErrTenantNotFound
+		}
+		return tenantDto.Tenant{}, fmt.Errorf("TenantRepo - GetTenant - FindOne: %w", err)
+	}
+
+	return mapper.ModelToTenant(model), nil
+}
+
+func (repo *TenantRepo) ListTenants(ctx context.Context) ([]tenantDto.Tenant, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := repo.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("TenantRepo - ListTenants - Find: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	tenants := make([]tenantDto.Tenant, 0)
+	for cursor.Next(ctx) {
+		model := tenantModel.Tenant{}
+		if err := cursor.Decode(&model); err != nil {
+			return nil, fmt.Errorf("TenantRepo - ListTenants - Decode: %w", err)
+		}
+		tenants = append(tenants, mapper.ModelToTenant(model))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("TenantRepo - ListTenants - Cursor: %w", err)
+	}
+
+	return tenants, nil
+}
+
+func (repo *TenantRepo) UpdateTenant(ctx context.Context, id string, update tenantDto.UpdateTenant) (tenantDto.Tenant, error) {
+	set := bson.M{}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+	if update.Hosts != nil {
+		hosts := *update.Hosts
+		if hosts == nil {
+			hosts = []string{}
+		}
+		set["hosts"] = hosts
+	}
+	if update.Disabled != nil {
+		set["disabled"] = *update.Disabled
+	}
+	if update.Policy != nil {
+		set["policy"] = mapper.PolicyToModel(*update.Policy)
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	model := tenantModel.Tenant{}
+
+	var result *mongo.SingleResult
+	if len(set) == 0 {
+		result = repo.collection.FindOne(ctx, bson.M{"_id": id})
+	} else {
+		result = repo.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": set}, opts)
+	}
+	if err := result.Decode(&model); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = tenantDto.ErrTenantNotFound
+		}
+		return tenantDto.Tenant{}, fmt.Errorf("TenantRepo - UpdateTenant - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToTenant(model), nil
+}
@@ -1,6 +1,18 @@
 package token
 
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
 type Token struct {
-	Token    string `bson:"token"`
-	UserUUID string `bson:"user_uuid`
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	Digest           string             `bson:"digest"`
+	UserUUID         string             `bson:"user_uuid"`
+	Tenant           string             `bson:"tenant_id"`
+	ClientID         string             `bson:"client_id,omitempty"`
+	Scope            []string           `bson:"scope"`
+	SessionStartedAt time.Time          `bson:"session_started_at"`
+	ExpiresAt        time.Time          `bson:"expires_at"`
 }
@@ -9,13 +9,16 @@ import (
 	tokenDto "github.com/elusiv0/medods_test/internal/model/token"
 	"github.com/elusiv0/medods_test/internal/repo"
 	tokenModel "github.com/elusiv0/medods_test/internal/repo/token/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
 	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type TokenRepo struct {
+	client     *mongoClient.MongoClient
 	collection *mongo.Collection
 	logger     *slog.Logger
 }
@@ -33,42 +36,93 @@ func New(
 	collection := client.MongoDatabase.Collection(collectionName)
 
 	return &TokenRepo{
+		client:     client,
 		collection: collection,
 		logger:     log,
 	}
 }
 
-func (repo *TokenRepo) GetTokenInfo(ctx context.Context, token string) (tokenModel.Token, error) {
+func (repo *TokenRepo) GetToken(ctx context.Context, id primitive.ObjectID) (tokenModel.Token, error) {
 	tokenModel := tokenModel.Token{}
 
-	filter := bson.M{"token": token}
+	filter := scoped(ctx, bson.M{"_id": id})
 
 	if err := repo.collection.FindOne(ctx, filter).Decode(&tokenModel); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			err = tokenDto.ErrRefreshTokenNotRegistered
 		}
-		return tokenModel, fmt.Errorf("TokenRepo - GetTokenInfo - FindOne: %w", err)
+		return tokenModel, fmt.Errorf("TokenRepo - GetToken - FindOne: %w", err)
 	}
 
 	return tokenModel, nil
 }
 
-func (repo *TokenRepo) InsertToken(ctx context.Context, token, uuid string) (primitive.ObjectID, error) {
-	tokenModel := tokenModel.Token{
-		Token:    token,
-		UserUUID: uuid,
+// ListUserTokens returns refresh tokens of user, each of them is a session.
+func (repo *TokenRepo) ListUserTokens(ctx context.Context, uuid string) ([]tokenModel.Token, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "session_started_at", Value: 1}})
+
+	cursor, err := repo.collection.Find(ctx, scoped(ctx, bson.M{"user_uuid": uuid}), opts)
+	if err != nil {
+		return nil, fmt.Errorf("TokenRepository - ListUserTokens - Find: %w", err)
+	}
+
+	tokens := make([]tokenModel.Token, 0)
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, fmt.Errorf("TokenRepository - ListUserTokens - All: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (repo *TokenRepo) InsertToken(ctx context.Context, token tokenModel.Token) error {
+	token.Tenant = tenantUtil.FromContext(ctx)
+	if _, err := repo.collection.InsertOne(ctx, token); err != nil {
+		return fmt.Errorf("TokenRepository - InsertToken: %w", err)
 	}
 
-	result, err := repo.collection.InsertOne(ctx, tokenModel)
+	return nil
+}
+
+// RotateToken consumes refresh token id of token owner and stores token in its place.
+// Either both happen or nothing changes, of concurrent rotations of the same token
+// only one succeeds, the rest get ErrRefreshTokenNotRegistered.
+func (repo *TokenRepo) RotateToken(ctx context.Context, id primitive.ObjectID, token tokenModel.Token) error {
+	token.Tenant = tenantUtil.FromContext(ctx)
+	err := repo.client.WithTransaction(ctx, func(ctx context.Context) error {
+		consumed := tokenModel.Token{}
+		filter := scoped(ctx, bson.M{"_id": id, "user_uuid": token.UserUUID})
+		if err := repo.collection.FindOneAndDelete(ctx, filter).Decode(&consumed); err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return tokenDto.ErrRefreshTokenNotRegistered
+			}
+			return fmt.Errorf("FindOneAndDelete: %w", err)
+		}
+
+		if _, err := repo.collection.InsertOne(ctx, token); err != nil {
+			if !repo.client.TransactionsSupported() {
+				repo.restore(ctx, consumed)
+			}
+			return fmt.Errorf("InsertOne: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return primitive.ObjectID{}, fmt.Errorf("TokenRepository - InsertToken: %w", err)
+		return fmt.Errorf("TokenRepository - RotateToken: %w", err)
 	}
 
-	return result.InsertedID.(primitive.ObjectID), nil
+	return nil
+}
+
+// restore puts consumed token back when rotation can not be rolled back by transaction.
+func (repo *TokenRepo) restore(ctx context.Context, consumed tokenModel.Token) {
+	if _, err := repo.collection.InsertOne(ctx, consumed); err != nil {
+		repo.logger.Error("TokenRepository - restore: " + err.Error())
+	}
 }
 
 func (repo *TokenRepo) DeleteToken(ctx context.Context, id primitive.ObjectID) error {
-	filter := bson.M{"_id": id}
+	filter := scoped(ctx, bson.M{"_id": id})
 
 	result, err := repo.collection.DeleteOne(ctx, filter)
 	if err != nil {
@@ -80,3 +134,21 @@ func (repo *TokenRepo) DeleteToken(ctx context.Context, id primitive.ObjectID) e
 
 	return nil
 }
+
+func (repo *TokenRepo) DeleteUserTokens(ctx context.Context, uuid string) (int64, error) {
+	filter := scoped(ctx, bson.M{"user_uuid": uuid})
+
+	result, err := repo.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("TokenRepository - DeleteUserTokens: %w", err)
+	}
+
+	return result.DeletedCount, nil
+}
+
+// scoped restricts filter to tokens of tenant of ctx.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	filter["tenant_id"] = tenantUtil.FromContext(ctx)
+
+	return filter
+}
@@ -1,6 +1,9 @@
 package user
 
 type User struct {
-	UUID string `bson:"_id"`
-	Name string `bson:"name"`
+	UUID     string   `bson:"_id"`
+	Tenant   string   `bson:"tenant_id"`
+	Name     string   `bson:"name"`
+	Disabled bool     `bson:"disabled"`
+	Roles    []string `bson:"roles,omitempty"`
 }
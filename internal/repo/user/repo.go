@@ -5,15 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
 
 	mapper "github.com/elusiv0/medods_test/internal/mapper/user"
 	userDto "github.com/elusiv0/medods_test/internal/model/user"
 	"github.com/elusiv0/medods_test/internal/repo"
 	userModel "github.com/elusiv0/medods_test/internal/repo/user/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
 	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type UserRepo struct {
@@ -42,8 +44,7 @@ func New(
 func (repo *UserRepo) GetUserByUUID(ctx context.Context, uuid string) (userDto.User, error) {
 	userModel := userModel.User{}
 
-	filter := bson.D{{"_id", uuid}}
-	if err := repo.collection.FindOne(ctx, filter).Decode(&userModel); err != nil {
+	if err := repo.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": uuid})).Decode(&userModel); err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			err = userDto.ErrUserNotFound
 		}
@@ -55,16 +56,174 @@ func (repo *UserRepo) GetUserByUUID(ctx context.Context, uuid string) (userDto.U
 
 func (repo *UserRepo) InsertUser(ctx context.Context, user userDto.CreateUser) (string, error) {
 	userModel := mapper.CreateUserToUserModel(user)
+	userModel.Tenant = tenantUtil.FromContext(ctx)
 	result, err := repo.collection.InsertOne(ctx, userModel)
 
 	if err != nil {
 		return "", fmt.Errorf("UserRepo - InsertUser - InsertOne: %w", err)
 	}
 
-	uuid, ok := result.InsertedID.(primitive.ObjectID)
+	uuid, ok := result.InsertedID.(string)
 	if !ok {
-		return "", fmt.Errorf("UserRepo - InsertUser - Get Inserted ID: %w", err)
+		return "", fmt.Errorf("UserRepo - InsertUser - Get Inserted ID: unexpected type %T", result.InsertedID)
 	}
 
-	return uuid.Hex(), nil
+	return uuid, nil
+}
+
+// UpsertUser stores user under its uuid in tenant of ctx, reports whether it was
+// created or an existing one was changed.
+func (repo *UserRepo) UpsertUser(ctx context.Context, user userDto.User) (bool, bool, error) {
+	roles := user.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	update := bson.M{"$set": bson.M{
+		"name":     user.Name,
+		"disabled": user.Disabled,
+		"roles":    roles,
+	}}
+
+	filter := scoped(ctx, bson.M{"_id": user.UUID})
+	result, err := repo.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, false, fmt.Errorf("UserRepo - UpsertUser - UpdateOne: %w", err)
+	}
+
+	return result.UpsertedCount > 0, result.ModifiedCount > 0, nil
+}
+
+func (repo *UserRepo) UpdateUser(ctx context.Context, uuid string, update userDto.UpdateUser) (userDto.User, error) {
+	set := bson.M{}
+	if update.Name != nil {
+		set["name"] = *update.Name
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	userModel := userModel.User{}
+
+	var result *mongo.SingleResult
+	if len(set) == 0 {
+		result = repo.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": uuid}))
+	} else {
+		result = repo.collection.FindOneAndUpdate(ctx, scoped(ctx, bson.M{"_id": uuid}), bson.M{"$set": set}, opts)
+	}
+	if err := result.Decode(&userModel); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = userDto.ErrUserNotFound
+		}
+		return userDto.User{}, fmt.Errorf("UserRepo - UpdateUser - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToUser(userModel), nil
+}
+
+// ListUsers returns users ordered by uuid, starting right after filter.Cursor.
+func (repo *UserRepo) ListUsers(ctx context.Context, filter userDto.Filter) ([]userDto.User, error) {
+	query := scoped(ctx, bson.M{})
+	if filter.Cursor != "" {
+		query["_id"] = bson.M{"$gt": filter.Cursor}
+	}
+	if filter.Query != "" {
+		query["name"] = bson.M{"$regex": regexp.QuoteMeta(filter.Query), "$options": "i"}
+	}
+	if filter.Disabled != nil {
+		if *filter.Disabled {
+			query["disabled"] = true
+		} else {
+			// users stored before disabled field was added have no value
+			query["disabled"] = bson.M{"$ne": true}
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := repo.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("UserRepo - ListUsers - Find: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	users := make([]userDto.User, 0)
+	for cursor.Next(ctx) {
+		userModel := userModel.User{}
+		if err := cursor.Decode(&userModel); err != nil {
+			return nil, fmt.Errorf("UserRepo - ListUsers - Decode: %w", err)
+		}
+		users = append(users, mapper.ModelToUser(userModel))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("UserRepo - ListUsers - Cursor: %w", err)
+	}
+
+	return users, nil
+}
+
+func (repo *UserRepo) SetDisabled(ctx context.Context, uuid string, disabled bool) error {
+	update := bson.M{"$set": bson.M{"disabled": disabled}}
+
+	result, err := repo.collection.UpdateOne(ctx, scoped(ctx, bson.M{"_id": uuid}), update)
+	if err != nil {
+		return fmt.Errorf("UserRepo - SetDisabled - UpdateOne: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("UserRepo - SetDisabled: %w", userDto.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+func (repo *UserRepo) SetRoles(ctx context.Context, uuid string, roles []string) (userDto.User, error) {
+	if roles == nil {
+		roles = []string{}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	userModel := userModel.User{}
+
+	filter := scoped(ctx, bson.M{"_id": uuid})
+	err := repo.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"roles": roles}}, opts).Decode(&userModel)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = userDto.ErrUserNotFound
+		}
+		return userDto.User{}, fmt.Errorf("UserRepo - SetRoles - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToUser(userModel), nil
+}
+
+// UnassignRole removes role from every user holding it, returns amount of changed
+// users. Roles are shared by tenants, so it is the only query not scoped to tenant.
+func (repo *UserRepo) UnassignRole(ctx context.Context, role string) (int64, error) {
+	result, err := repo.collection.UpdateMany(ctx, bson.M{"roles": role}, bson.M{"$pull": bson.M{"roles": role}})
+	if err != nil {
+		return 0, fmt.Errorf("UserRepo - UnassignRole - UpdateMany: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (repo *UserRepo) DeleteUser(ctx context.Context, uuid string) error {
+	result, err := repo.collection.DeleteOne(ctx, scoped(ctx, bson.M{"_id": uuid}))
+	if err != nil {
+		return fmt.Errorf("UserRepo - DeleteUser - DeleteOne: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("UserRepo - DeleteUser: %w", userDto.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// scoped restricts filter to users of tenant of ctx. Users are keyed by uuid
+// alone, so uuid is unique across tenants: upsert of uuid taken by another
+// tenant fails with duplicate key error instead of creating a second user.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	filter["tenant_id"] = tenantUtil.FromContext(ctx)
+
+	return filter
 }
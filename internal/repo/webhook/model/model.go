@@ -0,0 +1,34 @@
+package webhook
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Subscription struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Tenant    string             `bson:"tenant_id"`
+	URL       string             `bson:"url"`
+	Events    []string           `bson:"events"`
+	Secret    string             `bson:"secret"`
+	Active    bool               `bson:"active"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+type Delivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	Tenant         string             `bson:"tenant_id"`
+	SubscriptionID primitive.ObjectID `bson:"subscription_id"`
+	EventID        string             `bson:"event_id"`
+	EventType      string             `bson:"event_type"`
+	Payload        string             `bson:"payload"`
+	Status         string             `bson:"status"`
+	Attempts       int                `bson:"attempts"`
+	NextAttemptAt  time.Time          `bson:"next_attempt_at"`
+	LockedUntil    time.Time          `bson:"locked_until,omitempty"`
+	LastStatusCode int                `bson:"last_status_code,omitempty"`
+	LastError      string             `bson:"last_error,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at"`
+}
@@ -0,0 +1,348 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	mapper "github.com/elusiv0/medods_test/internal/mapper/webhook"
+	webhookDto "github.com/elusiv0/medods_test/internal/model/webhook"
+	"github.com/elusiv0/medods_test/internal/repo"
+	webhookModel "github.com/elusiv0/medods_test/internal/repo/webhook/model"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookRepo struct {
+	subscriptions *mongo.Collection
+	deliveries    *mongo.Collection
+	logger        *slog.Logger
+}
+
+const (
+	subscriptionsCollectionName = "webhook_subscriptions"
+	deliveriesCollectionName    = "webhook_deliveries"
+)
+
+var _ repo.WebhookRepo = (*WebhookRepo)(nil)
+
+func New(
+	client *mongoClient.MongoClient,
+	log *slog.Logger,
+) *WebhookRepo {
+	return &WebhookRepo{
+		subscriptions: client.MongoDatabase.Collection(subscriptionsCollectionName),
+		deliveries:    client.MongoDatabase.Collection(deliveriesCollectionName),
+		logger:        log,
+	}
+}
+
+// InsertSubscription stores subscription of tenant of ctx, it only receives
+// events of that tenant.
+func (repo *WebhookRepo) InsertSubscription(ctx context.Context, subscription webhookDto.Subscription) (string, error) {
+	subscription.Tenant = tenantUtil.FromContext(ctx)
+	result, err := repo.subscriptions.InsertOne(ctx, mapper.SubscriptionToModel(subscription))
+	if err != nil {
+		return "", fmt.Errorf("WebhookRepo - InsertSubscription - InsertOne: %w", err)
+	}
+
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (repo *WebhookRepo) GetSubscription(ctx context.Context, id string) (webhookDto.Subscription, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookRepo - GetSubscription: %w", webhookDto.ErrSubscriptionNotFound)
+	}
+
+	subscriptionModel := webhookModel.Subscription{}
+	if err := repo.subscriptions.FindOne(ctx, scoped(ctx, bson.M{"_id": objectID})).Decode(&subscriptionModel); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = webhookDto.ErrSubscriptionNotFound
+		}
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookRepo - GetSubscription - FindOne: %w", err)
+	}
+
+	return mapper.ModelToSubscription(subscriptionModel), nil
+}
+
+func (repo *WebhookRepo) ListSubscriptions(ctx context.Context) ([]webhookDto.Subscription, error) {
+	subscriptions, err := repo.findSubscriptions(ctx, scoped(ctx, bson.M{}))
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepo - ListSubscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (repo *WebhookRepo) ListActiveSubscriptions(ctx context.Context, eventType string) ([]webhookDto.Subscription, error) {
+	filter := scoped(ctx, bson.M{
+		"active": true,
+		"events": bson.M{"$in": bson.A{eventType, webhookDto.AllEvents}},
+	})
+
+	subscriptions, err := repo.findSubscriptions(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepo - ListActiveSubscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (repo *WebhookRepo) UpdateSubscription(
+	ctx context.Context,
+	id string,
+	update webhookDto.UpdateSubscription,
+) (webhookDto.Subscription, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookRepo - UpdateSubscription: %w", webhookDto.ErrSubscriptionNotFound)
+	}
+
+	set := bson.M{}
+	if update.URL != nil {
+		set["url"] = *update.URL
+	}
+	if update.Events != nil {
+		set["events"] = *update.Events
+	}
+	if update.Active != nil {
+		set["active"] = *update.Active
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	subscriptionModel := webhookModel.Subscription{}
+
+	var result *mongo.SingleResult
+	if len(set) == 0 {
+		result = repo.subscriptions.FindOne(ctx, scoped(ctx, bson.M{"_id": objectID}))
+	} else {
+		result = repo.subscriptions.FindOneAndUpdate(ctx, scoped(ctx, bson.M{"_id": objectID}), bson.M{"$set": set}, opts)
+	}
+	if err := result.Decode(&subscriptionModel); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = webhookDto.ErrSubscriptionNotFound
+		}
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookRepo - UpdateSubscription - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToSubscription(subscriptionModel), nil
+}
+
+func (repo *WebhookRepo) DeleteSubscription(ctx context.Context, id string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("WebhookRepo - DeleteSubscription: %w", webhookDto.ErrSubscriptionNotFound)
+	}
+
+	result, err := repo.subscriptions.DeleteOne(ctx, scoped(ctx, bson.M{"_id": objectID}))
+	if err != nil {
+		return fmt.Errorf("WebhookRepo - DeleteSubscription - DeleteOne: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("WebhookRepo - DeleteSubscription: %w", webhookDto.ErrSubscriptionNotFound)
+	}
+
+	return nil
+}
+
+// InsertDeliveries queues deliveries stamped with tenant of ctx.
+func (repo *WebhookRepo) InsertDeliveries(ctx context.Context, deliveries []webhookDto.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		delivery.Tenant = tenantUtil.FromContext(ctx)
+		deliveryModel, err := mapper.DeliveryToModel(delivery)
+		if err != nil {
+			return fmt.Errorf("WebhookRepo - InsertDeliveries: %w", err)
+		}
+		documents = append(documents, deliveryModel)
+	}
+
+	if _, err := repo.deliveries.InsertMany(ctx, documents); err != nil {
+		return fmt.Errorf("WebhookRepo - InsertDeliveries - InsertMany: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *WebhookRepo) GetDelivery(ctx context.Context, id string) (webhookDto.Delivery, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return webhookDto.Delivery{}, fmt.Errorf("WebhookRepo - GetDelivery: %w", webhookDto.ErrDeliveryNotFound)
+	}
+
+	deliveryModel := webhookModel.Delivery{}
+	if err := repo.deliveries.FindOne(ctx, scoped(ctx, bson.M{"_id": objectID})).Decode(&deliveryModel); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = webhookDto.ErrDeliveryNotFound
+		}
+		return webhookDto.Delivery{}, fmt.Errorf("WebhookRepo - GetDelivery - FindOne: %w", err)
+	}
+
+	return mapper.ModelToDelivery(deliveryModel), nil
+}
+
+func (repo *WebhookRepo) ListDeliveries(ctx context.Context, filter webhookDto.DeliveryFilter) ([]webhookDto.Delivery, error) {
+	query := scoped(ctx, bson.M{})
+	if filter.SubscriptionID != "" {
+		objectID, err := primitive.ObjectIDFromHex(filter.SubscriptionID)
+		if err != nil {
+			return nil, fmt.Errorf("WebhookRepo - ListDeliveries: %w", webhookDto.ErrSubscriptionNotFound)
+		}
+		query["subscription_id"] = objectID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit))
+	}
+
+	cursor, err := repo.deliveries.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepo - ListDeliveries - Find: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	deliveries := make([]webhookDto.Delivery, 0)
+	for cursor.Next(ctx) {
+		deliveryModel := webhookModel.Delivery{}
+		if err := cursor.Decode(&deliveryModel); err != nil {
+			return nil, fmt.Errorf("WebhookRepo - ListDeliveries - Decode: %w", err)
+		}
+		deliveries = append(deliveries, mapper.ModelToDelivery(deliveryModel))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("WebhookRepo - ListDeliveries - Cursor: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDelivery leases one due delivery of any tenant to the caller, so that
+// several replicas running workers never send the same delivery at once.
+func (repo *WebhookRepo) ClaimDelivery(ctx context.Context, now time.Time, lease time.Duration) (webhookDto.Delivery, error) {
+	now = now.UTC()
+	filter := bson.M{
+		"status":          webhookDto.StatusPending,
+		"next_attempt_at": bson.M{"$lte": now},
+		"$or": bson.A{
+			bson.M{"locked_until": bson.M{"$exists": false}},
+			bson.M{"locked_until": bson.M{"$lte": now}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"locked_until": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	deliveryModel := webhookModel.Delivery{}
+	if err := repo.deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&deliveryModel); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = webhookDto.ErrDeliveryNotFound
+		}
+		return webhookDto.Delivery{}, fmt.Errorf("WebhookRepo - ClaimDelivery - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToDelivery(deliveryModel), nil
+}
+
+// UpdateDelivery stores outcome of delivery attempt and releases the lease.
+func (repo *WebhookRepo) UpdateDelivery(ctx context.Context, delivery webhookDto.Delivery) error {
+	objectID, err := primitive.ObjectIDFromHex(delivery.ID)
+	if err != nil {
+		return fmt.Errorf("WebhookRepo - UpdateDelivery: %w", webhookDto.ErrDeliveryNotFound)
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":           delivery.Status,
+			"attempts":         delivery.Attempts,
+			"next_attempt_at":  delivery.NextAttemptAt.UTC(),
+			"last_status_code": delivery.LastStatusCode,
+			"last_error":       delivery.LastError,
+			"updated_at":       delivery.UpdatedAt.UTC(),
+		},
+		"$unset": bson.M{"locked_until": ""},
+	}
+
+	result, err := repo.deliveries.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+	if err != nil {
+		return fmt.Errorf("WebhookRepo - UpdateDelivery - UpdateOne: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("WebhookRepo - UpdateDelivery: %w", webhookDto.ErrDeliveryNotFound)
+	}
+
+	return nil
+}
+
+// RequeueDelivery schedules delivery again with a fresh attempts budget.
+func (repo *WebhookRepo) RequeueDelivery(ctx context.Context, id string, at time.Time) (webhookDto.Delivery, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return webhookDto.Delivery{}, fmt.Errorf("WebhookRepo - RequeueDelivery: %w", webhookDto.ErrDeliveryNotFound)
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":          webhookDto.StatusPending,
+			"attempts":        0,
+			"next_attempt_at": at.UTC(),
+			"updated_at":      at.UTC(),
+		},
+		"$unset": bson.M{"locked_until": ""},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	deliveryModel := webhookModel.Delivery{}
+	if err := repo.deliveries.FindOneAndUpdate(ctx, scoped(ctx, bson.M{"_id": objectID}), update, opts).Decode(&deliveryModel); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = webhookDto.ErrDeliveryNotFound
+		}
+		return webhookDto.Delivery{}, fmt.Errorf("WebhookRepo - RequeueDelivery - FindOneAndUpdate: %w", err)
+	}
+
+	return mapper.ModelToDelivery(deliveryModel), nil
+}
+
+func (repo *WebhookRepo) findSubscriptions(ctx context.Context, filter bson.M) ([]webhookDto.Subscription, error) {
+	cursor, err := repo.subscriptions.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("Find: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	subscriptions := make([]webhookDto.Subscription, 0)
+	for cursor.Next(ctx) {
+		subscriptionModel := webhookModel.Subscription{}
+		if err := cursor.Decode(&subscriptionModel); err != nil {
+			return nil, fmt.Errorf("Decode: %w", err)
+		}
+		subscriptions = append(subscriptions, mapper.ModelToSubscription(subscriptionModel))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("Cursor: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// scoped restricts filter to subscriptions or deliveries of tenant of ctx.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	filter["tenant_id"] = tenantUtil.FromContext(ctx)
+
+	return filter
+}
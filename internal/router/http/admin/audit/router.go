@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditRouter struct {
+	auditService *auditService.AuditService
+	logger       *slog.Logger
+}
+
+func New(
+	auditService *auditService.AuditService,
+	log *slog.Logger,
+	group *gin.RouterGroup,
+) {
+	auditRouter := &AuditRouter{
+		auditService: auditService,
+		logger:       log,
+	}
+
+	group.GET("", auditRouter.list)
+	group.GET("/export", auditRouter.export)
+}
+
+func (auditRouter *AuditRouter) list(c *gin.Context) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		auditRouter.logger.Error("AuditRouter - list: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	page, err := auditRouter.auditService.List(ctx, filter)
+	if err != nil {
+		auditRouter.logger.Error("AuditRouter - list: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (auditRouter *AuditRouter) export(c *gin.Context) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		auditRouter.logger.Error("AuditRouter - export: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	c.Status(http.StatusOK)
+
+	ctx := c.Request.Context()
+	if err := auditRouter.auditService.Export(ctx, filter, c.Writer); err != nil {
+		// headers are already sent, so the stream is just cut short
+		auditRouter.logger.Error("AuditRouter - export: " + err.Error())
+	}
+}
+
+func parseFilter(c *gin.Context) (auditDto.Filter, error) {
+	filter := auditDto.Filter{
+		User:   c.Query("user"),
+		Cursor: c.Query("cursor"),
+	}
+
+	for _, types := range c.QueryArray("type") {
+		for _, t := range strings.Split(types, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				filter.Types = append(filter.Types, t)
+			}
+		}
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return filter, fmt.Errorf("AuditRouter - parseFilter - from: %w", auditDto.ErrBadFilter)
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return filter, fmt.Errorf("AuditRouter - parseFilter - to: %w", auditDto.ErrBadFilter)
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+			return filter, fmt.Errorf("AuditRouter - parseFilter - limit: %w", auditDto.ErrBadFilter)
+		}
+	}
+
+	return filter, nil
+}
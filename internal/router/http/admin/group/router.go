@@ -0,0 +1,179 @@
+package group
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	groupService "github.com/elusiv0/medods_test/internal/service/group"
+	"github.com/gin-gonic/gin"
+)
+
+type GroupRouter struct {
+	groupService *groupService.GroupService
+	logger       *slog.Logger
+}
+
+func New(
+	groupService *groupService.GroupService,
+	log *slog.Logger,
+	groups *gin.RouterGroup,
+	users *gin.RouterGroup,
+) {
+	groupRouter := &GroupRouter{
+		groupService: groupService,
+		logger:       log,
+	}
+
+	groups.POST("", groupRouter.create)
+	groups.GET("", groupRouter.list)
+	groups.GET("/:id", groupRouter.get)
+	groups.PATCH("/:id", groupRouter.update)
+	groups.DELETE("/:id", groupRouter.delete)
+	groups.POST("/:id/members", groupRouter.addMember)
+	groups.DELETE("/:id/members/:uuid", groupRouter.removeMember)
+
+	users.GET("/:uuid/groups", groupRouter.userGroups)
+	users.GET("/:uuid/permissions", groupRouter.effective)
+}
+
+func (groupRouter *GroupRouter) create(c *gin.Context) {
+	create := groupDto.CreateGroup{}
+	if err := c.ShouldBindJSON(&create); err != nil {
+		groupRouter.logger.Error("GroupRouter - create: " + err.Error())
+		c.Error(groupDto.ErrBadGroup)
+		return
+	}
+
+	ctx := c.Request.Context()
+	group, err := groupRouter.groupService.Create(ctx, create)
+	if err != nil {
+		groupRouter.logger.Error("GroupRouter - create: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+func (groupRouter *GroupRouter) list(c *gin.Context) {
+	ctx := c.Request.Context()
+	groups, err := groupRouter.groupService.List(ctx)
+	if err != nil {
+		groupRouter.logger.Error("GroupRouter - list: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+func (groupRouter *GroupRouter) get(c *gin.Context) {
+	ctx := c.Request.Context()
+	group, err := groupRouter.groupService.Get(ctx, c.Param("id"))
+	if err != nil {
+		groupRouter.logger.Error("GroupRouter - get: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+func (groupRouter *GroupRouter) update(c *gin.Context) {
+	update := groupDto.UpdateGroup{}
+	if err := c.ShouldBindJSON(&update); err != nil {
+		groupRouter.logger.Error("GroupRouter - update: " + err.Error())
+		c.Error(groupDto.ErrBadGroup)
+		return
+	}
+
+	ctx := c.Request.Context()
+	group, err := groupRouter.groupService.Update(ctx, c.Param("id"), update)
+	if err != nil {
+		groupRouter.logger.Error("GroupRouter - update: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+func (groupRouter *GroupRouter) delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := groupRouter.groupService.Delete(ctx, c.Param("id")); err != nil {
+		groupRouter.logger.Error("GroupRouter - delete: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (groupRouter *GroupRouter) addMember(c *gin.Context) {
+	member := groupDto.Member{}
+	if err := c.ShouldBindJSON(&member); err != nil || member.UUID == "" {
+		groupRouter.logger.Error("GroupRouter - addMember: invalid member")
+		c.Error(userDto.ErrNoSuchUser)
+		return
+	}
+
+	ctx := c.Request.Context()
+	group, err := groupRouter.groupService.AddMember(ctx, c.Param("id"), member.UUID)
+	if err != nil {
+		groupRouter.logger.Error("GroupRouter - addMember: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+func (groupRouter *GroupRouter) removeMember(c *gin.Context) {
+	ctx := c.Request.Context()
+	group, err := groupRouter.groupService.RemoveMember(ctx, c.Param("id"), c.Param("uuid"))
+	if err != nil {
+		groupRouter.logger.Error("GroupRouter - removeMember: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+func (groupRouter *GroupRouter) userGroups(c *gin.Context) {
+	ctx := c.Request.Context()
+	groups, err := groupRouter.groupService.UserGroups(ctx, c.Param("uuid"))
+	if err != nil {
+		groupRouter.logger.Error("GroupRouter - userGroups: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// effective shows roles, groups and permissions user gets directly and through
+// nested groups.
+func (groupRouter *GroupRouter) effective(c *gin.Context) {
+	ctx := c.Request.Context()
+	effective, err := groupRouter.groupService.EffectiveOf(ctx, c.Param("uuid"))
+	if err != nil {
+		groupRouter.logger.Error("GroupRouter - effective: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, effective)
+}
+
+// notFound reports missing user as 404 like the users API does.
+func notFound(err error) error {
+	if errors.Is(err, userDto.ErrUserNotFound) {
+		return userDto.ErrNoSuchUser
+	}
+
+	return err
+}
@@ -0,0 +1,51 @@
+package lockout
+
+import (
+	"log/slog"
+	"net/http"
+
+	lockoutService "github.com/elusiv0/medods_test/internal/service/lockout"
+	"github.com/gin-gonic/gin"
+)
+
+type LockoutRouter struct {
+	lockoutService *lockoutService.LockoutService
+	logger         *slog.Logger
+}
+
+func New(
+	lockoutService *lockoutService.LockoutService,
+	log *slog.Logger,
+	group *gin.RouterGroup,
+) {
+	lockoutRouter := &LockoutRouter{
+		lockoutService: lockoutService,
+		logger:         log,
+	}
+
+	group.GET("/:uuid", lockoutRouter.get)
+	group.DELETE("/:uuid", lockoutRouter.unlock)
+}
+
+func (lockoutRouter *LockoutRouter) get(c *gin.Context) {
+	ctx := c.Request.Context()
+	lockout, err := lockoutRouter.lockoutService.Get(ctx, c.Param("uuid"))
+	if err != nil {
+		lockoutRouter.logger.Error("LockoutRouter - get: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lockout)
+}
+
+func (lockoutRouter *LockoutRouter) unlock(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := lockoutRouter.lockoutService.Unlock(ctx, c.Param("uuid")); err != nil {
+		lockoutRouter.logger.Error("LockoutRouter - unlock: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	policyDto "github.com/elusiv0/medods_test/internal/model/policy"
+	policyService "github.com/elusiv0/medods_test/internal/service/policy"
+	"github.com/gin-gonic/gin"
+)
+
+type PolicyRouter struct {
+	policyService *policyService.PolicyService
+	logger        *slog.Logger
+}
+
+func New(
+	policyService *policyService.PolicyService,
+	log *slog.Logger,
+	group *gin.RouterGroup,
+	globalGroup *gin.RouterGroup,
+) {
+	policyRouter := &PolicyRouter{
+		policyService: policyService,
+		logger:        log,
+	}
+
+	// policies are shared by tenants, only decisions are kept per tenant
+	globalGroup.GET("", policyRouter.status)
+	group.GET("/decisions", policyRouter.decisions)
+	globalGroup.POST("/reload", policyRouter.reload)
+}
+
+func (policyRouter *PolicyRouter) status(c *gin.Context) {
+	c.JSON(http.StatusOK, policyRouter.policyService.Status())
+}
+
+func (policyRouter *PolicyRouter) decisions(c *gin.Context) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		policyRouter.logger.Error("PolicyRouter - decisions: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policyRouter.policyService.Decisions(c.Request.Context(), filter))
+}
+
+func (policyRouter *PolicyRouter) reload(c *gin.Context) {
+	ctx := c.Request.Context()
+	status, err := policyRouter.policyService.Reload(ctx)
+	if err != nil {
+		policyRouter.logger.Error("PolicyRouter - reload: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+func parseFilter(c *gin.Context) (policyDto.Filter, error) {
+	filter := policyDto.Filter{
+		Subject: c.Query("subject"),
+	}
+
+	var err error
+	if denied := c.Query("denied"); denied != "" {
+		if filter.Denied, err = strconv.ParseBool(denied); err != nil {
+			return filter, fmt.Errorf("PolicyRouter - parseFilter - denied: %w", policyDto.ErrBadFilter)
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+			return filter, fmt.Errorf("PolicyRouter - parseFilter - limit: %w", policyDto.ErrBadFilter)
+		}
+	}
+
+	return filter, nil
+}
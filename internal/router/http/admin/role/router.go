@@ -0,0 +1,153 @@
+package role
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	roleService "github.com/elusiv0/medods_test/internal/service/role"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleRouter struct {
+	roleService *roleService.RoleService
+	logger      *slog.Logger
+}
+
+func New(
+	roleService *roleService.RoleService,
+	log *slog.Logger,
+	roles *gin.RouterGroup,
+	globalRoles *gin.RouterGroup,
+	users *gin.RouterGroup,
+) {
+	roleRouter := &RoleRouter{
+		roleService: roleService,
+		logger:      log,
+	}
+
+	// roles are shared by tenants, only assignment is kept per tenant
+	globalRoles.POST("", roleRouter.create)
+	roles.GET("", roleRouter.list)
+	roles.GET("/:name", roleRouter.get)
+	globalRoles.PATCH("/:name", roleRouter.update)
+	globalRoles.DELETE("/:name", roleRouter.delete)
+
+	users.GET("/:uuid/roles", roleRouter.assignment)
+	users.PUT("/:uuid/roles", roleRouter.assign)
+}
+
+func (roleRouter *RoleRouter) create(c *gin.Context) {
+	create := roleDto.CreateRole{}
+	if err := c.ShouldBindJSON(&create); err != nil {
+		roleRouter.logger.Error("RoleRouter - create: " + err.Error())
+		c.Error(roleDto.ErrBadRole)
+		return
+	}
+
+	ctx := c.Request.Context()
+	role, err := roleRouter.roleService.Create(ctx, create)
+	if err != nil {
+		roleRouter.logger.Error("RoleRouter - create: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+func (roleRouter *RoleRouter) list(c *gin.Context) {
+	ctx := c.Request.Context()
+	roles, err := roleRouter.roleService.List(ctx)
+	if err != nil {
+		roleRouter.logger.Error("RoleRouter - list: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+func (roleRouter *RoleRouter) get(c *gin.Context) {
+	ctx := c.Request.Context()
+	role, err := roleRouter.roleService.Get(ctx, c.Param("name"))
+	if err != nil {
+		roleRouter.logger.Error("RoleRouter - get: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (roleRouter *RoleRouter) update(c *gin.Context) {
+	update := roleDto.UpdateRole{}
+	if err := c.ShouldBindJSON(&update); err != nil {
+		roleRouter.logger.Error("RoleRouter - update: " + err.Error())
+		c.Error(roleDto.ErrBadRole)
+		return
+	}
+
+	ctx := c.Request.Context()
+	role, err := roleRouter.roleService.Update(ctx, c.Param("name"), update)
+	if err != nil {
+		roleRouter.logger.Error("RoleRouter - update: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (roleRouter *RoleRouter) delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := roleRouter.roleService.Delete(ctx, c.Param("name")); err != nil {
+		roleRouter.logger.Error("RoleRouter - delete: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (roleRouter *RoleRouter) assignment(c *gin.Context) {
+	ctx := c.Request.Context()
+	assignment, err := roleRouter.roleService.Assignment(ctx, c.Param("uuid"))
+	if err != nil {
+		roleRouter.logger.Error("RoleRouter - assignment: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+func (roleRouter *RoleRouter) assign(c *gin.Context) {
+	assignment := roleDto.Assignment{}
+	if err := c.ShouldBindJSON(&assignment); err != nil {
+		roleRouter.logger.Error("RoleRouter - assign: " + err.Error())
+		c.Error(roleDto.ErrUnknownRole)
+		return
+	}
+
+	ctx := c.Request.Context()
+	assignment, err := roleRouter.roleService.Assign(ctx, c.Param("uuid"), assignment)
+	if err != nil {
+		roleRouter.logger.Error("RoleRouter - assign: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// notFound reports missing user as 404 like the users API does.
+func notFound(err error) error {
+	if errors.Is(err, userDto.ErrUserNotFound) {
+		return userDto.ErrNoSuchUser
+	}
+
+	return err
+}
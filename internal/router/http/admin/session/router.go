@@ -0,0 +1,45 @@
+package session
+
+import (
+	"log/slog"
+	"net/http"
+
+	authService "github.com/elusiv0/medods_test/internal/service/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionRouter struct {
+	authService *authService.AuthService
+	logger      *slog.Logger
+}
+
+type revokeResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+func New(
+	authService *authService.AuthService,
+	log *slog.Logger,
+	group *gin.RouterGroup,
+) {
+	sessionRouter := &SessionRouter{
+		authService: authService,
+		logger:      log,
+	}
+
+	group.DELETE("/:uuid", sessionRouter.revoke)
+}
+
+func (sessionRouter *SessionRouter) revoke(c *gin.Context) {
+	ctx := c.Request.Context()
+	revoked, err := sessionRouter.authService.RevokeSessions(ctx, c.Param("uuid"))
+	if err != nil {
+		sessionRouter.logger.Error("SessionRouter - revoke: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revokeResponse{
+		Revoked: revoked,
+	})
+}
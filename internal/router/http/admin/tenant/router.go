@@ -0,0 +1,93 @@
+package tenant
+
+import (
+	"log/slog"
+	"net/http"
+
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
+	tenantService "github.com/elusiv0/medods_test/internal/service/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+type TenantRouter struct {
+	tenantService *tenantService.TenantService
+	logger        *slog.Logger
+}
+
+func New(
+	tenantService *tenantService.TenantService,
+	log *slog.Logger,
+	group *gin.RouterGroup,
+) {
+	tenantRouter := &TenantRouter{
+		tenantService: tenantService,
+		logger:        log,
+	}
+
+	group.POST("", tenantRouter.create)
+	group.GET("", tenantRouter.list)
+	group.GET("/:id", tenantRouter.get)
+	group.PATCH("/:id", tenantRouter.update)
+}
+
+func (tenantRouter *TenantRouter) create(c *gin.Context) {
+	create := tenantDto.CreateTenant{}
+	if err := c.ShouldBindJSON(&create); err != nil {
+		tenantRouter.logger.Error("TenantRouter - create: " + err.Error())
+		c.Error(tenantDto.ErrBadTenant)
+		return
+	}
+
+	ctx := c.Request.Context()
+	tenant, err := tenantRouter.tenantService.Create(ctx, create)
+	if err != nil {
+		tenantRouter.logger.Error("TenantRouter - create: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, tenant)
+}
+
+func (tenantRouter *TenantRouter) list(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenants, err := tenantRouter.tenantService.List(ctx)
+	if err != nil {
+		tenantRouter.logger.Error("TenantRouter - list: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenants)
+}
+
+func (tenantRouter *TenantRouter) get(c *gin.Context) {
+	ctx := c.Request.Context()
+	tenant, err := tenantRouter.tenantService.Get(ctx, c.Param("id"))
+	if err != nil {
+		tenantRouter.logger.Error("TenantRouter - get: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
+
+func (tenantRouter *TenantRouter) update(c *gin.Context) {
+	update := tenantDto.UpdateTenant{}
+	if err := c.ShouldBindJSON(&update); err != nil {
+		tenantRouter.logger.Error("TenantRouter - update: " + err.Error())
+		c.Error(tenantDto.ErrBadTenant)
+		return
+	}
+
+	ctx := c.Request.Context()
+	tenant, err := tenantRouter.tenantService.Update(ctx, c.Param("id"), update)
+	if err != nil {
+		tenantRouter.logger.Error("TenantRouter - update: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tenant)
+}
@@ -0,0 +1,186 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	userService "github.com/elusiv0/medods_test/internal/service/user"
+	"github.com/gin-gonic/gin"
+)
+
+type UserRouter struct {
+	userService *userService.UserService
+	logger      *slog.Logger
+}
+
+type statusResponse struct {
+	UUID     string `json:"uuid"`
+	Disabled bool   `json:"disabled"`
+}
+
+func New(
+	userService *userService.UserService,
+	log *slog.Logger,
+	group *gin.RouterGroup,
+) {
+	userRouter := &UserRouter{
+		userService: userService,
+		logger:      log,
+	}
+
+	group.POST("", userRouter.create)
+	group.GET("", userRouter.list)
+	group.GET("/:uuid", userRouter.get)
+	group.PATCH("/:uuid", userRouter.update)
+	group.POST("/:uuid/disable", userRouter.disable)
+	group.POST("/:uuid/enable", userRouter.enable)
+	group.DELETE("/:uuid", userRouter.delete)
+}
+
+func (userRouter *UserRouter) create(c *gin.Context) {
+	create := userDto.CreateUser{}
+	if err := c.ShouldBindJSON(&create); err != nil {
+		userRouter.logger.Error("UserRouter - create: " + err.Error())
+		c.Error(userDto.ErrBadUser)
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := userRouter.userService.Create(ctx, create)
+	if err != nil {
+		userRouter.logger.Error("UserRouter - create: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// list pages users by uuid, ?q= searches names and ?disabled=true|false filters by status.
+func (userRouter *UserRouter) list(c *gin.Context) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		userRouter.logger.Error("UserRouter - list: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	page, err := userRouter.userService.List(ctx, filter)
+	if err != nil {
+		userRouter.logger.Error("UserRouter - list: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (userRouter *UserRouter) get(c *gin.Context) {
+	ctx := c.Request.Context()
+	user, err := userRouter.userService.Get(ctx, c.Param("uuid"))
+	if err != nil {
+		userRouter.logger.Error("UserRouter - get: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (userRouter *UserRouter) update(c *gin.Context) {
+	update := userDto.UpdateUser{}
+	if err := c.ShouldBindJSON(&update); err != nil {
+		userRouter.logger.Error("UserRouter - update: " + err.Error())
+		c.Error(userDto.ErrBadUser)
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := userRouter.userService.Update(ctx, c.Param("uuid"), update)
+	if err != nil {
+		userRouter.logger.Error("UserRouter - update: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (userRouter *UserRouter) disable(c *gin.Context) {
+	ctx := c.Request.Context()
+	uuid := c.Param("uuid")
+	if err := userRouter.userService.Disable(ctx, uuid); err != nil {
+		userRouter.logger.Error("UserRouter - disable: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		UUID:     uuid,
+		Disabled: true,
+	})
+}
+
+func (userRouter *UserRouter) enable(c *gin.Context) {
+	ctx := c.Request.Context()
+	uuid := c.Param("uuid")
+	if err := userRouter.userService.Enable(ctx, uuid); err != nil {
+		userRouter.logger.Error("UserRouter - enable: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, statusResponse{
+		UUID:     uuid,
+		Disabled: false,
+	})
+}
+
+func (userRouter *UserRouter) delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := userRouter.userService.Delete(ctx, c.Param("uuid")); err != nil {
+		userRouter.logger.Error("UserRouter - delete: " + err.Error())
+		c.Error(notFound(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func parseFilter(c *gin.Context) (userDto.Filter, error) {
+	filter := userDto.Filter{
+		Query:  c.Query("q"),
+		Cursor: c.Query("cursor"),
+	}
+
+	var err error
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit < 0 {
+			return filter, fmt.Errorf("UserRouter - parseFilter - limit: %w", userDto.ErrBadFilter)
+		}
+	}
+	if disabled := c.Query("disabled"); disabled != "" {
+		parsed, err := strconv.ParseBool(disabled)
+		if err != nil {
+			return filter, fmt.Errorf("UserRouter - parseFilter - disabled: %w", userDto.ErrBadFilter)
+		}
+		filter.Disabled = &parsed
+	}
+
+	return filter, nil
+}
+
+// notFound reports missing user as 404, the error is 401 on sign in where the
+// caller is the user itself.
+func notFound(err error) error {
+	if errors.Is(err, userDto.ErrUserNotFound) {
+		return userDto.ErrNoSuchUser
+	}
+
+	return err
+}
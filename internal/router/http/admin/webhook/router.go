@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	webhookDto "github.com/elusiv0/medods_test/internal/model/webhook"
+	webhookService "github.com/elusiv0/medods_test/internal/service/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookRouter struct {
+	webhookService *webhookService.WebhookService
+	logger         *slog.Logger
+}
+
+func New(
+	webhookService *webhookService.WebhookService,
+	log *slog.Logger,
+	subscriptions *gin.RouterGroup,
+	deliveries *gin.RouterGroup,
+) {
+	webhookRouter := &WebhookRouter{
+		webhookService: webhookService,
+		logger:         log,
+	}
+
+	subscriptions.POST("", webhookRouter.create)
+	subscriptions.GET("", webhookRouter.list)
+	subscriptions.GET("/:id", webhookRouter.get)
+	subscriptions.PATCH("/:id", webhookRouter.update)
+	subscriptions.DELETE("/:id", webhookRouter.delete)
+	subscriptions.GET("/:id/deliveries", webhookRouter.subscriptionDeliveries)
+
+	deliveries.GET("", webhookRouter.deliveries)
+	deliveries.POST("/:id/redeliver", webhookRouter.redeliver)
+}
+
+func (webhookRouter *WebhookRouter) create(c *gin.Context) {
+	create := webhookDto.CreateSubscription{}
+	if err := c.ShouldBindJSON(&create); err != nil {
+		webhookRouter.logger.Error("WebhookRouter - create: " + err.Error())
+		c.Error(webhookDto.ErrBadSubscription)
+		return
+	}
+
+	ctx := c.Request.Context()
+	subscription, err := webhookRouter.webhookService.Create(ctx, create)
+	if err != nil {
+		webhookRouter.logger.Error("WebhookRouter - create: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (webhookRouter *WebhookRouter) list(c *gin.Context) {
+	ctx := c.Request.Context()
+	subscriptions, err := webhookRouter.webhookService.List(ctx)
+	if err != nil {
+		webhookRouter.logger.Error("WebhookRouter - list: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func (webhookRouter *WebhookRouter) get(c *gin.Context) {
+	ctx := c.Request.Context()
+	subscription, err := webhookRouter.webhookService.Get(ctx, c.Param("id"))
+	if err != nil {
+		webhookRouter.logger.Error("WebhookRouter - get: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (webhookRouter *WebhookRouter) update(c *gin.Context) {
+	update := webhookDto.UpdateSubscription{}
+	if err := c.ShouldBindJSON(&update); err != nil {
+		webhookRouter.logger.Error("WebhookRouter - update: " + err.Error())
+		c.Error(webhookDto.ErrBadSubscription)
+		return
+	}
+
+	ctx := c.Request.Context()
+	subscription, err := webhookRouter.webhookService.Update(ctx, c.Param("id"), update)
+	if err != nil {
+		webhookRouter.logger.Error("WebhookRouter - update: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (webhookRouter *WebhookRouter) delete(c *gin.Context) {
+	ctx := c.Request.Context()
+	if err := webhookRouter.webhookService.Delete(ctx, c.Param("id")); err != nil {
+		webhookRouter.logger.Error("WebhookRouter - delete: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (webhookRouter *WebhookRouter) subscriptionDeliveries(c *gin.Context) {
+	webhookRouter.listDeliveries(c, c.Param("id"))
+}
+
+// deliveries lists deliveries of all subscriptions, ?status=dead gives the dead letters.
+func (webhookRouter *WebhookRouter) deliveries(c *gin.Context) {
+	webhookRouter.listDeliveries(c, c.Query("subscription_id"))
+}
+
+func (webhookRouter *WebhookRouter) listDeliveries(c *gin.Context, subscriptionID string) {
+	filter := webhookDto.DeliveryFilter{
+		SubscriptionID: subscriptionID,
+		Status:         c.Query("status"),
+		Limit:          100,
+	}
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			c.Error(api.ErrBadPagination)
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	ctx := c.Request.Context()
+	deliveries, err := webhookRouter.webhookService.Deliveries(ctx, filter)
+	if err != nil {
+		webhookRouter.logger.Error("WebhookRouter - listDeliveries: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (webhookRouter *WebhookRouter) redeliver(c *gin.Context) {
+	ctx := c.Request.Context()
+	delivery, err := webhookRouter.webhookService.Redeliver(ctx, c.Param("id"))
+	if err != nil {
+		webhookRouter.logger.Error("WebhookRouter - redeliver: " + err.Error())
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,53 @@
+package health
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/elusiv0/medods_test/pkg/resilience"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthRouter struct {
+	breakers []*resilience.Breaker
+	logger   *slog.Logger
+}
+
+type response struct {
+	Status   string            `json:"status"`
+	Breakers map[string]string `json:"breakers"`
+}
+
+func New(
+	breakers []*resilience.Breaker,
+	log *slog.Logger,
+	group *gin.RouterGroup,
+) {
+	healthRouter := &HealthRouter{
+		breakers: breakers,
+		logger:   log,
+	}
+
+	group.GET("", healthRouter.get)
+}
+
+// get reports unavailable while any breaker is open, half open breakers let
+// traffic probe the dependency and count as up.
+func (healthRouter *HealthRouter) get(c *gin.Context) {
+	resp := response{
+		Status:   "up",
+		Breakers: make(map[string]string, len(healthRouter.breakers)),
+	}
+	status := http.StatusOK
+
+	for _, breaker := range healthRouter.breakers {
+		state := breaker.State()
+		resp.Breakers[breaker.Name()] = state.String()
+		if state == resilience.StateOpen {
+			resp.Status = "down"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	c.JSON(status, resp)
+}
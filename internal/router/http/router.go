@@ -6,9 +6,38 @@ import (
 
 	authMiddleware "github.com/elusiv0/medods_test/internal/middleware/auth"
 	errorsMiddleware "github.com/elusiv0/medods_test/internal/middleware/errors"
+	policyMiddleware "github.com/elusiv0/medods_test/internal/middleware/policy"
+	rateLimitMiddleware "github.com/elusiv0/medods_test/internal/middleware/ratelimit"
+	rbacMiddleware "github.com/elusiv0/medods_test/internal/middleware/rbac"
+	requestInfoMiddleware "github.com/elusiv0/medods_test/internal/middleware/requestinfo"
+	scopeMiddleware "github.com/elusiv0/medods_test/internal/middleware/scope"
+	tenantMiddleware "github.com/elusiv0/medods_test/internal/middleware/tenant"
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	auditRouter "github.com/elusiv0/medods_test/internal/router/http/admin/audit"
+	groupRouter "github.com/elusiv0/medods_test/internal/router/http/admin/group"
+	lockoutRouter "github.com/elusiv0/medods_test/internal/router/http/admin/lockout"
+	policyRouter "github.com/elusiv0/medods_test/internal/router/http/admin/policy"
+	roleRouter "github.com/elusiv0/medods_test/internal/router/http/admin/role"
+	sessionRouter "github.com/elusiv0/medods_test/internal/router/http/admin/session"
+	tenantRouter "github.com/elusiv0/medods_test/internal/router/http/admin/tenant"
+	userRouter "github.com/elusiv0/medods_test/internal/router/http/admin/user"
+	webhookRouter "github.com/elusiv0/medods_test/internal/router/http/admin/webhook"
+	healthRouter "github.com/elusiv0/medods_test/internal/router/http/health"
 	authRouter "github.com/elusiv0/medods_test/internal/router/http/v1/auth"
+	forwardAuthRouter "github.com/elusiv0/medods_test/internal/router/http/v1/forwardauth"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
 	authService "github.com/elusiv0/medods_test/internal/service/auth"
+	forwardAuthService "github.com/elusiv0/medods_test/internal/service/forwardauth"
+	groupService "github.com/elusiv0/medods_test/internal/service/group"
+	lockoutService "github.com/elusiv0/medods_test/internal/service/lockout"
+	policyService "github.com/elusiv0/medods_test/internal/service/policy"
+	roleService "github.com/elusiv0/medods_test/internal/service/role"
+	tenantService "github.com/elusiv0/medods_test/internal/service/tenant"
+	userService "github.com/elusiv0/medods_test/internal/service/user"
+	webhookService "github.com/elusiv0/medods_test/internal/service/webhook"
 	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	"github.com/elusiv0/medods_test/pkg/i18n"
+	"github.com/elusiv0/medods_test/pkg/resilience"
 	"github.com/gin-gonic/gin"
 	sloggin "github.com/samber/slog-gin"
 )
@@ -17,29 +46,134 @@ func InitRoutes(
 	log *slog.Logger,
 	tokenM *tokenManager.TokenManager,
 	authS *authService.AuthService,
+	catalog *i18n.Catalog,
+	limiter *rateLimitMiddleware.Limiter,
+	lockoutS *lockoutService.LockoutService,
+	auditS *auditService.AuditService,
+	webhookS *webhookService.WebhookService,
+	userS *userService.UserService,
+	roleS *roleService.RoleService,
+	groupS *groupService.GroupService,
+	tenantS *tenantService.TenantService,
+	policyS *policyService.PolicyService,
+	forwardAuthS *forwardAuthService.ForwardAuthService,
+	tenantHeader string,
+	forwardAuthCookie string,
+	trustedProxies []string,
+	breakers []*resilience.Breaker,
 ) *gin.Engine {
 	router := gin.New()
+	// client address is taken from X-Forwarded-For and X-Real-IP only when the
+	// request comes from one of trusted proxies, otherwise from the peer
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Error("Router - SetTrustedProxies: " + err.Error())
+	}
 
 	router.Use(sloggin.New(log))
-	router.Use(errorsMiddleware.ErrorsMiddleware(errorsMiddleware.InitErrors()))
+	router.Use(errorsMiddleware.ErrorsMiddleware(errorsMiddleware.InitErrors(), catalog))
+	router.Use(requestInfoMiddleware.RequestInfo())
 
 	router.GET("ping", func(c *gin.Context) {
 		c.Status(http.StatusOK)
 	})
+	healthRouter.New(
+		breakers,
+		log,
+		router.Group("health"),
+	)
+
+	tenant := tenantMiddleware.Resolve(tenantS, tenantHeader, log)
 
-	auth := router.Group("api/auth")
+	auth := router.Group("api/auth", tenant)
 	{
 		authRouter.New(
 			authS,
 			log,
 			auth,
+			authRouter.Middlewares{
+				authRouter.SignInRoute: {
+					limiter.Limit(authRouter.SignInRoute, rateLimitMiddleware.QueryKey("uuid")),
+				},
+				authRouter.RefreshRoute: {
+					limiter.Limit(authRouter.RefreshRoute, rateLimitMiddleware.AccessTokenKey(tokenM)),
+				},
+			},
+		)
+		forwardAuthRouter.New(
+			forwardAuthS,
+			forwardAuthCookie,
+			log,
+			auth,
 		)
 	}
-	v1 := router.Group("api/v1", authMiddleware.Auth(tokenM, log))
+	admin := router.Group(
+		"api/admin",
+		tenant,
+		authMiddleware.Auth(tokenM, log),
+		rbacMiddleware.RequirePermission(roleS, log, roleDto.PermissionAdmin),
+	)
 	{
-		v1.GET("/test", func(c *gin.Context) {
-			log.Info("Inside protected end-point")
-		})
+		userRouter.New(
+			userS,
+			log,
+			admin.Group("users"),
+		)
+		roleRouter.New(
+			roleS,
+			log,
+			admin.Group("roles"),
+			admin.Group("roles", tenantMiddleware.RequireDefault(log)),
+			admin.Group("users"),
+		)
+		groupRouter.New(
+			groupS,
+			log,
+			admin.Group("groups"),
+			admin.Group("users"),
+		)
+		tenantRouter.New(
+			tenantS,
+			log,
+			admin.Group("tenants", tenantMiddleware.RequireDefault(log)),
+		)
+		lockoutRouter.New(
+			lockoutS,
+			log,
+			admin.Group("lockouts"),
+		)
+		sessionRouter.New(
+			authS,
+			log,
+			admin.Group("sessions"),
+		)
+		auditRouter.New(
+			auditS,
+			log,
+			admin.Group("audit"),
+		)
+		webhookRouter.New(
+			webhookS,
+			log,
+			admin.Group("webhooks"),
+			admin.Group("webhook-deliveries"),
+		)
+		policyRouter.New(
+			policyS,
+			log,
+			admin.Group("policies"),
+			admin.Group("policies", tenantMiddleware.RequireDefault(log)),
+		)
+	}
+	v1 := router.Group("api/v1", tenant, authMiddleware.Auth(tokenM, log), policyMiddleware.Authorize(policyS))
+	{
+		v1.GET(
+			"/test",
+			scopeMiddleware.RequireScopes(log, "test:read"),
+			rbacMiddleware.RequirePermission(roleS, log, "test:read"),
+			func(c *gin.Context) {
+				log.Info("Inside protected end-point")
+			},
+		)
 	}
 
 	return router
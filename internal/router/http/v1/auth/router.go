@@ -9,8 +9,16 @@ import (
 	authService "github.com/elusiv0/medods_test/internal/service/auth"
 	reqUtils "github.com/elusiv0/medods_test/internal/util/request"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
+const (
+	SignInRoute  = "sign-in"
+	RefreshRoute = "refresh"
+)
+
+type Middlewares map[string][]gin.HandlerFunc
+
 type AuthRouter struct {
 	authService *authService.AuthService
 	logger      *slog.Logger
@@ -20,14 +28,15 @@ func New(
 	authService *authService.AuthService,
 	log *slog.Logger,
 	group *gin.RouterGroup,
+	middlewares Middlewares,
 ) {
 	authRouter := &AuthRouter{
 		logger:      log,
 		authService: authService,
 	}
 
-	group.POST("/sign-in", authRouter.signIn)
-	group.POST("/refresh", authRouter.refresh)
+	group.POST("/"+SignInRoute, append(middlewares[SignInRoute], authRouter.signIn)...)
+	group.POST("/"+RefreshRoute, append(middlewares[RefreshRoute], authRouter.refresh)...)
 }
 
 func (authRouter *AuthRouter) signIn(c *gin.Context) {
@@ -40,7 +49,10 @@ func (authRouter *AuthRouter) signIn(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	tokenResponse, err := authRouter.authService.SignIn(ctx, uuid)
+	tokenResponse, err := authRouter.authService.SignIn(ctx, uuid, tokenDto.ScopeRequest{
+		ClientID: c.Query("client_id"),
+		Scope:    c.Query("scope"),
+	})
 	if err != nil {
 		authRouter.logger.Error("AuthRouter - signIn: ", err.Error())
 		c.Error(err)
@@ -53,7 +65,7 @@ func (authRouter *AuthRouter) signIn(c *gin.Context) {
 func (authRouter *AuthRouter) refresh(c *gin.Context) {
 	refreshReponse := tokenDto.RefreshRequest{}
 
-	err := c.ShouldBindJSON(&refreshReponse)
+	err := c.ShouldBindBodyWith(&refreshReponse, binding.JSON)
 	if err != nil {
 		authRouter.logger.Error("AuthRouter - refresh - ", err.Error())
 		c.Error(api.ErrBadRefreshRequest)
@@ -63,7 +75,7 @@ func (authRouter *AuthRouter) refresh(c *gin.Context) {
 	ctx := c.Request.Context()
 	accessToken := refreshReponse.AccessToken
 	refreshToken := refreshReponse.RefreshToken
-	tokenResponse, err := authRouter.authService.Refresh(ctx, refreshToken, accessToken)
+	tokenResponse, err := authRouter.authService.Refresh(ctx, refreshToken, accessToken, refreshReponse.Scope)
 	if err != nil {
 		authRouter.logger.Error("AuthRouter - refresh - ", err.Error())
 		c.Error(err)
@@ -0,0 +1,110 @@
+package forwardauth
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	forwardAuthDto "github.com/elusiv0/medods_test/internal/model/forwardauth"
+	forwardAuthService "github.com/elusiv0/medods_test/internal/service/forwardauth"
+	"github.com/gin-gonic/gin"
+)
+
+const VerifyRoute = "verify"
+
+type ForwardAuthRouter struct {
+	forwardAuthService *forwardAuthService.ForwardAuthService
+	cookie             string
+	logger             *slog.Logger
+}
+
+// New serves forward authentication for nginx auth_request and Traefik
+// ForwardAuth, token is read from Authorization header or, when cookie is set,
+// from cookie of that name.
+func New(
+	forwardAuthService *forwardAuthService.ForwardAuthService,
+	cookie string,
+	log *slog.Logger,
+	group *gin.RouterGroup,
+) {
+	forwardAuthRouter := &ForwardAuthRouter{
+		forwardAuthService: forwardAuthService,
+		cookie:             cookie,
+		logger:             log,
+	}
+
+	group.GET("/"+VerifyRoute, forwardAuthRouter.verify)
+}
+
+func (forwardAuthRouter *ForwardAuthRouter) verify(c *gin.Context) {
+	token, err := forwardAuthRouter.token(c)
+	if err != nil {
+		forwardAuthRouter.logger.Warn("ForwardAuthRouter - verify: " + err.Error())
+		c.Header("WWW-Authenticate", "Bearer")
+		c.Error(err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	scopes := strings.Fields(strings.ReplaceAll(c.Query("scope"), ",", " "))
+	// subrequest comes from the proxy itself, ClientIP takes the original client
+	// from X-Forwarded-For or X-Real-IP the proxy sets when it is listed in
+	// HTTP_TRUSTEDPROXIES
+	tokenInfo, err := forwardAuthRouter.forwardAuthService.Verify(ctx, forwardAuthDto.Request{
+		Token:     token,
+		Method:    forwarded(c, "X-Forwarded-Method", "X-Original-Method", http.MethodGet),
+		URI:       forwarded(c, "X-Forwarded-Uri", "X-Original-URI", "/"),
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Scopes:    scopes,
+	})
+	if err != nil {
+		forwardAuthRouter.logger.Warn("ForwardAuthRouter - verify: " + err.Error())
+		switch {
+		case errors.Is(err, api.ErrInsufficientScope):
+			c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope="%s"`, strings.Join(scopes, " ")))
+		case !errors.Is(err, api.ErrForbidden):
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		}
+		c.Error(err)
+		return
+	}
+
+	for name, value := range forwardAuthRouter.forwardAuthService.Headers(tokenInfo) {
+		c.Header(name, value)
+	}
+	c.Status(http.StatusOK)
+}
+
+func (forwardAuthRouter *ForwardAuthRouter) token(c *gin.Context) (string, error) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		token := strings.Split(header, " ")
+		if len(token) != 2 || token[0] != "Bearer" || len(token[1]) == 0 {
+			return "", fmt.Errorf("ForwardAuthRouter - token: %w", api.ErrInvalidAccessToken)
+		}
+		return token[1], nil
+	}
+
+	if forwardAuthRouter.cookie != "" {
+		if token, err := c.Cookie(forwardAuthRouter.cookie); err == nil && token != "" {
+			return token, nil
+		}
+	}
+
+	return "", fmt.Errorf("ForwardAuthRouter - token: %w", api.ErrNoAccessTokenFound)
+}
+
+// forwarded returns value of the first present header of original request.
+func forwarded(c *gin.Context, traefik string, nginx string, fallback string) string {
+	if value := c.GetHeader(traefik); value != "" {
+		return value
+	}
+	if value := c.GetHeader(nginx); value != "" {
+		return value
+	}
+
+	return fallback
+}
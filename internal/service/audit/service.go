@@ -0,0 +1,286 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	"github.com/elusiv0/medods_test/internal/repo"
+	"github.com/elusiv0/medods_test/internal/util/hashchain"
+	reqUtils "github.com/elusiv0/medods_test/internal/util/request"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+)
+
+type AuditService struct {
+	auditRepo          repo.AuditRepo
+	tokenManager       *tokenManager.TokenManager
+	checkpointInterval int64
+	logger             *slog.Logger
+	now                func() time.Time
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+var errStopWalk = errors.New("stop walking audit chain")
+
+func New(
+	auditRepo repo.AuditRepo,
+	tokenManager *tokenManager.TokenManager,
+	checkpointInterval int64,
+	log *slog.Logger,
+) *AuditService {
+	return &AuditService{
+		auditRepo:          auditRepo,
+		tokenManager:       tokenManager,
+		checkpointInterval: checkpointInterval,
+		logger:             log,
+		now:                time.Now,
+	}
+}
+
+// Record stores entry enriched with request info from ctx. Failing to write audit
+// must not break the audited operation, so errors are only logged.
+func (auditService *AuditService) Record(ctx context.Context, entry auditDto.Entry) {
+	info := reqUtils.InfoFromContext(ctx)
+	if entry.Actor == "" {
+		entry.Actor = info.Actor
+	}
+	if entry.IP == "" {
+		entry.IP = info.IP
+	}
+	if entry.UserAgent == "" {
+		entry.UserAgent = info.UserAgent
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = auditService.now()
+	}
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Millisecond)
+
+	entry, err := auditService.auditRepo.InsertEntry(ctx, entry)
+	if err != nil {
+		auditService.logger.Error("AuditService - Record: " + err.Error())
+		return
+	}
+
+	if auditService.checkpointInterval > 0 && entry.Seq%auditService.checkpointInterval == 0 {
+		auditService.checkpoint(ctx, entry)
+	}
+	auditService.sign(ctx, entry)
+}
+
+// RecordResult records entry with outcome and reason derived from err.
+func (auditService *AuditService) RecordResult(ctx context.Context, entry auditDto.Entry, err error) {
+	entry.Outcome = auditDto.OutcomeSuccess
+	if err != nil {
+		entry.Outcome = auditDto.OutcomeFailure
+		entry.Reason = reason(err)
+	}
+
+	auditService.Record(ctx, entry)
+}
+
+func (auditService *AuditService) List(ctx context.Context, filter auditDto.Filter) (auditDto.Page, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+
+	entries, err := auditService.auditRepo.ListEntries(ctx, filter)
+	if err != nil {
+		return auditDto.Page{}, fmt.Errorf("AuditService - List: %w", err)
+	}
+
+	page := auditDto.Page{
+		Entries: entries,
+	}
+	if len(entries) == filter.Limit {
+		page.NextCursor = entries[len(entries)-1].ID
+	}
+
+	return page, nil
+}
+
+// Export writes matching entries to w as JSON lines.
+func (auditService *AuditService) Export(ctx context.Context, filter auditDto.Filter, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	err := auditService.auditRepo.ExportEntries(ctx, filter, func(entry auditDto.Entry) error {
+		return encoder.Encode(entry)
+	})
+	if err != nil {
+		return fmt.Errorf("AuditService - Export: %w", err)
+	}
+
+	return nil
+}
+
+// Verify walks the whole chain from the first entry and stops at the first broken link.
+// Every AUDIT_CHECKPOINTINTERVAL-th entry must have a signed checkpoint and the
+// signed head must point at the last entry, so that removed entries at the tail
+// of the chain are detected too.
+func (auditService *AuditService) Verify(ctx context.Context) (auditDto.VerifyReport, error) {
+	checkpoints, err := auditService.auditRepo.ListCheckpoints(ctx)
+	if err != nil {
+		return auditDto.VerifyReport{}, fmt.Errorf("AuditService - Verify: %w", err)
+	}
+	anchors := make(map[int64]auditDto.Checkpoint, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		anchors[checkpoint.Seq] = checkpoint
+	}
+
+	report := auditDto.VerifyReport{
+		Valid: true,
+	}
+	prev := auditDto.Entry{}
+
+	err = auditService.auditRepo.WalkChain(ctx, func(entry auditDto.Entry) error {
+		if entry.Seq == 0 {
+			report.Unchained++
+			return nil
+		}
+
+		switch {
+		case entry.Seq != prev.Seq+1:
+			broken(&report, entry.Seq, fmt.Sprintf("expected seq %d, got %d", prev.Seq+1, entry.Seq))
+		case entry.PrevHash != prev.Hash:
+			broken(&report, entry.Seq, "prev_hash does not match hash of previous entry")
+		case hashchain.Hash(entry) != entry.Hash:
+			broken(&report, entry.Seq, "entry content does not match its hash")
+		}
+		if !report.Valid {
+			return errStopWalk
+		}
+
+		checkpoint, ok := anchors[entry.Seq]
+		if !ok && auditService.checkpointInterval > 0 && entry.Seq%auditService.checkpointInterval == 0 {
+			broken(&report, entry.Seq, "signed checkpoint is missing")
+			return errStopWalk
+		}
+		if ok {
+			if !auditService.tokenManager.VerifySignature(checkpointPayload(checkpoint.Seq, checkpoint.Hash), checkpoint.Signature) {
+				broken(&report, entry.Seq, "checkpoint signature is invalid")
+			} else if checkpoint.Hash != entry.Hash {
+				broken(&report, entry.Seq, "entry hash does not match signed checkpoint")
+			}
+			if !report.Valid {
+				return errStopWalk
+			}
+			report.Checkpoints++
+		}
+
+		report.Checked++
+		report.LastSeq = entry.Seq
+		prev = entry
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return report, fmt.Errorf("AuditService - Verify: %w", err)
+	}
+
+	if report.Valid {
+		for seq := range anchors {
+			if seq > report.LastSeq {
+				broken(&report, seq, "signed checkpoint refers to missing entry")
+				break
+			}
+		}
+	}
+	if report.Valid && report.LastSeq > 0 {
+		if err := auditService.verifyHead(ctx, &report, prev); err != nil {
+			return report, fmt.Errorf("AuditService - Verify: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func (auditService *AuditService) verifyHead(ctx context.Context, report *auditDto.VerifyReport, last auditDto.Entry) error {
+	head, err := auditService.auditRepo.GetHead(ctx)
+	if err != nil {
+		if errors.Is(err, auditDto.ErrHeadNotFound) {
+			broken(report, last.Seq, "signed chain head is missing")
+			return nil
+		}
+		return err
+	}
+	report.HeadSeq = head.Seq
+
+	switch {
+	case !auditService.tokenManager.VerifySignature(headPayload(head.Seq, head.Hash), head.Signature):
+		broken(report, head.Seq, "chain head signature is invalid")
+	case head.Seq > last.Seq:
+		broken(report, last.Seq+1, fmt.Sprintf("chain is truncated, signed head is at seq %d", head.Seq))
+	case head.Seq < last.Seq:
+		broken(report, head.Seq+1, "entries after signed head are not signed")
+	case head.Hash != last.Hash:
+		broken(report, head.Seq, "last entry hash does not match signed head")
+	}
+
+	return nil
+}
+
+func (auditService *AuditService) checkpoint(ctx context.Context, entry auditDto.Entry) {
+	checkpoint := auditDto.Checkpoint{
+		Seq:       entry.Seq,
+		Hash:      entry.Hash,
+		Signature: auditService.tokenManager.Sign(checkpointPayload(entry.Seq, entry.Hash)),
+		CreatedAt: auditService.now().UTC(),
+	}
+
+	if err := auditService.auditRepo.InsertCheckpoint(ctx, checkpoint); err != nil {
+		auditService.logger.Error("AuditService - checkpoint: " + err.Error())
+	}
+}
+
+// sign moves signed chain head to entry, failure is only logged like the one
+// of checkpoint and is reported by Verify.
+func (auditService *AuditService) sign(ctx context.Context, entry auditDto.Entry) {
+	head := auditDto.Checkpoint{
+		Seq:       entry.Seq,
+		Hash:      entry.Hash,
+		Signature: auditService.tokenManager.Sign(headPayload(entry.Seq, entry.Hash)),
+		CreatedAt: auditService.now().UTC(),
+	}
+
+	if err := auditService.auditRepo.SetHead(ctx, head); err != nil {
+		auditService.logger.Error("AuditService - sign: " + err.Error())
+	}
+}
+
+func broken(report *auditDto.VerifyReport, seq int64, reason string) {
+	report.Valid = false
+	report.BrokenSeq = seq
+	report.Reason = reason
+}
+
+func checkpointPayload(seq int64, hash string) []byte {
+	return []byte(fmt.Sprintf("%d:%s", seq, hash))
+}
+
+// headPayload differs from checkpoint one, so that a checkpoint signature can
+// not be passed off as signed head of truncated chain.
+func headPayload(seq int64, hash string) []byte {
+	return []byte(fmt.Sprintf("head:%d:%s", seq, hash))
+}
+
+// reason returns message of innermost wrapped error, which is the domain error.
+func reason(err error) string {
+	for {
+		inner := errors.Unwrap(err)
+		if inner == nil {
+			return err.Error()
+		}
+		err = inner
+	}
+}
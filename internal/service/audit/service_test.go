@@ -0,0 +1,187 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	"github.com/elusiv0/medods_test/internal/util/hashchain"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+)
+
+// memoryAuditRepo keeps the chain in seq order and links entries the way Mongo
+// repository does.
+type memoryAuditRepo struct {
+	entries     []auditDto.Entry
+	checkpoints []auditDto.Checkpoint
+	head        *auditDto.Checkpoint
+}
+
+func (repo *memoryAuditRepo) InsertEntry(ctx context.Context, entry auditDto.Entry) (auditDto.Entry, error) {
+	if len(repo.entries) > 0 {
+		last := repo.entries[len(repo.entries)-1]
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	} else {
+		entry.Seq = 1
+	}
+	entry.Hash = hashchain.Hash(entry)
+	repo.entries = append(repo.entries, entry)
+
+	return entry, nil
+}
+
+func (repo *memoryAuditRepo) ListEntries(ctx context.Context, filter auditDto.Filter) ([]auditDto.Entry, error) {
+	return repo.entries, nil
+}
+
+func (repo *memoryAuditRepo) ExportEntries(ctx context.Context, filter auditDto.Filter, fn func(auditDto.Entry) error) error {
+	return repo.WalkChain(ctx, fn)
+}
+
+func (repo *memoryAuditRepo) WalkChain(ctx context.Context, fn func(auditDto.Entry) error) error {
+	for _, entry := range repo.entries {
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *memoryAuditRepo) InsertCheckpoint(ctx context.Context, checkpoint auditDto.Checkpoint) error {
+	repo.checkpoints = append(repo.checkpoints, checkpoint)
+	return nil
+}
+
+func (repo *memoryAuditRepo) ListCheckpoints(ctx context.Context) ([]auditDto.Checkpoint, error) {
+	return repo.checkpoints, nil
+}
+
+func (repo *memoryAuditRepo) SetHead(ctx context.Context, head auditDto.Checkpoint) error {
+	repo.head = &head
+	return nil
+}
+
+func (repo *memoryAuditRepo) GetHead(ctx context.Context) (auditDto.Checkpoint, error) {
+	if repo.head == nil {
+		return auditDto.Checkpoint{}, auditDto.ErrHeadNotFound
+	}
+
+	return *repo.head, nil
+}
+
+// newTestService records count entries into a chain checkpointed every 3rd entry.
+func newTestService(t *testing.T, count int) (*AuditService, *memoryAuditRepo) {
+	t.Helper()
+
+	repo := &memoryAuditRepo{}
+	service := &AuditService{
+		auditRepo:          repo,
+		tokenManager:       tokenManager.New(time.Minute, "secret"),
+		checkpointInterval: 3,
+		logger:             slog.New(slog.NewTextHandler(io.Discard, nil)),
+		now:                func() time.Time { return time.Unix(1714564800, 0) },
+	}
+	for i := 0; i < count; i++ {
+		service.Record(context.Background(), auditDto.Entry{
+			Type:    auditDto.TypeSignIn,
+			Subject: "u-1",
+			Outcome: auditDto.OutcomeSuccess,
+		})
+	}
+
+	return service, repo
+}
+
+func TestVerifyValidChain(t *testing.T) {
+	service, _ := newTestService(t, 7)
+
+	report, err := service.Verify(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := auditDto.VerifyReport{Checked: 7, Checkpoints: 2, LastSeq: 7, HeadSeq: 7, Valid: true}
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	for name, tc := range map[string]struct {
+		tamper    func(repo *memoryAuditRepo)
+		brokenSeq int64
+	}{
+		"changed content": {
+			tamper:    func(repo *memoryAuditRepo) { repo.entries[1].Subject = "u-2" },
+			brokenSeq: 2,
+		},
+		"rehashed entry": {
+			tamper: func(repo *memoryAuditRepo) {
+				repo.entries[1].Subject = "u-2"
+				repo.entries[1].Hash = hashchain.Hash(repo.entries[1])
+			},
+			brokenSeq: 3,
+		},
+		"rehashed chain": {
+			tamper: func(repo *memoryAuditRepo) {
+				repo.entries[3].Subject = "u-2"
+				for i := 3; i < len(repo.entries); i++ {
+					repo.entries[i].PrevHash = repo.entries[i-1].Hash
+					repo.entries[i].Hash = hashchain.Hash(repo.entries[i])
+				}
+			},
+			brokenSeq: 6,
+		},
+		"removed entry": {
+			tamper:    func(repo *memoryAuditRepo) { repo.entries = append(repo.entries[:2], repo.entries[3:]...) },
+			brokenSeq: 4,
+		},
+		"removed checkpoint": {
+			tamper:    func(repo *memoryAuditRepo) { repo.checkpoints = repo.checkpoints[1:] },
+			brokenSeq: 3,
+		},
+		"forged checkpoint": {
+			tamper:    func(repo *memoryAuditRepo) { repo.checkpoints[0].Signature = repo.head.Signature },
+			brokenSeq: 3,
+		},
+		"truncated tail": {
+			tamper:    func(repo *memoryAuditRepo) { repo.entries = repo.entries[:5] },
+			brokenSeq: 6,
+		},
+		"truncated to checkpoint": {
+			tamper:    func(repo *memoryAuditRepo) { repo.entries = repo.entries[:6] },
+			brokenSeq: 7,
+		},
+		"truncated past checkpoint": {
+			tamper:    func(repo *memoryAuditRepo) { repo.entries = repo.entries[:4] },
+			brokenSeq: 6,
+		},
+		"missing head": {
+			tamper:    func(repo *memoryAuditRepo) { repo.head = nil },
+			brokenSeq: 7,
+		},
+		"unsigned tail": {
+			tamper: func(repo *memoryAuditRepo) {
+				repo.InsertEntry(context.Background(), auditDto.Entry{Type: auditDto.TypeSignIn})
+			},
+			brokenSeq: 8,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, repo := newTestService(t, 7)
+			tc.tamper(repo)
+
+			report, err := service.Verify(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Valid || report.BrokenSeq != tc.brokenSeq {
+				t.Errorf("report = %+v, want broken at seq %d", report, tc.brokenSeq)
+			}
+		})
+	}
+}
@@ -5,103 +5,553 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	tokenMapper "github.com/elusiv0/medods_test/internal/mapper/token"
 	"github.com/elusiv0/medods_test/internal/model/api"
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	eventDto "github.com/elusiv0/medods_test/internal/model/event"
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	outboxDto "github.com/elusiv0/medods_test/internal/model/outbox"
 	tokenDto "github.com/elusiv0/medods_test/internal/model/token"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
 	"github.com/elusiv0/medods_test/internal/repo"
-	tokenRepository "github.com/elusiv0/medods_test/internal/repo/token"
-	userRepository "github.com/elusiv0/medods_test/internal/repo/user"
-	hashing "github.com/elusiv0/medods_test/internal/util/hash"
+	tokenModel "github.com/elusiv0/medods_test/internal/repo/token/model"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	groupService "github.com/elusiv0/medods_test/internal/service/group"
+	lockoutService "github.com/elusiv0/medods_test/internal/service/lockout"
+	outboxService "github.com/elusiv0/medods_test/internal/service/outbox"
+	roleService "github.com/elusiv0/medods_test/internal/service/role"
+	tenantService "github.com/elusiv0/medods_test/internal/service/tenant"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
 	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"github.com/elusiv0/medods_test/pkg/scope"
+	uuidUtil "github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// Policy limits refresh tokens. RefreshLifeTime is counted from issue when
+// SlidingRenewal is on and from session start otherwise, no session outlives
+// SessionMaxAge whatever the renewal, zero disables the limit. Clients lists
+// scopes each client may request, DefaultScopes are requested by sign in
+// without client and scope. AccessLifeTime overrides lifetime configured in token
+// manager when set, GroupsClaim puts ids of effective groups of user into access
+// token. Policy of tenant overrides lifetimes, SessionMaxAge, Clients and
+// DefaultScopes it sets, SlidingRenewal and GroupsClaim are set for all tenants.
+type Policy struct {
+	AccessLifeTime  time.Duration
+	RefreshLifeTime time.Duration
+	SessionMaxAge   time.Duration
+	SlidingRenewal  bool
+	Clients         map[string]scope.Set
+	DefaultScopes   scope.Set
+	GroupsClaim     bool
+}
+
 type AuthService struct {
-	userRepo     repo.UserRepo
-	tokenRepo    repo.TokenRepo
-	logger       *slog.Logger
-	tokenManager *tokenManager.TokenManager
+	userRepo       repo.UserRepo
+	tokenRepo      repo.TokenRepo
+	logger         *slog.Logger
+	tokenManager   *tokenManager.TokenManager
+	lockoutService *lockoutService.LockoutService
+	auditService   *auditService.AuditService
+	outboxService  *outboxService.OutboxService
+	roleService    *roleService.RoleService
+	groupService   *groupService.GroupService
+	tenantService  *tenantService.TenantService
+	transactor     repo.Transactor
+	hooks          []eventDto.Hook
+	now            func() time.Time
+
+	mu     sync.RWMutex
+	policy Policy
 }
 
 func New(
-	userRepo *userRepository.UserRepo,
-	tokenRepo *tokenRepository.TokenRepo,
+	userRepo repo.UserRepo,
+	tokenRepo repo.TokenRepo,
 	log *slog.Logger,
 	tokenManager *tokenManager.TokenManager,
+	lockoutService *lockoutService.LockoutService,
+	auditService *auditService.AuditService,
+	outboxService *outboxService.OutboxService,
+	roleService *roleService.RoleService,
+	groupService *groupService.GroupService,
+	tenantService *tenantService.TenantService,
+	transactor *mongoClient.MongoClient,
+	policy Policy,
 ) *AuthService {
 	return &AuthService{
-		userRepo:     userRepo,
-		tokenRepo:    tokenRepo,
-		logger:       log,
-		tokenManager: tokenManager,
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		logger:         log,
+		tokenManager:   tokenManager,
+		lockoutService: lockoutService,
+		auditService:   auditService,
+		outboxService:  outboxService,
+		roleService:    roleService,
+		groupService:   groupService,
+		tenantService:  tenantService,
+		transactor:     transactor,
+		policy:         policy,
+		now:            time.Now,
 	}
 }
 
-func (authService *AuthService) SignIn(ctx context.Context, uuid string) (tokenDto.TokenResponse, error) {
-	_, err := authService.userRepo.GetUserByUUID(ctx, uuid)
+// SetPolicy replaces policy, sessions already issued keep their expiry.
+func (authService *AuthService) SetPolicy(policy Policy) {
+	authService.mu.Lock()
+	defer authService.mu.Unlock()
+
+	authService.policy = policy
+}
+
+// AddHook subscribes hook to events of successful authentication state changes.
+func (authService *AuthService) AddHook(hook eventDto.Hook) {
+	authService.hooks = append(authService.hooks, hook)
+}
+
+// SignIn issues tokens granting requested scopes the client may request and
+// roles of user permit, scopes out of reach are left out.
+func (authService *AuthService) SignIn(
+	ctx context.Context,
+	uuid string,
+	request tokenDto.ScopeRequest,
+) (_ tokenDto.TokenResponse, err error) {
+	defer func() {
+		authService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeSignIn,
+			Subject: uuid,
+		}, err)
+	}()
+
+	user, err := authService.userRepo.GetUserByUUID(ctx, uuid)
+	if err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - SignIn: %w", err)
+	}
+	if user.Disabled {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - SignIn: %w", userDto.ErrUserDisabled)
+	}
+
+	if err := authService.lockoutService.Check(ctx, uuid); err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - SignIn: %w", err)
+	}
+
+	policy, err := authService.currentPolicy(ctx)
+	if err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - SignIn: %w", err)
+	}
+	requested, err := scope.Parse(request.Scope)
+	if err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - SignIn: %w: %s", tokenDto.ErrInvalidScope, err.Error())
+	}
+	effective, err := authService.groupService.Effective(ctx, user)
+	if err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - SignIn: %w", err)
+	}
+	granted, err := authService.grantScope(ctx, policy, effective, request.ClientID, requested, nil)
 	if err != nil {
 		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - SignIn: %w", err)
 	}
 
-	tokens, err := authService.generateTokens(ctx, uuid)
+	var tokens tokenDto.TokenResponse
+	err = authService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var (
+			refreshId primitive.ObjectID
+			err       error
+		)
+		tokens, refreshId, err = authService.generateTokens(ctx, policy, user, effective, request.ClientID, granted, tokenModel.Token{})
+		if err != nil {
+			return err
+		}
+
+		return authService.outboxService.Enqueue(ctx, outboxDto.TypeTokensIssued, uuid, map[string]string{
+			"session_id": refreshId.Hex(),
+		})
+	})
 	if err != nil {
-		return tokens, fmt.Errorf("AuthService - SignIn: %w", err)
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - SignIn: %w", err)
 	}
+	authService.resetFailures(ctx, uuid)
+	authService.emit(ctx, eventDto.TypeSignIn, uuid)
 
 	return tokens, nil
 }
 
+// Refresh rotates tokens of session keeping its scope, requested scope may only
+// narrow it.
 func (authService *AuthService) Refresh(
 	ctx context.Context,
 	refreshToken string,
 	accessToken string,
-) (tokenDto.TokenResponse, error) {
+	requestedScope string,
+) (_ tokenDto.TokenResponse, err error) {
 	tokenInfo, err := authService.tokenManager.ValidateJWT(accessToken)
+	defer func() {
+		authService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeRefresh,
+			Subject: tokenInfo.UUID,
+		}, err)
+	}()
+
 	if err != nil && !(errors.Is(err, api.ErrAccessTokenExpired)) {
 		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", err)
 	}
+	if tenantUtil.Normalize(tokenInfo.Tenant) != tenantUtil.FromContext(ctx) {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", api.ErrTenantMismatch)
+	}
+
+	uuid := tokenInfo.UUID
 
-	if ok, err := authService.tokenManager.CheckTokenMatch(tokenInfo, refreshToken); !ok {
+	if err := authService.lockoutService.Check(ctx, uuid); err != nil {
 		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", err)
 	}
 
-	if err := authService.tokenRepo.DeleteToken(ctx, tokenInfo.RefreshId); err != nil {
+	previous, err := authService.verifyRefreshToken(ctx, tokenInfo, refreshToken)
+	if err != nil {
+		if errors.Is(err, api.ErrTokenMismatch) || errors.Is(err, tokenDto.ErrRefreshTokenNotRegistered) {
+			authService.registerFailure(ctx, uuid)
+		}
 		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", err)
 	}
 
-	uuid := tokenInfo.UUID
+	// roles and groups are read again so that assignment changes reach the new access token
+	user, err := authService.userRepo.GetUserByUUID(ctx, uuid)
+	if err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", err)
+	}
+	if user.Disabled {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", userDto.ErrUserDisabled)
+	}
 
-	tokens, err := authService.generateTokens(ctx, uuid)
+	policy, err := authService.currentPolicy(ctx)
+	if err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", err)
+	}
+	requested, err := scope.Parse(requestedScope)
+	if err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w: %s", tokenDto.ErrInvalidScope, err.Error())
+	}
+	// sessions started before scopes were introduced are treated as new sign in
+	var previousScope scope.Set
+	if previous.Scope != nil {
+		previousScope = scope.Set(previous.Scope)
+	}
+	effective, err := authService.groupService.Effective(ctx, user)
+	if err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", err)
+	}
+	granted, err := authService.grantScope(ctx, policy, effective, previous.ClientID, requested, previousScope)
+	if err != nil {
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", err)
+	}
 
+	var tokens tokenDto.TokenResponse
+	err = authService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var (
+			refreshId primitive.ObjectID
+			err       error
+		)
+		tokens, refreshId, err = authService.generateTokens(ctx, policy, user, effective, previous.ClientID, granted, previous)
+		if err != nil {
+			return err
+		}
+
+		return authService.outboxService.Enqueue(ctx, outboxDto.TypeTokensRotated, uuid, map[string]string{
+			"previous_session_id": tokenInfo.SessionId.Hex(),
+			"session_id":          refreshId.Hex(),
+		})
+	})
 	if err != nil {
-		return tokens, fmt.Errorf("AuthService - Refresh: %w", err)
+		if errors.Is(err, tokenDto.ErrRefreshTokenNotRegistered) {
+			authService.registerFailure(ctx, uuid)
+		}
+		return tokenDto.TokenResponse{}, fmt.Errorf("AuthService - Refresh: %w", err)
 	}
+	authService.resetFailures(ctx, uuid)
+	authService.emit(ctx, eventDto.TypeRefresh, uuid)
 
 	return tokens, nil
 }
 
-func (authService *AuthService) generateTokens(ctx context.Context, uuid string) (tokenDto.TokenResponse, error) {
-	refreshToken, err := authService.tokenManager.NewRefreshToken()
+// RevokeSessions deletes every refresh token of user, returns amount of revoked sessions.
+func (authService *AuthService) RevokeSessions(ctx context.Context, uuid string) (_ int64, err error) {
+	defer func() {
+		authService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeRevocation,
+			Subject: uuid,
+		}, err)
+	}()
+
+	var revoked int64
+	err = authService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		revoked, err = authService.tokenRepo.DeleteUserTokens(ctx, uuid)
+		if err != nil {
+			return err
+		}
+
+		return authService.outboxService.Enqueue(ctx, outboxDto.TypeTokensRevoked, uuid, map[string]string{
+			"revoked": strconv.FormatInt(revoked, 10),
+		})
+	})
+	if err != nil {
+		return 0, fmt.Errorf("AuthService - RevokeSessions: %w", err)
+	}
+	authService.emit(ctx, eventDto.TypeRevocation, uuid)
+
+	return revoked, nil
+}
+
+// Sessions lists active sessions of user.
+func (authService *AuthService) Sessions(ctx context.Context, uuid string) ([]tokenDto.Session, error) {
+	tokens, err := authService.tokenRepo.ListUserTokens(ctx, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("AuthService - Sessions: %w", err)
+	}
+
+	now := authService.now()
+	sessions := make([]tokenDto.Session, 0, len(tokens))
+	for _, token := range tokens {
+		if now.Before(token.ExpiresAt) {
+			sessions = append(sessions, tokenMapper.ModelToSession(token))
+		}
+	}
+
+	return sessions, nil
+}
+
+// registerFailure must not hide original authentication error, so it only logs its own.
+func (authService *AuthService) registerFailure(ctx context.Context, uuid string) {
+	locked, err := authService.lockoutService.RegisterFailure(ctx, uuid)
+	if err != nil {
+		authService.logger.Error("AuthService - registerFailure: " + err.Error())
+		return
+	}
+	if locked {
+		authService.emit(ctx, eventDto.TypeLockout, uuid)
+	}
+}
+
+func (authService *AuthService) resetFailures(ctx context.Context, uuid string) {
+	if err := authService.lockoutService.Reset(ctx, uuid); err != nil {
+		authService.logger.Error("AuthService - resetFailures: " + err.Error())
+	}
+}
+
+// verifyRefreshToken checks that refresh token belongs to the session of access token,
+// has not expired and its secret matches the stored digest.
+func (authService *AuthService) verifyRefreshToken(
+	ctx context.Context,
+	tokenInfo tokenManager.TokenInfo,
+	refreshToken string,
+) (tokenModel.Token, error) {
+	sessionId, secret, err := authService.tokenManager.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return tokenModel.Token{}, fmt.Errorf("verifyRefreshToken: %w", err)
+	}
+	if sessionId != tokenInfo.SessionId {
+		return tokenModel.Token{}, fmt.Errorf("verifyRefreshToken: %w", api.ErrTokenMismatch)
+	}
+
+	token, err := authService.tokenRepo.GetToken(ctx, sessionId)
 	if err != nil {
-		return tokenDto.TokenResponse{}, fmt.Errorf("generateTokens: %w", err)
+		return tokenModel.Token{}, fmt.Errorf("verifyRefreshToken: %w", err)
+	}
+	if token.UserUUID != tokenInfo.UUID {
+		return tokenModel.Token{}, fmt.Errorf("verifyRefreshToken: %w", api.ErrTokenMismatch)
+	}
+
+	if err := authService.tokenManager.CheckRefreshSecret(secret, token.Digest); err != nil {
+		return tokenModel.Token{}, fmt.Errorf("verifyRefreshToken: %w", err)
+	}
+
+	// TTL monitor removes expired documents only once a minute
+	if !authService.now().Before(token.ExpiresAt) {
+		return tokenModel.Token{}, fmt.Errorf("verifyRefreshToken: %w", tokenDto.ErrRefreshTokenExpired)
+	}
+
+	return token, nil
+}
+
+// generateTokens stores new refresh token, atomically replacing previous one when it is
+// set, the new token continues session of the previous. ctx is expected to carry the
+// transaction of the whole state change.
+func (authService *AuthService) generateTokens(
+	ctx context.Context,
+	policy Policy,
+	user userDto.User,
+	effective groupDto.Effective,
+	clientID string,
+	granted scope.Set,
+	previous tokenModel.Token,
+) (tokenDto.TokenResponse, primitive.ObjectID, error) {
+	now := authService.now().UTC()
+	token := tokenModel.Token{
+		ID:               primitive.NewObjectID(),
+		UserUUID:         user.UUID,
+		ClientID:         clientID,
+		Scope:            granted,
+		SessionStartedAt: now,
+	}
+	if !previous.ID.IsZero() {
+		token.SessionStartedAt = previous.SessionStartedAt
 	}
+	token.ExpiresAt = expiresAt(policy, token.SessionStartedAt, now)
 
-	hashedRefresh, err := hashing.CryptToken(refreshToken)
+	refreshToken, digest, err := authService.tokenManager.NewRefreshToken(token.ID)
 	if err != nil {
-		return tokenDto.TokenResponse{}, fmt.Errorf("generateTokens: %w", err)
+		return tokenDto.TokenResponse{}, primitive.NilObjectID, fmt.Errorf("generateTokens: %w", err)
 	}
+	token.Digest = digest
 
-	refreshId, err := authService.tokenRepo.InsertToken(ctx, hashedRefresh, uuid)
+	if previous.ID.IsZero() {
+		err = authService.tokenRepo.InsertToken(ctx, token)
+	} else {
+		err = authService.tokenRepo.RotateToken(ctx, previous.ID, token)
+	}
 	if err != nil {
-		return tokenDto.TokenResponse{}, fmt.Errorf("generateTokens: %w", err)
+		return tokenDto.TokenResponse{}, primitive.NilObjectID, fmt.Errorf("generateTokens: %w", err)
 	}
 
-	accessToken, err := authService.tokenManager.NewJWTToken(hashedRefresh, uuid, refreshId)
+	tokenInfo := tokenManager.TokenInfo{
+		UUID:      user.UUID,
+		Tenant:    tenantUtil.FromContext(ctx),
+		SessionId: token.ID,
+		Roles:     effective.Roles,
+		Scope:     granted.String(),
+		ClientID:  clientID,
+	}
+	if policy.GroupsClaim {
+		tokenInfo.Groups = effective.Groups
+	}
+	accessToken, err := authService.tokenManager.NewJWTToken(tokenInfo, policy.AccessLifeTime)
 	if err != nil {
-		return tokenDto.TokenResponse{}, fmt.Errorf("generateTokens: %w", err)
+		return tokenDto.TokenResponse{}, primitive.NilObjectID, fmt.Errorf("generateTokens: %w", err)
 	}
 
 	return tokenDto.TokenResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
-	}, nil
+		Scope:        granted.String(),
+	}, token.ID, nil
+}
+
+// grantScope intersects requested scopes with the ones client may request and
+// effective roles of user permit. Nothing requested means every scope of previous grant
+// on refresh, every scope of client or default scopes on sign in. On refresh
+// requested scopes must be covered by previous grant.
+func (authService *AuthService) grantScope(
+	ctx context.Context,
+	policy Policy,
+	effective groupDto.Effective,
+	clientID string,
+	requested scope.Set,
+	previous scope.Set,
+) (scope.Set, error) {
+	allowed, ok := policy.Clients[clientID]
+	if clientID != "" && !ok {
+		return nil, fmt.Errorf("grantScope - %s: %w", clientID, tokenDto.ErrInvalidClient)
+	}
+
+	explicit := len(requested) > 0
+	switch {
+	case explicit && previous != nil:
+		for _, token := range requested {
+			if !previous.Covers(token) {
+				return nil, fmt.Errorf("grantScope - %s: %w", token, tokenDto.ErrInvalidScope)
+			}
+		}
+	case previous != nil:
+		requested = previous
+	case !explicit && clientID != "":
+		requested = allowed
+	case !explicit:
+		requested = policy.DefaultScopes
+	}
+
+	granted := make(scope.Set, 0, len(requested))
+	for _, token := range requested {
+		if clientID != "" && !allowed.Covers(token) {
+			continue
+		}
+		permitted, err := authService.roleService.Allowed(ctx, effective.Roles, token)
+		if err != nil {
+			return nil, fmt.Errorf("grantScope: %w", err)
+		}
+		if permitted {
+			granted = append(granted, token)
+		}
+	}
+	if explicit && len(granted) == 0 {
+		return nil, fmt.Errorf("grantScope: %w", tokenDto.ErrInvalidScope)
+	}
+
+	return granted, nil
+}
+
+// currentPolicy returns policy with overrides of tenant of ctx applied.
+func (authService *AuthService) currentPolicy(ctx context.Context) (Policy, error) {
+	authService.mu.RLock()
+	policy := authService.policy
+	authService.mu.RUnlock()
+
+	overrides, err := authService.tenantService.Policy(ctx)
+	if err != nil {
+		return Policy{}, fmt.Errorf("currentPolicy: %w", err)
+	}
+	if overrides.AccessLifeTime > 0 {
+		policy.AccessLifeTime = time.Duration(overrides.AccessLifeTime)
+	}
+	if overrides.RefreshLifeTime > 0 {
+		policy.RefreshLifeTime = time.Duration(overrides.RefreshLifeTime)
+	}
+	if overrides.SessionMaxAge > 0 {
+		policy.SessionMaxAge = time.Duration(overrides.SessionMaxAge)
+	}
+	if len(overrides.Clients) > 0 {
+		if policy.Clients, err = scope.ParseClients(overrides.Clients); err != nil {
+			return Policy{}, fmt.Errorf("currentPolicy: %w", err)
+		}
+	}
+	if len(overrides.DefaultScopes) > 0 {
+		if policy.DefaultScopes, err = scope.Parse(strings.Join(overrides.DefaultScopes, " ")); err != nil {
+			return Policy{}, fmt.Errorf("currentPolicy: %w", err)
+		}
+	}
+
+	return policy, nil
+}
+
+func expiresAt(policy Policy, sessionStartedAt, issuedAt time.Time) time.Time {
+	expiresAt := sessionStartedAt.Add(policy.RefreshLifeTime)
+	if policy.SlidingRenewal {
+		expiresAt = issuedAt.Add(policy.RefreshLifeTime)
+	}
+
+	if policy.SessionMaxAge > 0 {
+		if deadline := sessionStartedAt.Add(policy.SessionMaxAge); deadline.Before(expiresAt) {
+			expiresAt = deadline
+		}
+	}
+
+	return expiresAt
+}
+
+func (authService *AuthService) emit(ctx context.Context, eventType string, subject string) {
+	event := eventDto.Event{
+		ID:         uuidUtil.NewString(),
+		Tenant:     tenantUtil.FromContext(ctx),
+		Type:       eventType,
+		Subject:    subject,
+		OccurredAt: time.Now().UTC(),
+	}
+
+	for _, hook := range authService.hooks {
+		hook.Handle(ctx, event)
+	}
 }
@@ -0,0 +1,399 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	lockoutDto "github.com/elusiv0/medods_test/internal/model/lockout"
+	outboxDto "github.com/elusiv0/medods_test/internal/model/outbox"
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
+	tokenDto "github.com/elusiv0/medods_test/internal/model/token"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	"github.com/elusiv0/medods_test/internal/repo"
+	tokenModel "github.com/elusiv0/medods_test/internal/repo/token/model"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	groupService "github.com/elusiv0/medods_test/internal/service/group"
+	lockoutService "github.com/elusiv0/medods_test/internal/service/lockout"
+	outboxService "github.com/elusiv0/medods_test/internal/service/outbox"
+	roleService "github.com/elusiv0/medods_test/internal/service/role"
+	tenantService "github.com/elusiv0/medods_test/internal/service/tenant"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	"github.com/elusiv0/medods_test/pkg/scope"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryTokenRepo rotates tokens the way Mongo repository does: the previous
+// token is consumed and the new one stored at once, of concurrent rotations of
+// the same token only one succeeds.
+type memoryTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[primitive.ObjectID]tokenModel.Token
+}
+
+func (repo *memoryTokenRepo) GetToken(ctx context.Context, id primitive.ObjectID) (tokenModel.Token, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	token, ok := repo.tokens[id]
+	if !ok {
+		return tokenModel.Token{}, tokenDto.ErrRefreshTokenNotRegistered
+	}
+
+	return token, nil
+}
+
+func (repo *memoryTokenRepo) ListUserTokens(ctx context.Context, uuid string) ([]tokenModel.Token, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	tokens := make([]tokenModel.Token, 0)
+	for _, token := range repo.tokens {
+		if token.UserUUID == uuid {
+			tokens = append(tokens, token)
+		}
+	}
+
+	return tokens, nil
+}
+
+func (repo *memoryTokenRepo) InsertToken(ctx context.Context, token tokenModel.Token) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.tokens[token.ID] = token
+	return nil
+}
+
+func (repo *memoryTokenRepo) RotateToken(ctx context.Context, id primitive.ObjectID, token tokenModel.Token) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	consumed, ok := repo.tokens[id]
+	if !ok || consumed.UserUUID != token.UserUUID {
+		return tokenDto.ErrRefreshTokenNotRegistered
+	}
+	delete(repo.tokens, id)
+	repo.tokens[token.ID] = token
+
+	return nil
+}
+
+func (repo *memoryTokenRepo) DeleteToken(ctx context.Context, id primitive.ObjectID) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if _, ok := repo.tokens[id]; !ok {
+		return tokenDto.ErrRefreshTokenNotRegistered
+	}
+	delete(repo.tokens, id)
+
+	return nil
+}
+
+func (repo *memoryTokenRepo) DeleteUserTokens(ctx context.Context, uuid string) (int64, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var deleted int64
+	for id, token := range repo.tokens {
+		if token.UserUUID == uuid {
+			delete(repo.tokens, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// Repositories below implement only what sign in and refresh use, the embedded
+// interface is left nil.
+
+type userRepo struct {
+	repo.UserRepo
+	users map[string]userDto.User
+}
+
+func (repo userRepo) GetUserByUUID(ctx context.Context, uuid string) (userDto.User, error) {
+	user, ok := repo.users[uuid]
+	if !ok {
+		return userDto.User{}, userDto.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+type lockoutRepo struct {
+	repo.LockoutRepo
+	mu       sync.Mutex
+	failures int
+}
+
+func (repo *lockoutRepo) GetLockout(ctx context.Context, uuid string) (lockoutDto.Lockout, error) {
+	return lockoutDto.Lockout{}, lockoutDto.ErrLockoutNotFound
+}
+
+func (repo *lockoutRepo) RegisterFailure(ctx context.Context, uuid string, at time.Time, window time.Duration) (lockoutDto.Lockout, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.failures++
+	return lockoutDto.Lockout{UUID: uuid, Failures: repo.failures, LastFailureAt: at}, nil
+}
+
+func (repo *lockoutRepo) DeleteLockout(ctx context.Context, uuid string) error {
+	return lockoutDto.ErrLockoutNotFound
+}
+
+type auditRepo struct {
+	repo.AuditRepo
+}
+
+func (auditRepo) InsertEntry(ctx context.Context, entry auditDto.Entry) (auditDto.Entry, error) {
+	return entry, nil
+}
+
+func (auditRepo) SetHead(ctx context.Context, head auditDto.Checkpoint) error {
+	return nil
+}
+
+type outboxRepo struct {
+	repo.OutboxRepo
+}
+
+func (outboxRepo) InsertMessage(ctx context.Context, message outboxDto.Message) (string, error) {
+	return primitive.NewObjectID().Hex(), nil
+}
+
+type roleRepo struct {
+	repo.RoleRepo
+}
+
+func (roleRepo) ListRoles(ctx context.Context) ([]roleDto.Role, error) {
+	return []roleDto.Role{{Name: "editor", Permissions: []string{"profile:*"}}}, nil
+}
+
+type groupRepo struct {
+	repo.GroupRepo
+}
+
+func (groupRepo) ListUserGroups(ctx context.Context, uuid string) ([]groupDto.Group, error) {
+	return nil, nil
+}
+
+type tenantRepo struct {
+	repo.TenantRepo
+}
+
+func (tenantRepo) ListTenants(ctx context.Context) ([]tenantDto.Tenant, error) {
+	return nil, nil
+}
+
+type transactor struct{}
+
+func (transactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newTestService(t *testing.T) (*AuthService, *memoryTokenRepo, *lockoutRepo) {
+	t.Helper()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tokenM := tokenManager.New(time.Minute, "secret")
+	tokens := &memoryTokenRepo{tokens: map[primitive.ObjectID]tokenModel.Token{}}
+	lockouts := &lockoutRepo{}
+	users := userRepo{users: map[string]userDto.User{
+		"u-1": {UUID: "u-1", Roles: []string{"editor"}},
+	}}
+
+	audit := auditService.New(auditRepo{}, tokenM, 0, logger)
+	roles := roleService.New(roleRepo{}, users, groupRepo{}, audit, nil, time.Minute, logger)
+	service := New(
+		users,
+		tokens,
+		logger,
+		tokenM,
+		lockoutService.New(lockouts, audit, lockoutService.Policy{}, logger),
+		audit,
+		outboxService.New(outboxRepo{}, nil, outboxService.Policy{}, logger),
+		roles,
+		groupService.New(groupRepo{}, roleRepo{}, users, roles, audit, nil, logger),
+		tenantService.New(tenantRepo{}, audit, time.Minute, true, logger),
+		nil,
+		Policy{
+			RefreshLifeTime: time.Hour,
+			Clients:         map[string]scope.Set{"app": {"admin:users", "profile:read", "profile:write"}},
+		},
+	)
+	service.transactor = transactor{}
+
+	return service, tokens, lockouts
+}
+
+func signIn(t *testing.T, service *AuthService, request tokenDto.ScopeRequest) tokenDto.TokenResponse {
+	t.Helper()
+
+	tokens, err := service.SignIn(context.Background(), "u-1", request)
+	if err != nil {
+		t.Fatalf("SignIn: %v", err)
+	}
+
+	return tokens
+}
+
+func TestRefreshRotatesOnce(t *testing.T) {
+	service, tokenRepo, lockouts := newTestService(t)
+	issued := signIn(t, service, tokenDto.ScopeRequest{})
+
+	const attempts = 8
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		rotated []tokenDto.TokenResponse
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			tokens, err := service.Refresh(context.Background(), issued.RefreshToken, issued.AccessToken, "")
+			if err != nil {
+				if !errors.Is(err, tokenDto.ErrRefreshTokenNotRegistered) {
+					t.Errorf("Refresh: %v, want ErrRefreshTokenNotRegistered", err)
+				}
+				return
+			}
+			mu.Lock()
+			rotated = append(rotated, tokens)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(rotated) != 1 {
+		t.Fatalf("%d of concurrent refreshes succeeded, want 1", len(rotated))
+	}
+	if sessions, _ := tokenRepo.ListUserTokens(context.Background(), "u-1"); len(sessions) != 1 {
+		t.Errorf("%d sessions are left, want 1", len(sessions))
+	}
+	if lockouts.failures != attempts-1 {
+		t.Errorf("%d failures are registered, want %d", lockouts.failures, attempts-1)
+	}
+
+	if _, err := service.Refresh(context.Background(), rotated[0].RefreshToken, rotated[0].AccessToken, ""); err != nil {
+		t.Errorf("refresh with rotated tokens: %v", err)
+	}
+}
+
+func TestRefreshRejectsReplay(t *testing.T) {
+	service, _, lockouts := newTestService(t)
+	issued := signIn(t, service, tokenDto.ScopeRequest{})
+
+	rotated, err := service.Refresh(context.Background(), issued.RefreshToken, issued.AccessToken, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.Refresh(context.Background(), issued.RefreshToken, issued.AccessToken, "")
+	if !errors.Is(err, tokenDto.ErrRefreshTokenNotRegistered) {
+		t.Fatalf("replayed refresh: %v, want ErrRefreshTokenNotRegistered", err)
+	}
+	if lockouts.failures != 1 {
+		t.Errorf("replay registered %d failures, want 1", lockouts.failures)
+	}
+
+	other := signIn(t, service, tokenDto.ScopeRequest{})
+	if _, err := service.Refresh(context.Background(), other.RefreshToken, rotated.AccessToken, ""); err == nil {
+		t.Error("refresh token of another session is accepted")
+	}
+}
+
+func TestSignInGrantsScope(t *testing.T) {
+	for name, tc := range map[string]struct {
+		request tokenDto.ScopeRequest
+		want    string
+		err     error
+	}{
+		"client scopes permitted by roles": {
+			request: tokenDto.ScopeRequest{ClientID: "app"},
+			want:    "profile:read profile:write",
+		},
+		"requested scopes out of reach are left out": {
+			request: tokenDto.ScopeRequest{ClientID: "app", Scope: "admin:users profile:read profile:delete"},
+			want:    "profile:read",
+		},
+		"nothing requested in reach": {
+			request: tokenDto.ScopeRequest{ClientID: "app", Scope: "admin:users"},
+			err:     tokenDto.ErrInvalidScope,
+		},
+		"unknown client": {
+			request: tokenDto.ScopeRequest{ClientID: "other"},
+			err:     tokenDto.ErrInvalidClient,
+		},
+		"no client": {
+			request: tokenDto.ScopeRequest{Scope: "profile:delete"},
+			want:    "profile:delete",
+		},
+		"no client nor scope": {
+			request: tokenDto.ScopeRequest{},
+			want:    "",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			service, _, _ := newTestService(t)
+
+			tokens, err := service.SignIn(context.Background(), "u-1", tc.request)
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("err = %v, want %v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tokens.Scope != tc.want {
+				t.Errorf("scope = %q, want %q", tokens.Scope, tc.want)
+			}
+		})
+	}
+}
+
+func TestRefreshNarrowsScope(t *testing.T) {
+	service, _, _ := newTestService(t)
+	tokens := signIn(t, service, tokenDto.ScopeRequest{ClientID: "app"})
+
+	for i, step := range []struct {
+		scope string
+		want  string
+		err   error
+	}{
+		{scope: "", want: "profile:read profile:write"},
+		{scope: "profile:read", want: "profile:read"},
+		{scope: "profile:write", err: tokenDto.ErrInvalidScope},
+		{scope: "profile:*", err: tokenDto.ErrInvalidScope},
+		{scope: "", want: "profile:read"},
+	} {
+		rotated, err := service.Refresh(context.Background(), tokens.RefreshToken, tokens.AccessToken, step.scope)
+		if step.err != nil {
+			if !errors.Is(err, step.err) {
+				t.Fatalf("step %d: err = %v, want %v", i, err, step.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if rotated.Scope != step.want {
+			t.Errorf("step %d: scope = %q, want %q", i, rotated.Scope, step.want)
+		}
+		tokens = rotated
+	}
+}
@@ -0,0 +1,246 @@
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	fixtureDto "github.com/elusiv0/medods_test/internal/model/fixture"
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	"github.com/elusiv0/medods_test/internal/repo"
+	tenantService "github.com/elusiv0/medods_test/internal/service/tenant"
+	envUtil "github.com/elusiv0/medods_test/internal/util/env"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	uuidUtil "github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+type FixtureService struct {
+	userRepo    repo.UserRepo
+	roleRepo    repo.RoleRepo
+	tenantRepo  repo.TenantRepo
+	environment string
+	logger      *slog.Logger
+}
+
+func New(
+	userRepo repo.UserRepo,
+	roleRepo repo.RoleRepo,
+	tenantRepo repo.TenantRepo,
+	environment string,
+	log *slog.Logger,
+) *FixtureService {
+	return &FixtureService{
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		tenantRepo:  tenantRepo,
+		environment: environment,
+		logger:      log,
+	}
+}
+
+// Load reads fixtures from .json, .yaml or .yml file, or from every such file of
+// directory in name order.
+func (fixtureService *FixtureService) Load(path string) (fixtureDto.Fixtures, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fixtureDto.Fixtures{}, fmt.Errorf("FixtureService - Load: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return fixtureDto.Fixtures{}, fmt.Errorf("FixtureService - Load: %w", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() && isFixtureFile(entry.Name()) {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	fixtures := fixtureDto.Fixtures{}
+	for _, file := range files {
+		loaded, err := readFile(file)
+		if err != nil {
+			return fixtureDto.Fixtures{}, fmt.Errorf("FixtureService - Load: %w", err)
+		}
+		fixtures.Tenants = append(fixtures.Tenants, loaded.Tenants...)
+		fixtures.Roles = append(fixtures.Roles, loaded.Roles...)
+		fixtures.Users = append(fixtures.Users, loaded.Users...)
+	}
+
+	return fixtures, nil
+}
+
+// Apply upserts fixtures, applying the same fixtures again changes nothing.
+func (fixtureService *FixtureService) Apply(
+	ctx context.Context,
+	fixtures fixtureDto.Fixtures,
+) (fixtureDto.Result, error) {
+	if !envUtil.IsNonProduction(fixtureService.environment) {
+		return fixtureDto.Result{}, fmt.Errorf("FixtureService - Apply: %w", fixtureDto.ErrProductionEnvironment)
+	}
+	if err := validate(fixtures); err != nil {
+		return fixtureDto.Result{}, fmt.Errorf("FixtureService - Apply: %w", err)
+	}
+
+	result := fixtureDto.Result{}
+	for _, tenant := range fixtures.Tenants {
+		hosts := tenant.Hosts
+		if hosts == nil {
+			hosts = []string{}
+		}
+		created, updated, err := fixtureService.tenantRepo.UpsertTenant(ctx, tenantDto.Tenant{
+			ID:        tenant.ID,
+			Name:      tenant.Name,
+			Hosts:     hosts,
+			Policy:    tenant.Policy,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return result, fmt.Errorf("FixtureService - Apply: %w", err)
+		}
+		count(&result, created, updated)
+	}
+	for _, role := range fixtures.Roles {
+		created, updated, err := fixtureService.roleRepo.UpsertRole(ctx, roleDto.Role{
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: role.Permissions,
+			CreatedAt:   time.Now().UTC(),
+		})
+		if err != nil {
+			return result, fmt.Errorf("FixtureService - Apply: %w", err)
+		}
+		count(&result, created, updated)
+	}
+	for _, user := range fixtures.Users {
+		ctx := tenantUtil.WithTenant(ctx, tenantUtil.Normalize(user.Tenant))
+		created, updated, err := fixtureService.userRepo.UpsertUser(ctx, userDto.User{
+			UUID:     user.UUID,
+			Name:     user.Name,
+			Disabled: user.Disabled,
+			Roles:    user.Roles,
+		})
+		if err != nil {
+			return result, fmt.Errorf("FixtureService - Apply: %w", err)
+		}
+		count(&result, created, updated)
+	}
+	fixtureService.logger.Info(
+		"FixtureService: fixtures applied",
+		slog.Int("created", result.Created),
+		slog.Int("updated", result.Updated),
+		slog.Int("unchanged", result.Unchanged),
+	)
+
+	return result, nil
+}
+
+// ApplyPath loads fixtures from path and applies them.
+func (fixtureService *FixtureService) ApplyPath(ctx context.Context, path string) (fixtureDto.Result, error) {
+	fixtures, err := fixtureService.Load(path)
+	if err != nil {
+		return fixtureDto.Result{}, err
+	}
+
+	return fixtureService.Apply(ctx, fixtures)
+}
+
+func readFile(file string) (fixtureDto.Fixtures, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fixtureDto.Fixtures{}, err
+	}
+
+	fixtures := fixtureDto.Fixtures{}
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		decoder := json.NewDecoder(strings.NewReader(string(data)))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&fixtures)
+	} else {
+		decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&fixtures)
+	}
+	if err != nil {
+		return fixtureDto.Fixtures{}, fmt.Errorf("%s: %w: %s", file, fixtureDto.ErrBadFixture, err.Error())
+	}
+
+	return fixtures, nil
+}
+
+func validate(fixtures fixtureDto.Fixtures) error {
+	tenants := make(map[string]struct{}, len(fixtures.Tenants))
+	for i, tenant := range fixtures.Tenants {
+		if tenant.ID == "" {
+			return fmt.Errorf("%w: tenant %d has no id", fixtureDto.ErrBadFixture, i)
+		}
+		if !tenantService.ValidPolicy(tenant.Policy) {
+			return fmt.Errorf("%w: tenant %s has invalid policy", fixtureDto.ErrBadFixture, tenant.ID)
+		}
+		if _, ok := tenants[tenant.ID]; ok {
+			return fmt.Errorf("%w: tenant %s is listed twice", fixtureDto.ErrBadFixture, tenant.ID)
+		}
+		tenants[tenant.ID] = struct{}{}
+	}
+
+	roles := make(map[string]struct{}, len(fixtures.Roles))
+	for i, role := range fixtures.Roles {
+		if role.Name == "" {
+			return fmt.Errorf("%w: role %d has no name", fixtureDto.ErrBadFixture, i)
+		}
+		if _, ok := roles[role.Name]; ok {
+			return fmt.Errorf("%w: role %s is listed twice", fixtureDto.ErrBadFixture, role.Name)
+		}
+		roles[role.Name] = struct{}{}
+	}
+
+	seen := make(map[string]struct{}, len(fixtures.Users))
+	for i, user := range fixtures.Users {
+		if _, err := uuidUtil.Parse(user.UUID); err != nil {
+			return fmt.Errorf("%w: user %d has invalid uuid %q", fixtureDto.ErrBadFixture, i, user.UUID)
+		}
+		if user.Name == "" {
+			return fmt.Errorf("%w: user %s has no name", fixtureDto.ErrBadFixture, user.UUID)
+		}
+		if _, ok := seen[user.UUID]; ok {
+			return fmt.Errorf("%w: user %s is listed twice", fixtureDto.ErrBadFixture, user.UUID)
+		}
+		seen[user.UUID] = struct{}{}
+	}
+
+	return nil
+}
+
+func count(result *fixtureDto.Result, created, updated bool) {
+	switch {
+	case created:
+		result.Created++
+	case updated:
+		result.Updated++
+	default:
+		result.Unchanged++
+	}
+}
+
+func isFixtureFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,240 @@
+package forwardauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	forwardAuthDto "github.com/elusiv0/medods_test/internal/model/forwardauth"
+	policyDto "github.com/elusiv0/medods_test/internal/model/policy"
+	policyService "github.com/elusiv0/medods_test/internal/service/policy"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	"github.com/elusiv0/medods_test/pkg/scope"
+)
+
+// Headers names response headers carrying claims of verified token, empty name
+// leaves claim out.
+type Headers struct {
+	User   string
+	Tenant string
+	Roles  string
+	Groups string
+	Scope  string
+	Client string
+}
+
+// Policy sets how long positive decisions are cached, zero CacheTTL disables
+// caching.
+type Policy struct {
+	CacheTTL  time.Duration
+	CacheSize int
+	Headers   Headers
+}
+
+type cached struct {
+	tokenInfo tokenManager.TokenInfo
+	expiresAt time.Time
+}
+
+type ForwardAuthService struct {
+	tokenManager  *tokenManager.TokenManager
+	policyService *policyService.PolicyService
+	logger        *slog.Logger
+	now           func() time.Time
+
+	mu     sync.Mutex
+	policy Policy
+	cache  map[string]cached
+}
+
+func New(
+	tokenManager *tokenManager.TokenManager,
+	policyService *policyService.PolicyService,
+	policy Policy,
+	log *slog.Logger,
+) *ForwardAuthService {
+	return &ForwardAuthService{
+		tokenManager:  tokenManager,
+		policyService: policyService,
+		logger:        log,
+		now:           time.Now,
+		policy:        policy,
+		cache:         make(map[string]cached),
+	}
+}
+
+// SetPolicy applies new policy and drops cached decisions, so that rotated
+// secrets take effect at once.
+func (forwardAuthService *ForwardAuthService) SetPolicy(policy Policy) {
+	forwardAuthService.mu.Lock()
+	defer forwardAuthService.mu.Unlock()
+
+	forwardAuthService.policy = policy
+	forwardAuthService.cache = make(map[string]cached)
+}
+
+// Verify validates access token of request for tenant of ctx, checks required
+// scopes and evaluates access policies against forwarded method and uri. Uri is
+// matched to route templates of policies, so that they apply to forwarded
+// requests as they do to api/v1 ones; uri no template matches is evaluated as
+// its own route and only policies on it, its prefix or any route apply. Valid
+// tokens are cached for CacheTTL but never past their expiry, scopes and
+// policies are checked on every call.
+func (forwardAuthService *ForwardAuthService) Verify(
+	ctx context.Context,
+	request forwardAuthDto.Request,
+) (tokenManager.TokenInfo, error) {
+	tenant := tenantUtil.FromContext(ctx)
+	tokenInfo, err := forwardAuthService.validate(tenant, request.Token)
+	if err != nil {
+		return tokenManager.TokenInfo{}, fmt.Errorf("ForwardAuthService - Verify: %w", err)
+	}
+
+	granted, err := scope.Parse(tokenInfo.Scope)
+	if err != nil {
+		forwardAuthService.logger.Error("ForwardAuthService - Verify: " + err.Error())
+	}
+	for _, required := range request.Scopes {
+		if !granted.Covers(required) {
+			return tokenManager.TokenInfo{}, fmt.Errorf("ForwardAuthService - Verify: %w", api.ErrInsufficientScope)
+		}
+	}
+
+	uri, err := url.ParseRequestURI(request.URI)
+	if err != nil {
+		return tokenManager.TokenInfo{}, fmt.Errorf("ForwardAuthService - Verify: %w", api.ErrForbidden)
+	}
+	// dot segments are resolved, so that they do not slip past route templates
+	requestPath := path.Clean(uri.Path)
+	if strings.HasSuffix(uri.Path, "/") && requestPath != "/" {
+		requestPath += "/"
+	}
+
+	route, params, ok := forwardAuthService.policyService.Route(requestPath)
+	if !ok {
+		route = requestPath
+	}
+	attributes := forwardAuthService.policyService.Attributes(
+		tokenInfo,
+		request.Method,
+		route,
+		requestPath,
+		request.IP,
+		request.UserAgent,
+	)
+	for name, value := range params {
+		attributes.Set(policyDto.AttributeResourcePrefix+name, value)
+	}
+	// owner of resource is user named by uuid path or query parameter
+	if owner := params["uuid"]; owner != "" {
+		attributes.Set(policyDto.AttributeResourceOwner, owner)
+	} else if owner := uri.Query().Get("uuid"); owner != "" {
+		attributes.Set(policyDto.AttributeResourceOwner, owner)
+	}
+
+	decision := forwardAuthService.policyService.Decide(ctx, policyDto.Request{
+		Method:     request.Method,
+		Route:      route,
+		Path:       requestPath,
+		Attributes: attributes,
+	})
+	if !decision.Allowed && decision.Enforced {
+		return tokenManager.TokenInfo{}, fmt.Errorf("ForwardAuthService - Verify: %w", api.ErrForbidden)
+	}
+
+	return tokenInfo, nil
+}
+
+// Headers returns response headers carrying claims of tokenInfo.
+func (forwardAuthService *ForwardAuthService) Headers(tokenInfo tokenManager.TokenInfo) map[string]string {
+	forwardAuthService.mu.Lock()
+	names := forwardAuthService.policy.Headers
+	forwardAuthService.mu.Unlock()
+
+	headers := make(map[string]string)
+	set := func(name string, value string) {
+		if name != "" && value != "" {
+			headers[name] = value
+		}
+	}
+	set(names.User, tokenInfo.UUID)
+	set(names.Tenant, tenantUtil.Normalize(tokenInfo.Tenant))
+	set(names.Roles, strings.Join(tokenInfo.Roles, ","))
+	set(names.Groups, strings.Join(tokenInfo.Groups, ","))
+	set(names.Scope, tokenInfo.Scope)
+	set(names.Client, tokenInfo.ClientID)
+
+	return headers
+}
+
+func (forwardAuthService *ForwardAuthService) validate(tenant string, token string) (tokenManager.TokenInfo, error) {
+	if token == "" {
+		return tokenManager.TokenInfo{}, fmt.Errorf("validate: %w", api.ErrNoAccessTokenFound)
+	}
+
+	digest := sha256.Sum256([]byte(tenant + "\x00" + token))
+	key := hex.EncodeToString(digest[:])
+	now := forwardAuthService.now()
+
+	forwardAuthService.mu.Lock()
+	entry, ok := forwardAuthService.cache[key]
+	policy := forwardAuthService.policy
+	forwardAuthService.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.tokenInfo, nil
+	}
+
+	tokenInfo, err := forwardAuthService.tokenManager.ValidateJWT(token)
+	if err != nil {
+		return tokenManager.TokenInfo{}, fmt.Errorf("validate: %w", err)
+	}
+	if tenantUtil.Normalize(tokenInfo.Tenant) != tenant {
+		forwardAuthService.logger.Warn(
+			"ForwardAuthService: token of another tenant",
+			slog.String("uuid", tokenInfo.UUID),
+			slog.String("token_tenant", tokenInfo.Tenant),
+			slog.String("tenant", tenant),
+		)
+		return tokenManager.TokenInfo{}, fmt.Errorf("validate: %w", api.ErrTenantMismatch)
+	}
+
+	if policy.CacheTTL > 0 {
+		expiresAt := now.Add(policy.CacheTTL)
+		// token is already verified, its claims are only read here
+		if _, claims, err := forwardAuthService.tokenManager.Inspect(token); err == nil && claims.ExpiresAt != nil {
+			if claims.ExpiresAt.Time.Before(expiresAt) {
+				expiresAt = claims.ExpiresAt.Time
+			}
+		}
+		forwardAuthService.store(key, cached{tokenInfo: tokenInfo, expiresAt: expiresAt}, now)
+	}
+
+	return tokenInfo, nil
+}
+
+func (forwardAuthService *ForwardAuthService) store(key string, entry cached, now time.Time) {
+	forwardAuthService.mu.Lock()
+	defer forwardAuthService.mu.Unlock()
+
+	if len(forwardAuthService.cache) >= forwardAuthService.policy.CacheSize {
+		for key, entry := range forwardAuthService.cache {
+			if !now.Before(entry.expiresAt) {
+				delete(forwardAuthService.cache, key)
+			}
+		}
+		// every entry is still fresh, start over rather than grow past the limit
+		if len(forwardAuthService.cache) >= forwardAuthService.policy.CacheSize {
+			forwardAuthService.cache = make(map[string]cached)
+		}
+	}
+	forwardAuthService.cache[key] = entry
+}
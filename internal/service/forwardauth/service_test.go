@@ -0,0 +1,139 @@
+package forwardauth
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+)
+
+func newTestService(now *time.Time, policy Policy) (*ForwardAuthService, *tokenManager.TokenManager) {
+	tokenM := tokenManager.New(time.Minute, "secret")
+	service := New(tokenM, nil, policy, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	service.now = func() time.Time { return *now }
+
+	return service, tokenM
+}
+
+func newToken(t *testing.T, tokenM *tokenManager.TokenManager, tenant string) string {
+	t.Helper()
+
+	token, err := tokenM.NewJWTToken(tokenManager.TokenInfo{UUID: "u-1", Tenant: tenant}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestValidateCachesUntilTokenExpiry(t *testing.T) {
+	now := time.Now()
+	service, tokenM := newTestService(&now, Policy{CacheTTL: time.Hour, CacheSize: 10})
+	token := newToken(t, tokenM, "")
+
+	if _, err := service.validate(tenantUtil.DefaultID, token); err != nil {
+		t.Fatal(err)
+	}
+	// token stops verifying, only cached decision can accept it now
+	tokenM.SetSecrets("rotated", nil)
+
+	now = now.Add(30 * time.Second)
+	if tokenInfo, err := service.validate(tenantUtil.DefaultID, token); err != nil || tokenInfo.UUID != "u-1" {
+		t.Fatalf("cached token: %+v, %v", tokenInfo, err)
+	}
+
+	now = now.Add(31 * time.Second)
+	if _, err := service.validate(tenantUtil.DefaultID, token); err == nil {
+		t.Error("token is served from cache past its expiry")
+	}
+}
+
+func TestValidateCacheTTL(t *testing.T) {
+	now := time.Now()
+	service, tokenM := newTestService(&now, Policy{CacheTTL: 10 * time.Second, CacheSize: 10})
+	token := newToken(t, tokenM, "")
+
+	if _, err := service.validate(tenantUtil.DefaultID, token); err != nil {
+		t.Fatal(err)
+	}
+	tokenM.SetSecrets("rotated", nil)
+
+	now = now.Add(10 * time.Second)
+	if _, err := service.validate(tenantUtil.DefaultID, token); err == nil {
+		t.Error("token is served from cache past CacheTTL")
+	}
+}
+
+func TestValidateWithoutCache(t *testing.T) {
+	now := time.Now()
+	service, tokenM := newTestService(&now, Policy{})
+	token := newToken(t, tokenM, "")
+
+	if _, err := service.validate(tenantUtil.DefaultID, token); err != nil {
+		t.Fatal(err)
+	}
+	if len(service.cache) != 0 {
+		t.Error("zero CacheTTL caches tokens")
+	}
+	if _, err := service.validate(tenantUtil.DefaultID, ""); !errors.Is(err, api.ErrNoAccessTokenFound) {
+		t.Errorf("err = %v, want ErrNoAccessTokenFound", err)
+	}
+}
+
+func TestValidateTenantMismatch(t *testing.T) {
+	now := time.Now()
+	service, tokenM := newTestService(&now, Policy{CacheTTL: time.Hour, CacheSize: 10})
+
+	for _, tc := range []struct {
+		tokenTenant string
+		tenant      string
+		err         error
+	}{
+		{tokenTenant: "", tenant: tenantUtil.DefaultID},
+		{tokenTenant: tenantUtil.DefaultID, tenant: tenantUtil.DefaultID},
+		{tokenTenant: "acme", tenant: "acme"},
+		{tokenTenant: "", tenant: "acme", err: api.ErrTenantMismatch},
+		{tokenTenant: "acme", tenant: tenantUtil.DefaultID, err: api.ErrTenantMismatch},
+		{tokenTenant: "acme", tenant: "globex", err: api.ErrTenantMismatch},
+	} {
+		token := newToken(t, tokenM, tc.tokenTenant)
+		if _, err := service.validate(tc.tenant, token); !errors.Is(err, tc.err) {
+			t.Errorf("token of %q for %q: err = %v, want %v", tc.tokenTenant, tc.tenant, err, tc.err)
+		}
+	}
+}
+
+func TestValidateCacheIsPerTenant(t *testing.T) {
+	now := time.Now()
+	service, tokenM := newTestService(&now, Policy{CacheTTL: time.Hour, CacheSize: 10})
+	token := newToken(t, tokenM, "acme")
+
+	if _, err := service.validate("acme", token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.validate("globex", token); !errors.Is(err, api.ErrTenantMismatch) {
+		t.Errorf("token cached for acme: err = %v, want ErrTenantMismatch", err)
+	}
+}
+
+func TestStoreKeepsCacheSize(t *testing.T) {
+	now := time.Now()
+	service, _ := newTestService(&now, Policy{CacheTTL: time.Hour, CacheSize: 2})
+
+	service.store("stale", cached{expiresAt: now}, now)
+	service.store("fresh", cached{expiresAt: now.Add(time.Minute)}, now)
+	service.store("new", cached{expiresAt: now.Add(time.Minute)}, now)
+	if _, ok := service.cache["stale"]; ok || len(service.cache) != 2 {
+		t.Errorf("expired entry is kept: %v", service.cache)
+	}
+
+	service.store("newest", cached{expiresAt: now.Add(time.Minute)}, now)
+	if len(service.cache) > 2 {
+		t.Errorf("cache grows past its size: %d entries", len(service.cache))
+	}
+}
@@ -0,0 +1,364 @@
+package group
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	"github.com/elusiv0/medods_test/internal/repo"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	roleService "github.com/elusiv0/medods_test/internal/service/role"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	uuidUtil "github.com/google/uuid"
+)
+
+var (
+	namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+)
+
+// GroupService manages groups of users of a tenant. Groups grant roles to their
+// members and may be nested into parent groups, whose roles they inherit. Nesting
+// never forms a cycle, a change that would form one is rejected.
+type GroupService struct {
+	groupRepo    repo.GroupRepo
+	roleRepo     repo.RoleRepo
+	userRepo     repo.UserRepo
+	roleService  *roleService.RoleService
+	auditService *auditService.AuditService
+	transactor   repo.Transactor
+	logger       *slog.Logger
+	now          func() time.Time
+}
+
+func New(
+	groupRepo repo.GroupRepo,
+	roleRepo repo.RoleRepo,
+	userRepo repo.UserRepo,
+	roleService *roleService.RoleService,
+	auditService *auditService.AuditService,
+	transactor *mongoClient.MongoClient,
+	log *slog.Logger,
+) *GroupService {
+	return &GroupService{
+		groupRepo:    groupRepo,
+		roleRepo:     roleRepo,
+		userRepo:     userRepo,
+		roleService:  roleService,
+		auditService: auditService,
+		transactor:   transactor,
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+func (groupService *GroupService) Create(ctx context.Context, create groupDto.CreateGroup) (_ groupDto.Group, err error) {
+	defer func() {
+		groupService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: create.Name,
+			Action:  "create_group",
+		}, err)
+	}()
+
+	group := groupDto.Group{
+		ID:          uuidUtil.NewString(),
+		Name:        strings.TrimSpace(create.Name),
+		Description: create.Description,
+		Roles:       normalize(create.Roles),
+		Parents:     normalize(create.Parents),
+		Members:     []string{},
+		CreatedAt:   groupService.now().UTC(),
+	}
+	if !namePattern.MatchString(group.Name) {
+		return groupDto.Group{}, fmt.Errorf("GroupService - Create: %w", groupDto.ErrBadGroup)
+	}
+	if err := groupService.validate(ctx, group); err != nil {
+		return groupDto.Group{}, fmt.Errorf("GroupService - Create: %w", err)
+	}
+
+	if err := groupService.groupRepo.InsertGroup(ctx, group); err != nil {
+		return groupDto.Group{}, fmt.Errorf("GroupService - Create: %w", err)
+	}
+
+	return group, nil
+}
+
+func (groupService *GroupService) Get(ctx context.Context, id string) (groupDto.Group, error) {
+	group, err := groupService.groupRepo.GetGroup(ctx, id)
+	if err != nil {
+		return groupDto.Group{}, fmt.Errorf("GroupService - Get: %w", err)
+	}
+
+	return group, nil
+}
+
+func (groupService *GroupService) List(ctx context.Context) ([]groupDto.Group, error) {
+	groups, err := groupService.groupRepo.ListGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GroupService - List: %w", err)
+	}
+
+	return groups, nil
+}
+
+func (groupService *GroupService) Update(
+	ctx context.Context,
+	id string,
+	update groupDto.UpdateGroup,
+) (_ groupDto.Group, err error) {
+	defer func() {
+		groupService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: id,
+			Action:  "update_group",
+		}, err)
+	}()
+
+	group, err := groupService.groupRepo.GetGroup(ctx, id)
+	if err != nil {
+		return groupDto.Group{}, fmt.Errorf("GroupService - Update: %w", err)
+	}
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if !namePattern.MatchString(name) {
+			return groupDto.Group{}, fmt.Errorf("GroupService - Update: %w", groupDto.ErrBadGroup)
+		}
+		update.Name = &name
+	}
+	if update.Roles != nil {
+		roles := normalize(*update.Roles)
+		update.Roles = &roles
+		group.Roles = roles
+	}
+	if update.Parents != nil {
+		parents := normalize(*update.Parents)
+		update.Parents = &parents
+		group.Parents = parents
+	}
+	if err := groupService.validate(ctx, group); err != nil {
+		return groupDto.Group{}, fmt.Errorf("GroupService - Update: %w", err)
+	}
+
+	group, err = groupService.groupRepo.UpdateGroup(ctx, id, update)
+	if err != nil {
+		return groupDto.Group{}, fmt.Errorf("GroupService - Update: %w", err)
+	}
+
+	return group, nil
+}
+
+// Delete removes group, groups nested into it lose it as parent.
+func (groupService *GroupService) Delete(ctx context.Context, id string) (err error) {
+	defer func() {
+		groupService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: id,
+			Action:  "delete_group",
+		}, err)
+	}()
+
+	err = groupService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := groupService.groupRepo.DeleteGroup(ctx, id); err != nil {
+			return err
+		}
+
+		_, err := groupService.groupRepo.RemoveParent(ctx, id)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("GroupService - Delete: %w", err)
+	}
+
+	return nil
+}
+
+// AddMember puts user into group, the roles it grants get into access tokens
+// issued from now on.
+func (groupService *GroupService) AddMember(ctx context.Context, id string, uuid string) (_ groupDto.Group, err error) {
+	defer func() {
+		groupService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: uuid,
+			Action:  "add_group_member",
+		}, err)
+	}()
+
+	if _, err := groupService.userRepo.GetUserByUUID(ctx, uuid); err != nil {
+		return groupDto.Group{}, fmt.Errorf("GroupService - AddMember: %w", err)
+	}
+
+	group, err := groupService.groupRepo.AddMember(ctx, id, uuid)
+	if err != nil {
+		return groupDto.Group{}, fmt.Errorf("GroupService - AddMember: %w", err)
+	}
+
+	return group, nil
+}
+
+func (groupService *GroupService) RemoveMember(ctx context.Context, id string, uuid string) (_ groupDto.Group, err error) {
+	defer func() {
+		groupService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: uuid,
+			Action:  "remove_group_member",
+		}, err)
+	}()
+
+	group, err := groupService.groupRepo.RemoveMember(ctx, id, uuid)
+	if err != nil {
+		return groupDto.Group{}, fmt.Errorf("GroupService - RemoveMember: %w", err)
+	}
+
+	return group, nil
+}
+
+// UserGroups returns groups user is a direct member of.
+func (groupService *GroupService) UserGroups(ctx context.Context, uuid string) ([]groupDto.Group, error) {
+	if _, err := groupService.userRepo.GetUserByUUID(ctx, uuid); err != nil {
+		return nil, fmt.Errorf("GroupService - UserGroups: %w", err)
+	}
+
+	groups, err := groupService.groupRepo.ListUserGroups(ctx, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("GroupService - UserGroups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// Effective flattens roles of user with roles of its groups and every group
+// they are nested into, and resolves their permissions. Groups are read level
+// by level starting from direct ones, so only ancestors of user are loaded.
+func (groupService *GroupService) Effective(ctx context.Context, user userDto.User) (groupDto.Effective, error) {
+	groups, err := groupService.groupRepo.ListUserGroups(ctx, user.UUID)
+	if err != nil {
+		return groupDto.Effective{}, fmt.Errorf("GroupService - Effective: %w", err)
+	}
+
+	// visited also stops on cycles, concurrent updates may form one despite validate
+	roles := slices.Clone(user.Roles)
+	visited := make(map[string]struct{})
+	for len(groups) > 0 {
+		parents := make([]string, 0)
+		for _, group := range groups {
+			if _, ok := visited[group.ID]; ok {
+				continue
+			}
+			visited[group.ID] = struct{}{}
+			roles = append(roles, group.Roles...)
+			for _, parent := range group.Parents {
+				if _, ok := visited[parent]; !ok && !slices.Contains(parents, parent) {
+					parents = append(parents, parent)
+				}
+			}
+		}
+		if len(parents) == 0 {
+			break
+		}
+
+		groups, err = groupService.groupRepo.GetGroups(ctx, parents)
+		if err != nil {
+			return groupDto.Effective{}, fmt.Errorf("GroupService - Effective: %w", err)
+		}
+	}
+
+	effective := groupDto.Effective{
+		Roles:  normalize(roles),
+		Groups: make([]string, 0, len(visited)),
+	}
+	for id := range visited {
+		effective.Groups = append(effective.Groups, id)
+	}
+	slices.Sort(effective.Groups)
+
+	effective.Permissions, err = groupService.roleService.Permissions(ctx, effective.Roles)
+	if err != nil {
+		return groupDto.Effective{}, fmt.Errorf("GroupService - Effective: %w", err)
+	}
+
+	return effective, nil
+}
+
+// EffectiveOf resolves effective roles, groups and permissions of user uuid.
+func (groupService *GroupService) EffectiveOf(ctx context.Context, uuid string) (groupDto.Effective, error) {
+	user, err := groupService.userRepo.GetUserByUUID(ctx, uuid)
+	if err != nil {
+		return groupDto.Effective{}, fmt.Errorf("GroupService - EffectiveOf: %w", err)
+	}
+
+	return groupService.Effective(ctx, user)
+}
+
+// validate checks that roles of group are defined, its parents exist and nesting
+// group into them forms no cycle.
+func (groupService *GroupService) validate(ctx context.Context, group groupDto.Group) error {
+	for _, name := range group.Roles {
+		if _, err := groupService.roleRepo.GetRole(ctx, name); err != nil {
+			return fmt.Errorf("%s: %w", name, groupDto.ErrBadGroup)
+		}
+	}
+	if len(group.Parents) == 0 {
+		return nil
+	}
+
+	groups, err := groupService.groupRepo.ListGroups(ctx)
+	if err != nil {
+		return err
+	}
+	parents := make(map[string][]string, len(groups)+1)
+	for _, other := range groups {
+		parents[other.ID] = other.Parents
+	}
+	for _, parent := range group.Parents {
+		if _, ok := parents[parent]; !ok {
+			return fmt.Errorf("%s: %w", parent, groupDto.ErrBadGroup)
+		}
+	}
+	parents[group.ID] = group.Parents
+
+	if reaches(parents, group.Parents, group.ID) {
+		return groupDto.ErrGroupCycle
+	}
+
+	return nil
+}
+
+// reaches reports whether target is among from or their ancestors.
+func reaches(parents map[string][]string, from []string, target string) bool {
+	visited := make(map[string]struct{})
+	stack := slices.Clone(from)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == target {
+			return true
+		}
+		if _, ok := visited[id]; ok {
+			continue
+		}
+		visited[id] = struct{}{}
+		stack = append(stack, parents[id]...)
+	}
+
+	return false
+}
+
+func normalize(list []string) []string {
+	normalized := make([]string, 0, len(list))
+	for _, item := range list {
+		if item = strings.TrimSpace(item); item != "" {
+			normalized = append(normalized, item)
+		}
+	}
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
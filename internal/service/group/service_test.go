@@ -0,0 +1,197 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	groupDto "github.com/elusiv0/medods_test/internal/model/group"
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	"github.com/elusiv0/medods_test/internal/repo"
+	roleService "github.com/elusiv0/medods_test/internal/service/role"
+)
+
+// memoryGroupRepo implements only lookups used to resolve and validate nesting,
+// the embedded interface is left nil.
+type memoryGroupRepo struct {
+	repo.GroupRepo
+	groups map[string]groupDto.Group
+	loaded []string
+}
+
+func (repo *memoryGroupRepo) ListGroups(ctx context.Context) ([]groupDto.Group, error) {
+	groups := make([]groupDto.Group, 0, len(repo.groups))
+	for _, group := range repo.groups {
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (repo *memoryGroupRepo) ListUserGroups(ctx context.Context, uuid string) ([]groupDto.Group, error) {
+	groups := make([]groupDto.Group, 0)
+	for _, group := range repo.groups {
+		if slices.Contains(group.Members, uuid) {
+			repo.loaded = append(repo.loaded, group.ID)
+			groups = append(groups, group)
+		}
+	}
+
+	return groups, nil
+}
+
+func (repo *memoryGroupRepo) GetGroups(ctx context.Context, ids []string) ([]groupDto.Group, error) {
+	groups := make([]groupDto.Group, 0, len(ids))
+	for _, id := range ids {
+		if group, ok := repo.groups[id]; ok {
+			repo.loaded = append(repo.loaded, id)
+			groups = append(groups, group)
+		}
+	}
+
+	return groups, nil
+}
+
+type memoryRoleRepo struct {
+	repo.RoleRepo
+	roles []roleDto.Role
+}
+
+func (repo memoryRoleRepo) GetRole(ctx context.Context, name string) (roleDto.Role, error) {
+	for _, role := range repo.roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+
+	return roleDto.Role{}, roleDto.ErrRoleNotFound
+}
+
+func (repo memoryRoleRepo) ListRoles(ctx context.Context) ([]roleDto.Role, error) {
+	return repo.roles, nil
+}
+
+func newTestService(groups ...groupDto.Group) (*GroupService, *memoryGroupRepo) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	roles := memoryRoleRepo{roles: []roleDto.Role{
+		{Name: "admin", Permissions: []string{"users:*"}},
+		{Name: "editor", Permissions: []string{"profile:write"}},
+		{Name: "reader", Permissions: []string{"profile:read"}},
+	}}
+	groupRepo := &memoryGroupRepo{groups: make(map[string]groupDto.Group, len(groups))}
+	for _, group := range groups {
+		groupRepo.groups[group.ID] = group
+	}
+
+	return &GroupService{
+		groupRepo:   groupRepo,
+		roleRepo:    roles,
+		roleService: roleService.New(roles, nil, nil, nil, nil, time.Minute, logger),
+		logger:      logger,
+		now:         time.Now,
+	}, groupRepo
+}
+
+func TestEffectiveInheritsParents(t *testing.T) {
+	service, groupRepo := newTestService(
+		groupDto.Group{ID: "staff", Roles: []string{"reader"}},
+		groupDto.Group{ID: "editors", Roles: []string{"editor"}, Parents: []string{"staff"}},
+		groupDto.Group{ID: "team", Parents: []string{"editors", "staff"}, Members: []string{"u-1"}},
+		groupDto.Group{ID: "admins", Roles: []string{"admin"}},
+	)
+
+	effective, err := service.Effective(context.Background(), userDto.User{UUID: "u-1", Roles: []string{"reader"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := groupDto.Effective{
+		Roles:       []string{"editor", "reader"},
+		Groups:      []string{"editors", "staff", "team"},
+		Permissions: []string{"profile:read", "profile:write"},
+	}
+	if !slices.Equal(effective.Roles, want.Roles) ||
+		!slices.Equal(effective.Groups, want.Groups) ||
+		!slices.Equal(effective.Permissions, want.Permissions) {
+		t.Errorf("Effective = %+v, want %+v", effective, want)
+	}
+	if slices.Contains(groupRepo.loaded, "admins") {
+		t.Error("group user is not nested into is loaded")
+	}
+}
+
+func TestEffectiveStopsOnCycle(t *testing.T) {
+	service, _ := newTestService(
+		groupDto.Group{ID: "a", Roles: []string{"reader"}, Parents: []string{"b"}, Members: []string{"u-1"}},
+		groupDto.Group{ID: "b", Roles: []string{"editor"}, Parents: []string{"c"}},
+		groupDto.Group{ID: "c", Roles: []string{"admin"}, Parents: []string{"a"}},
+	)
+
+	effective, err := service.Effective(context.Background(), userDto.User{UUID: "u-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(effective.Groups, []string{"a", "b", "c"}) ||
+		!slices.Equal(effective.Roles, []string{"admin", "editor", "reader"}) {
+		t.Errorf("Effective = %+v", effective)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	service, _ := newTestService(
+		groupDto.Group{ID: "root"},
+		groupDto.Group{ID: "middle", Parents: []string{"root"}},
+		groupDto.Group{ID: "leaf", Parents: []string{"middle"}},
+	)
+
+	for name, tc := range map[string]struct {
+		group groupDto.Group
+		err   error
+	}{
+		"new group":              {group: groupDto.Group{ID: "new", Roles: []string{"reader"}, Parents: []string{"leaf"}}},
+		"moved group":            {group: groupDto.Group{ID: "leaf", Parents: []string{"root"}}},
+		"undefined role":         {group: groupDto.Group{ID: "new", Roles: []string{"owner"}}, err: groupDto.ErrBadGroup},
+		"missing parent":         {group: groupDto.Group{ID: "new", Parents: []string{"other"}}, err: groupDto.ErrBadGroup},
+		"nested into itself":     {group: groupDto.Group{ID: "root", Parents: []string{"root"}}, err: groupDto.ErrGroupCycle},
+		"nested into child":      {group: groupDto.Group{ID: "root", Parents: []string{"middle"}}, err: groupDto.ErrGroupCycle},
+		"nested into grandchild": {group: groupDto.Group{ID: "root", Parents: []string{"leaf"}}, err: groupDto.ErrGroupCycle},
+	} {
+		if err := service.validate(context.Background(), tc.group); !errors.Is(err, tc.err) {
+			t.Errorf("%s: validate = %v, want %v", name, err, tc.err)
+		}
+	}
+}
+
+func TestReaches(t *testing.T) {
+	parents := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"d"},
+		"c": {"d"},
+		"d": nil,
+		"x": {"y"},
+		"y": {"x"},
+	}
+
+	for _, tc := range []struct {
+		from   []string
+		target string
+		want   bool
+	}{
+		{[]string{"a"}, "a", true},
+		{[]string{"a"}, "d", true},
+		{[]string{"b"}, "c", false},
+		{[]string{"d"}, "a", false},
+		{[]string{"x"}, "a", false},
+		{[]string{"x"}, "y", true},
+		{nil, "a", false},
+	} {
+		if got := reaches(parents, tc.from, tc.target); got != tc.want {
+			t.Errorf("reaches(%v, %s) = %v, want %v", tc.from, tc.target, got, tc.want)
+		}
+	}
+}
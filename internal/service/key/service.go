@@ -0,0 +1,221 @@
+package key
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	keyDto "github.com/elusiv0/medods_test/internal/model/key"
+	"github.com/elusiv0/medods_test/internal/repo"
+	keyRepository "github.com/elusiv0/medods_test/internal/repo/key"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+)
+
+type Policy struct {
+	// ReloadInterval is how often keys rotated by another process are picked up.
+	ReloadInterval time.Duration
+	// RetiredKeyTTL is how long retired key keeps verifying tokens, it should not
+	// be shorter than access token lifetime.
+	RetiredKeyTTL time.Duration
+}
+
+type KeyService struct {
+	keyRepo      repo.KeyRepo
+	tokenManager *tokenManager.TokenManager
+	auditService *auditService.AuditService
+	logger       *slog.Logger
+	now          func() time.Time
+
+	mu     sync.RWMutex
+	policy Policy
+}
+
+const (
+	keySize   = 64
+	keyIdSize = 8
+)
+
+func New(
+	keyRepo *keyRepository.KeyRepo,
+	tokenManager *tokenManager.TokenManager,
+	auditService *auditService.AuditService,
+	policy Policy,
+	log *slog.Logger,
+) *KeyService {
+	return &KeyService{
+		keyRepo:      keyRepo,
+		tokenManager: tokenManager,
+		auditService: auditService,
+		policy:       policy,
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+// Generate stores new pending key of tenant of ctx, it signs nothing until
+// activated by Rotate.
+func (keyService *KeyService) Generate(ctx context.Context) (keyDto.Key, error) {
+	id := make([]byte, keyIdSize)
+	secret := make([]byte, keySize)
+	if _, err := rand.Read(id); err != nil {
+		return keyDto.Key{}, fmt.Errorf("KeyService - Generate: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return keyDto.Key{}, fmt.Errorf("KeyService - Generate: %w", err)
+	}
+
+	key := keyDto.Key{
+		ID:        hex.EncodeToString(id),
+		Tenant:    tenantUtil.FromContext(ctx),
+		Secret:    secret,
+		Status:    keyDto.StatusPending,
+		CreatedAt: keyService.now().UTC(),
+	}
+	if err := keyService.keyRepo.InsertKey(ctx, key); err != nil {
+		return keyDto.Key{}, fmt.Errorf("KeyService - Generate: %w", err)
+	}
+
+	return key, nil
+}
+
+// Rotate activates pending key id of tenant of ctx. Key younger than
+// ReloadInterval is refused, some replica may not have loaded it yet and would
+// reject tokens signed with it. When id is empty a new pending key is only
+// generated, it is activated by another Rotate once every replica has loaded it.
+func (keyService *KeyService) Rotate(ctx context.Context, id string) (_ keyDto.Key, err error) {
+	if id == "" {
+		return keyService.Generate(ctx)
+	}
+
+	defer func() {
+		keyService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: id,
+			Action:  "rotate_key",
+		}, err)
+	}()
+
+	keys, err := keyService.List(ctx)
+	if err != nil {
+		return keyDto.Key{}, fmt.Errorf("KeyService - Rotate: %w", err)
+	}
+	index := slices.IndexFunc(keys, func(key keyDto.Key) bool {
+		return key.ID == id && key.Status == keyDto.StatusPending
+	})
+	if index < 0 {
+		return keyDto.Key{}, fmt.Errorf("KeyService - Rotate: %w", keyDto.ErrKeyNotFound)
+	}
+	if age := keyService.now().Sub(keys[index].CreatedAt); age < keyService.currentPolicy().ReloadInterval {
+		return keyDto.Key{}, fmt.Errorf("KeyService - Rotate: %w", keyDto.ErrKeyNotLoaded)
+	}
+
+	if err := keyService.keyRepo.ActivateKey(ctx, id, keyService.now()); err != nil {
+		return keyDto.Key{}, fmt.Errorf("KeyService - Rotate: %w", err)
+	}
+	if err := keyService.Load(ctx); err != nil {
+		return keyDto.Key{}, fmt.Errorf("KeyService - Rotate: %w", err)
+	}
+
+	keys, err = keyService.List(ctx)
+	if err != nil {
+		return keyDto.Key{}, fmt.Errorf("KeyService - Rotate: %w", err)
+	}
+	for _, key := range keys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+
+	return keyDto.Key{}, fmt.Errorf("KeyService - Rotate: %w", keyDto.ErrKeyNotFound)
+}
+
+// List returns keys of tenant of ctx.
+func (keyService *KeyService) List(ctx context.Context) ([]keyDto.Key, error) {
+	keys, err := keyService.keyRepo.ListKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("KeyService - List: %w", err)
+	}
+
+	tenant := tenantUtil.FromContext(ctx)
+	owned := make([]keyDto.Key, 0, len(keys))
+	for _, key := range keys {
+		if key.Tenant == tenant {
+			owned = append(owned, key)
+		}
+	}
+
+	return owned, nil
+}
+
+// SetPolicy replaces policy, ReloadInterval takes effect after restart.
+func (keyService *KeyService) SetPolicy(policy Policy) {
+	keyService.mu.Lock()
+	defer keyService.mu.Unlock()
+
+	keyService.policy = policy
+}
+
+func (keyService *KeyService) currentPolicy() Policy {
+	keyService.mu.RLock()
+	defer keyService.mu.RUnlock()
+
+	return keyService.policy
+}
+
+// Load hands active key of every tenant and retired keys still within
+// RetiredKeyTTL to token manager. Pending keys verify tokens as well, so that every
+// replica knows the key before any of them starts signing with it.
+func (keyService *KeyService) Load(ctx context.Context) error {
+	keys, err := keyService.keyRepo.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("KeyService - Load: %w", err)
+	}
+
+	now := keyService.now()
+	policy := keyService.currentPolicy()
+	activeKeyIds := make(map[string]string)
+	secrets := make(map[string]tokenManager.SigningKey)
+	for _, key := range keys {
+		signingKey := tokenManager.SigningKey{
+			Tenant: key.Tenant,
+			Secret: key.Secret,
+		}
+		switch {
+		case key.Status == keyDto.StatusActive:
+			activeKeyIds[key.Tenant] = key.ID
+			secrets[key.ID] = signingKey
+		case key.Status == keyDto.StatusPending:
+			secrets[key.ID] = signingKey
+		case key.Status == keyDto.StatusRetired && now.Sub(key.RetiredAt) < policy.RetiredKeyTTL:
+			secrets[key.ID] = signingKey
+		}
+	}
+	keyService.tokenManager.SetKeys(activeKeyIds, secrets)
+
+	return nil
+}
+
+// Run reloads keys until ctx is cancelled.
+func (keyService *KeyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(keyService.currentPolicy().ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := keyService.Load(ctx); err != nil {
+				keyService.logger.Error("KeyService - Run: " + err.Error())
+			}
+		}
+	}
+}
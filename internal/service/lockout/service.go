@@ -0,0 +1,163 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	lockoutDto "github.com/elusiv0/medods_test/internal/model/lockout"
+	"github.com/elusiv0/medods_test/internal/repo"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+)
+
+// Policy of lockout. Failures are registered only by refresh presenting a wrong
+// or unknown refresh token of user: sign in takes no secret, so there is nothing
+// to guess and it is left to rate limits. Lockout blocks both sign in and
+// refresh of user.
+type Policy struct {
+	Threshold     int
+	Duration      time.Duration
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	FailureWindow time.Duration
+}
+
+type LockoutService struct {
+	lockoutRepo  repo.LockoutRepo
+	auditService *auditService.AuditService
+	policy       Policy
+	logger       *slog.Logger
+	now          func() time.Time
+}
+
+func New(
+	lockoutRepo repo.LockoutRepo,
+	auditService *auditService.AuditService,
+	policy Policy,
+	log *slog.Logger,
+) *LockoutService {
+	return &LockoutService{
+		lockoutRepo:  lockoutRepo,
+		auditService: auditService,
+		policy:       policy,
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+// Check returns error when user has to wait before next authentication attempt.
+func (lockoutService *LockoutService) Check(ctx context.Context, uuid string) error {
+	lockout, err := lockoutService.lockoutRepo.GetLockout(ctx, uuid)
+	if err != nil {
+		if errors.Is(err, lockoutDto.ErrLockoutNotFound) {
+			return nil
+		}
+		return fmt.Errorf("LockoutService - Check: %w", err)
+	}
+
+	now := lockoutService.now()
+	if now.Before(lockout.LockedUntil) {
+		return fmt.Errorf("LockoutService - Check: %w", &api.RetryError{
+			Err:        lockoutDto.ErrAccountLocked,
+			RetryAfter: lockout.LockedUntil.Sub(now),
+		})
+	}
+
+	if lockout.Failures == 0 || now.Sub(lockout.LastFailureAt) > lockoutService.policy.FailureWindow {
+		return nil
+	}
+
+	retryAt := lockout.LastFailureAt.Add(lockoutService.delay(lockout.Failures))
+	if now.Before(retryAt) {
+		return fmt.Errorf("LockoutService - Check: %w", &api.RetryError{
+			Err:        lockoutDto.ErrAuthenticationDelayed,
+			RetryAfter: retryAt.Sub(now),
+		})
+	}
+
+	return nil
+}
+
+// RegisterFailure counts failed attempt and locks account once threshold is reached,
+// reports whether this attempt locked the account.
+func (lockoutService *LockoutService) RegisterFailure(ctx context.Context, uuid string) (bool, error) {
+	now := lockoutService.now()
+
+	lockout, err := lockoutService.lockoutRepo.RegisterFailure(ctx, uuid, now, lockoutService.policy.FailureWindow)
+	if err != nil {
+		return false, fmt.Errorf("LockoutService - RegisterFailure: %w", err)
+	}
+
+	if lockoutService.policy.Threshold <= 0 || lockout.Failures < lockoutService.policy.Threshold {
+		return false, nil
+	}
+
+	lockedUntil := now.Add(lockoutService.policy.Duration)
+	if err := lockoutService.lockoutRepo.Lock(ctx, uuid, lockedUntil); err != nil {
+		return false, fmt.Errorf("LockoutService - RegisterFailure: %w", err)
+	}
+	lockoutService.logger.Warn(
+		"LockoutService: account locked",
+		slog.String("uuid", uuid),
+		slog.Time("locked_until", lockedUntil),
+	)
+	lockoutService.auditService.Record(ctx, auditDto.Entry{
+		Type:    auditDto.TypeLockout,
+		Subject: uuid,
+		Outcome: auditDto.OutcomeSuccess,
+		Reason:  lockoutDto.ErrAccountLocked.Error(),
+	})
+
+	return true, nil
+}
+
+// Reset forgets failed attempts after successful authentication.
+func (lockoutService *LockoutService) Reset(ctx context.Context, uuid string) error {
+	if err := lockoutService.lockoutRepo.DeleteLockout(ctx, uuid); err != nil && !errors.Is(err, lockoutDto.ErrLockoutNotFound) {
+		return fmt.Errorf("LockoutService - Reset: %w", err)
+	}
+
+	return nil
+}
+
+func (lockoutService *LockoutService) Get(ctx context.Context, uuid string) (lockoutDto.Lockout, error) {
+	lockout, err := lockoutService.lockoutRepo.GetLockout(ctx, uuid)
+	if err != nil {
+		return lockoutDto.Lockout{}, fmt.Errorf("LockoutService - Get: %w", err)
+	}
+
+	return lockout, nil
+}
+
+func (lockoutService *LockoutService) Unlock(ctx context.Context, uuid string) (err error) {
+	defer func() {
+		lockoutService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: uuid,
+			Action:  "unlock",
+		}, err)
+	}()
+
+	if err := lockoutService.lockoutRepo.DeleteLockout(ctx, uuid); err != nil {
+		return fmt.Errorf("LockoutService - Unlock: %w", err)
+	}
+	lockoutService.logger.Info("LockoutService: account unlocked", slog.String("uuid", uuid))
+
+	return nil
+}
+
+func (lockoutService *LockoutService) delay(failures int) time.Duration {
+	delay := lockoutService.policy.BaseDelay
+	for i := 1; i < failures && delay < lockoutService.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > lockoutService.policy.MaxDelay {
+		delay = lockoutService.policy.MaxDelay
+	}
+
+	return delay
+}
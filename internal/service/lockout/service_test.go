@@ -0,0 +1,168 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/elusiv0/medods_test/internal/model/api"
+	lockoutDto "github.com/elusiv0/medods_test/internal/model/lockout"
+)
+
+// memoryLockoutRepo counts failures the way Mongo repository does: the counter
+// starts over when previous failure is older than window.
+type memoryLockoutRepo struct {
+	lockouts map[string]lockoutDto.Lockout
+}
+
+func (repo *memoryLockoutRepo) GetLockout(ctx context.Context, uuid string) (lockoutDto.Lockout, error) {
+	lockout, ok := repo.lockouts[uuid]
+	if !ok {
+		return lockoutDto.Lockout{}, lockoutDto.ErrLockoutNotFound
+	}
+
+	return lockout, nil
+}
+
+func (repo *memoryLockoutRepo) RegisterFailure(ctx context.Context, uuid string, at time.Time, window time.Duration) (lockoutDto.Lockout, error) {
+	lockout := repo.lockouts[uuid]
+	lockout.UUID = uuid
+	if lockout.LastFailureAt.Before(at.Add(-window)) {
+		lockout.Failures = 0
+	}
+	lockout.Failures++
+	lockout.LastFailureAt = at
+	repo.lockouts[uuid] = lockout
+
+	return lockout, nil
+}
+
+func (repo *memoryLockoutRepo) Lock(ctx context.Context, uuid string, until time.Time) error {
+	lockout := repo.lockouts[uuid]
+	lockout.UUID = uuid
+	lockout.LockedUntil = until
+	lockout.Failures = 0
+	repo.lockouts[uuid] = lockout
+
+	return nil
+}
+
+func (repo *memoryLockoutRepo) DeleteLockout(ctx context.Context, uuid string) error {
+	if _, ok := repo.lockouts[uuid]; !ok {
+		return lockoutDto.ErrLockoutNotFound
+	}
+	delete(repo.lockouts, uuid)
+
+	return nil
+}
+
+func newTestService(now *time.Time, repo *memoryLockoutRepo) *LockoutService {
+	return &LockoutService{
+		lockoutRepo: repo,
+		policy: Policy{
+			BaseDelay:     time.Second,
+			MaxDelay:      10 * time.Second,
+			FailureWindow: time.Hour,
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		now:    func() time.Time { return *now },
+	}
+}
+
+func retryAfter(t *testing.T, err error, want error) time.Duration {
+	t.Helper()
+
+	var retryErr *api.RetryError
+	if !errors.As(err, &retryErr) || !errors.Is(err, want) {
+		t.Fatalf("err = %v, want %v with retry after", err, want)
+	}
+
+	return retryErr.RetryAfter
+}
+
+func TestDelay(t *testing.T) {
+	service := newTestService(new(time.Time), &memoryLockoutRepo{})
+
+	for failures, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		4:  8 * time.Second,
+		5:  10 * time.Second,
+		50: 10 * time.Second,
+	} {
+		if got := service.delay(failures); got != want {
+			t.Errorf("delay(%d) = %s, want %s", failures, got, want)
+		}
+	}
+}
+
+func TestCheckDelaysAfterFailures(t *testing.T) {
+	now := time.Unix(1714564800, 0)
+	service := newTestService(&now, &memoryLockoutRepo{lockouts: map[string]lockoutDto.Lockout{}})
+	ctx := context.Background()
+
+	if err := service.Check(ctx, "u-1"); err != nil {
+		t.Fatalf("user without failures is delayed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if locked, err := service.RegisterFailure(ctx, "u-1"); err != nil || locked {
+			t.Fatalf("RegisterFailure = %v, %v", locked, err)
+		}
+	}
+	if got := retryAfter(t, service.Check(ctx, "u-1"), lockoutDto.ErrAuthenticationDelayed); got != 4*time.Second {
+		t.Errorf("retry after = %s, want 4s", got)
+	}
+
+	now = now.Add(4 * time.Second)
+	if err := service.Check(ctx, "u-1"); err != nil {
+		t.Errorf("attempt after delay is rejected: %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := service.RegisterFailure(ctx, "u-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := retryAfter(t, service.Check(ctx, "u-1"), lockoutDto.ErrAuthenticationDelayed); got != time.Second {
+		t.Errorf("retry after failure past window = %s, want base delay", got)
+	}
+
+	if err := service.Reset(ctx, "u-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.Check(ctx, "u-1"); err != nil {
+		t.Errorf("reset user is delayed: %v", err)
+	}
+}
+
+func TestCheckLockExpires(t *testing.T) {
+	now := time.Unix(1714564800, 0)
+	service := newTestService(&now, &memoryLockoutRepo{lockouts: map[string]lockoutDto.Lockout{
+		"u-1": {UUID: "u-1", LockedUntil: now.Add(15 * time.Minute)},
+	}})
+	ctx := context.Background()
+
+	if got := retryAfter(t, service.Check(ctx, "u-1"), lockoutDto.ErrAccountLocked); got != 15*time.Minute {
+		t.Errorf("retry after = %s, want 15m", got)
+	}
+
+	now = now.Add(15 * time.Minute)
+	if err := service.Check(ctx, "u-1"); err != nil {
+		t.Errorf("expired lock still applies: %v", err)
+	}
+}
+
+func TestCheckIgnoresFailuresPastWindow(t *testing.T) {
+	now := time.Unix(1714564800, 0)
+	service := newTestService(&now, &memoryLockoutRepo{lockouts: map[string]lockoutDto.Lockout{
+		"u-1": {UUID: "u-1", Failures: 20, LastFailureAt: now.Add(-time.Hour - time.Second)},
+	}})
+
+	if err := service.Check(context.Background(), "u-1"); err != nil {
+		t.Errorf("failures past window delay user: %v", err)
+	}
+}
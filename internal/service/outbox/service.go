@@ -0,0 +1,163 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	eventDto "github.com/elusiv0/medods_test/internal/model/event"
+	outboxDto "github.com/elusiv0/medods_test/internal/model/outbox"
+	"github.com/elusiv0/medods_test/internal/repo"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	"github.com/elusiv0/medods_test/pkg/publisher"
+	uuidUtil "github.com/google/uuid"
+)
+
+type Policy struct {
+	TopicPrefix  string
+	PollInterval time.Duration
+	Lease        time.Duration
+	BatchSize    int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+// OutboxService writes domain events next to the state they describe and relays
+// them to the broker. A message is marked delivered only after broker has accepted
+// it, so consumers may see duplicates and should drop them by message id.
+type OutboxService struct {
+	outboxRepo repo.OutboxRepo
+	publisher  publisher.Publisher
+	policy     Policy
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+func New(
+	outboxRepo repo.OutboxRepo,
+	publisher publisher.Publisher,
+	policy Policy,
+	log *slog.Logger,
+) *OutboxService {
+	return &OutboxService{
+		outboxRepo: outboxRepo,
+		publisher:  publisher,
+		policy:     policy,
+		logger:     log,
+		now:        time.Now,
+	}
+}
+
+// Enqueue stores event in outbox, ctx has to carry the transaction of the state change.
+func (outboxService *OutboxService) Enqueue(
+	ctx context.Context,
+	eventType string,
+	subject string,
+	data map[string]string,
+) error {
+	now := outboxService.now().UTC()
+	event := eventDto.Event{
+		ID:         uuidUtil.NewString(),
+		Tenant:     tenantUtil.FromContext(ctx),
+		Type:       eventType,
+		Subject:    subject,
+		OccurredAt: now,
+		Data:       data,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("OutboxService - Enqueue: %w", err)
+	}
+
+	_, err = outboxService.outboxRepo.InsertMessage(ctx, outboxDto.Message{
+		EventID:       event.ID,
+		EventType:     eventType,
+		Key:           subject,
+		Payload:       string(payload),
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	})
+	if err != nil {
+		return fmt.Errorf("OutboxService - Enqueue: %w", err)
+	}
+
+	return nil
+}
+
+// Run relays pending messages until ctx is cancelled.
+func (outboxService *OutboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxService.policy.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := outboxService.publisher.Close(); err != nil {
+				outboxService.logger.Error("OutboxService - Run: " + err.Error())
+			}
+			return
+		case <-ticker.C:
+			outboxService.relayDue(ctx)
+		}
+	}
+}
+
+func (outboxService *OutboxService) relayDue(ctx context.Context) {
+	for i := 0; i < outboxService.policy.BatchSize; i++ {
+		message, err := outboxService.outboxRepo.ClaimMessage(ctx, outboxService.now(), outboxService.policy.Lease)
+		if err != nil {
+			if !errors.Is(err, outboxDto.ErrMessageNotFound) {
+				outboxService.logger.Error("OutboxService - relayDue: " + err.Error())
+			}
+			return
+		}
+
+		outboxService.relay(ctx, message)
+	}
+}
+
+func (outboxService *OutboxService) relay(ctx context.Context, message outboxDto.Message) {
+	err := outboxService.publisher.Publish(ctx, publisher.Message{
+		ID:      message.EventID,
+		Topic:   outboxService.policy.TopicPrefix + message.EventType,
+		Key:     message.Key,
+		Payload: []byte(message.Payload),
+		Headers: map[string]string{
+			publisher.HeaderEventType: message.EventType,
+			publisher.HeaderTenant:    message.Tenant,
+		},
+	})
+	if err != nil {
+		outboxService.logger.Warn(
+			"OutboxService: publish failed",
+			slog.String("message", message.ID),
+			slog.String("error", err.Error()),
+		)
+		nextAttemptAt := outboxService.now().Add(outboxService.backoff(message.Attempts + 1))
+		if err := outboxService.outboxRepo.MarkFailed(ctx, message.ID, nextAttemptAt, err.Error()); err != nil {
+			outboxService.logger.Error("OutboxService - relay: " + err.Error())
+		}
+		return
+	}
+
+	// failing here leaves message claimed, it is published again once the lease expires
+	if err := outboxService.outboxRepo.MarkDelivered(ctx, message.ID, outboxService.now()); err != nil {
+		outboxService.logger.Error("OutboxService - relay: " + err.Error())
+	}
+}
+
+func (outboxService *OutboxService) backoff(attempts int) time.Duration {
+	backoff := outboxService.policy.BaseBackoff
+	for i := 1; i < attempts && backoff < outboxService.policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxService.policy.MaxBackoff {
+		backoff = outboxService.policy.MaxBackoff
+	}
+
+	return backoff
+}
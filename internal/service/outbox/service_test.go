@@ -0,0 +1,246 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	eventDto "github.com/elusiv0/medods_test/internal/model/event"
+	outboxDto "github.com/elusiv0/medods_test/internal/model/outbox"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	"github.com/elusiv0/medods_test/pkg/publisher"
+)
+
+// memoryOutboxRepo keeps messages in insertion order and claims them the way
+// Mongo repository does: due, undelivered and not leased by another relay.
+type memoryOutboxRepo struct {
+	mu       sync.Mutex
+	messages []outboxDto.Message
+	leased   map[string]time.Time
+}
+
+func newMemoryOutboxRepo() *memoryOutboxRepo {
+	return &memoryOutboxRepo{leased: map[string]time.Time{}}
+}
+
+func (repo *memoryOutboxRepo) InsertMessage(ctx context.Context, message outboxDto.Message) (string, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	message.ID = strconv.Itoa(len(repo.messages) + 1)
+	message.Tenant = tenantUtil.FromContext(ctx)
+	repo.messages = append(repo.messages, message)
+
+	return message.ID, nil
+}
+
+func (repo *memoryOutboxRepo) ClaimMessage(ctx context.Context, now time.Time, lease time.Duration) (outboxDto.Message, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, message := range repo.messages {
+		if !message.DeliveredAt.IsZero() || message.NextAttemptAt.After(now) || repo.leased[message.ID].After(now) {
+			continue
+		}
+		repo.leased[message.ID] = now.Add(lease)
+		return message, nil
+	}
+
+	return outboxDto.Message{}, outboxDto.ErrMessageNotFound
+}
+
+func (repo *memoryOutboxRepo) MarkDelivered(ctx context.Context, id string, at time.Time) error {
+	return repo.update(id, func(message *outboxDto.Message) {
+		message.DeliveredAt = at
+	})
+}
+
+func (repo *memoryOutboxRepo) MarkFailed(ctx context.Context, id string, nextAttemptAt time.Time, lastError string) error {
+	return repo.update(id, func(message *outboxDto.Message) {
+		message.Attempts++
+		message.NextAttemptAt = nextAttemptAt
+		message.LastError = lastError
+	})
+}
+
+func (repo *memoryOutboxRepo) update(id string, fn func(message *outboxDto.Message)) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for i := range repo.messages {
+		if repo.messages[i].ID == id {
+			fn(&repo.messages[i])
+			delete(repo.leased, id)
+			return nil
+		}
+	}
+
+	return outboxDto.ErrMessageNotFound
+}
+
+func (repo *memoryOutboxRepo) message(t *testing.T, id string) outboxDto.Message {
+	t.Helper()
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, message := range repo.messages {
+		if message.ID == id {
+			return message
+		}
+	}
+	t.Fatalf("message %s not found", id)
+
+	return outboxDto.Message{}
+}
+
+func newTestService(repo *memoryOutboxRepo, memoryPublisher *publisher.MemoryPublisher, now *time.Time) *OutboxService {
+	return &OutboxService{
+		outboxRepo: repo,
+		publisher:  memoryPublisher,
+		policy: Policy{
+			TopicPrefix:  "auth.",
+			PollInterval: time.Second,
+			Lease:        30 * time.Second,
+			BatchSize:    10,
+			BaseBackoff:  time.Second,
+			MaxBackoff:   5 * time.Second,
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		now:    func() time.Time { return *now },
+	}
+}
+
+func TestRelayPublishesAndMarksDelivered(t *testing.T) {
+	ctx := tenantUtil.WithTenant(context.Background(), "acme")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := newMemoryOutboxRepo()
+	memoryPublisher := publisher.NewMemoryPublisher()
+	outboxService := newTestService(repo, memoryPublisher, &now)
+
+	if err := outboxService.Enqueue(ctx, outboxDto.TypeUserCreated, "user-1", map[string]string{"login": "alice"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	outboxService.relayDue(ctx)
+
+	messages := memoryPublisher.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("published %d messages, want 1", len(messages))
+	}
+	message := messages[0]
+	if message.Topic != "auth."+outboxDto.TypeUserCreated || message.Key != "user-1" {
+		t.Errorf("published to %q with key %q", message.Topic, message.Key)
+	}
+	if message.Headers[publisher.HeaderEventType] != outboxDto.TypeUserCreated {
+		t.Errorf("event type header is %q", message.Headers[publisher.HeaderEventType])
+	}
+	if message.Headers[publisher.HeaderTenant] != "acme" {
+		t.Errorf("tenant header is %q", message.Headers[publisher.HeaderTenant])
+	}
+
+	event := eventDto.Event{}
+	if err := json.Unmarshal(message.Payload, &event); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if event.ID != message.ID || event.Tenant != "acme" || event.Subject != "user-1" || event.Data["login"] != "alice" {
+		t.Errorf("unexpected event %+v for message %s", event, message.ID)
+	}
+
+	if stored := repo.message(t, "1"); !stored.DeliveredAt.Equal(now) {
+		t.Errorf("message delivered at %v, want %v", stored.DeliveredAt, now)
+	}
+
+	outboxService.relayDue(ctx)
+	if published := len(memoryPublisher.Messages()); published != 1 {
+		t.Errorf("delivered message published again, %d messages", published)
+	}
+}
+
+func TestRelayRetriesFailedPublishWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := newMemoryOutboxRepo()
+	memoryPublisher := publisher.NewMemoryPublisher()
+	outboxService := newTestService(repo, memoryPublisher, &now)
+
+	if err := outboxService.Enqueue(ctx, outboxDto.TypeTokensIssued, "user-1", nil); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	memoryPublisher.FailWith(errors.New("broker is down"))
+	outboxService.relayDue(ctx)
+
+	stored := repo.message(t, "1")
+	if stored.Attempts != 1 || stored.LastError != "broker is down" {
+		t.Fatalf("attempts %d, last error %q", stored.Attempts, stored.LastError)
+	}
+	if !stored.DeliveredAt.IsZero() {
+		t.Fatal("failed message marked delivered")
+	}
+	if want := now.Add(time.Second); !stored.NextAttemptAt.Equal(want) {
+		t.Errorf("next attempt at %v, want %v", stored.NextAttemptAt, want)
+	}
+
+	// not due yet
+	outboxService.relayDue(ctx)
+	if stored := repo.message(t, "1"); stored.Attempts != 1 {
+		t.Fatalf("message retried before backoff, attempts %d", stored.Attempts)
+	}
+
+	memoryPublisher.FailWith(nil)
+	now = now.Add(time.Second)
+	outboxService.relayDue(ctx)
+
+	if published := len(memoryPublisher.Messages()); published != 1 {
+		t.Fatalf("published %d messages, want 1", published)
+	}
+	if stored := repo.message(t, "1"); stored.DeliveredAt.IsZero() {
+		t.Error("retried message is not marked delivered")
+	}
+}
+
+func TestRelayDueStopsAtBatchSize(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	repo := newMemoryOutboxRepo()
+	memoryPublisher := publisher.NewMemoryPublisher()
+	outboxService := newTestService(repo, memoryPublisher, &now)
+	outboxService.policy.BatchSize = 2
+
+	for i := 0; i < 3; i++ {
+		if err := outboxService.Enqueue(ctx, outboxDto.TypeUserUpdated, "user-1", nil); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+
+	outboxService.relayDue(ctx)
+	if published := len(memoryPublisher.Messages()); published != 2 {
+		t.Fatalf("published %d messages in one batch, want 2", published)
+	}
+	outboxService.relayDue(ctx)
+	if published := len(memoryPublisher.Messages()); published != 3 {
+		t.Fatalf("published %d messages after second batch, want 3", published)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	now := time.Now()
+	outboxService := newTestService(newMemoryOutboxRepo(), publisher.NewMemoryPublisher(), &now)
+
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		4:  5 * time.Second,
+		20: 5 * time.Second,
+	} {
+		if got := outboxService.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
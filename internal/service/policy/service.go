@@ -0,0 +1,279 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	policyDto "github.com/elusiv0/medods_test/internal/model/policy"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	tokenManager "github.com/elusiv0/medods_test/internal/util/token"
+	"github.com/elusiv0/medods_test/pkg/policy"
+)
+
+const defaultDecisionsLimit = 100
+
+type PolicyService struct {
+	auditService *auditService.AuditService
+	logger       *slog.Logger
+	now          func() time.Time
+
+	mu       sync.RWMutex
+	path     string
+	dryRun   bool
+	engine   *policy.Engine
+	loadedAt time.Time
+
+	logMu     sync.Mutex
+	decisions []policyDto.Decision
+	next      int
+	full      bool
+}
+
+// New loads policies from path, empty path leaves engine without policies so
+// that every request is allowed.
+func New(
+	path string,
+	dryRun bool,
+	decisionLogSize int,
+	auditService *auditService.AuditService,
+	log *slog.Logger,
+) (*PolicyService, error) {
+	policyService := &PolicyService{
+		auditService: auditService,
+		logger:       log,
+		now:          time.Now,
+		dryRun:       dryRun,
+		decisions:    make([]policyDto.Decision, decisionLogSize),
+	}
+	if err := policyService.Load(path); err != nil {
+		return nil, fmt.Errorf("PolicyService - New: %w", err)
+	}
+
+	return policyService, nil
+}
+
+// Load replaces policies with ones read from path, current policies are kept
+// when path holds invalid ones.
+func (policyService *PolicyService) Load(path string) error {
+	engine, err := policyService.compile(path)
+	if err != nil {
+		return fmt.Errorf("PolicyService - Load: %w", err)
+	}
+
+	policyService.mu.Lock()
+	policyService.path = path
+	policyService.engine = engine
+	policyService.loadedAt = policyService.now().UTC()
+	policyService.mu.Unlock()
+
+	policyService.logger.Info(
+		"PolicyService: policies loaded",
+		slog.String("path", path),
+		slog.Int("count", len(engine.Policies())),
+	)
+
+	return nil
+}
+
+// Reload re-reads policies from configured path.
+func (policyService *PolicyService) Reload(ctx context.Context) (_ policyDto.Status, err error) {
+	policyService.mu.RLock()
+	path := policyService.path
+	policyService.mu.RUnlock()
+
+	defer func() {
+		policyService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: path,
+			Action:  "reload_policies",
+		}, err)
+	}()
+
+	if path == "" {
+		return policyDto.Status{}, fmt.Errorf("PolicyService - Reload: %w", policyDto.ErrNoPolicyPath)
+	}
+	if err := policyService.Load(path); err != nil {
+		return policyDto.Status{}, fmt.Errorf("PolicyService - Reload: %w", err)
+	}
+
+	return policyService.Status(), nil
+}
+
+func (policyService *PolicyService) SetDryRun(dryRun bool) {
+	policyService.mu.Lock()
+	defer policyService.mu.Unlock()
+
+	policyService.dryRun = dryRun
+}
+
+func (policyService *PolicyService) Status() policyDto.Status {
+	policyService.mu.RLock()
+	defer policyService.mu.RUnlock()
+
+	return policyDto.Status{
+		Path:     policyService.path,
+		DryRun:   policyService.dryRun,
+		LoadedAt: policyService.loadedAt,
+		Policies: policyService.engine.Policies(),
+	}
+}
+
+// Route finds route template of loaded policies path matches and values of its
+// parameters.
+func (policyService *PolicyService) Route(path string) (string, map[string]string, bool) {
+	policyService.mu.RLock()
+	engine := policyService.engine
+	policyService.mu.RUnlock()
+
+	return engine.Route(path)
+}
+
+// Decide evaluates policies for request and records decision, denial is not
+// enforced in dry-run mode and is only logged.
+func (policyService *PolicyService) Decide(ctx context.Context, request policyDto.Request) policyDto.Decision {
+	policyService.mu.RLock()
+	engine, dryRun := policyService.engine, policyService.dryRun
+	policyService.mu.RUnlock()
+
+	result := engine.Evaluate(request.Method, request.Route, request.Attributes)
+	decision := policyDto.Decision{
+		Time:     policyService.now().UTC(),
+		Subject:  first(request.Attributes[policyDto.AttributeSubjectUUID]),
+		Tenant:   first(request.Attributes[policyDto.AttributeSubjectTenant]),
+		Method:   request.Method,
+		Route:    request.Route,
+		Path:     request.Path,
+		IP:       first(request.Attributes[policyDto.AttributeEnvIP]),
+		Allowed:  result.Allowed,
+		Enforced: result.Allowed || !dryRun,
+		Policy:   result.Policy,
+		Reason:   result.Reason,
+	}
+	policyService.record(decision)
+
+	attrs := []any{
+		slog.String("subject", decision.Subject),
+		slog.String("method", decision.Method),
+		slog.String("route", decision.Route),
+		slog.String("policy", decision.Policy),
+		slog.String("reason", decision.Reason),
+	}
+	switch {
+	case decision.Allowed:
+		policyService.logger.DebugContext(ctx, "PolicyService: access allowed", attrs...)
+	case decision.Enforced:
+		policyService.logger.WarnContext(ctx, "PolicyService: access denied", attrs...)
+	default:
+		policyService.logger.WarnContext(ctx, "PolicyService: access would be denied, dry-run mode", attrs...)
+	}
+
+	return decision
+}
+
+// Decisions returns recent decisions of tenant of ctx matching filter, newest first.
+func (policyService *PolicyService) Decisions(ctx context.Context, filter policyDto.Filter) []policyDto.Decision {
+	tenant := tenantUtil.FromContext(ctx)
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultDecisionsLimit
+	}
+
+	policyService.logMu.Lock()
+	defer policyService.logMu.Unlock()
+
+	count := policyService.next
+	if policyService.full {
+		count = len(policyService.decisions)
+	}
+
+	decisions := make([]policyDto.Decision, 0, min(limit, count))
+	for i := 1; i <= count && len(decisions) < limit; i++ {
+		decision := policyService.decisions[(policyService.next-i+len(policyService.decisions))%len(policyService.decisions)]
+		if decision.Tenant != tenant {
+			continue
+		}
+		if filter.Denied && decision.Allowed {
+			continue
+		}
+		if filter.Subject != "" && decision.Subject != filter.Subject {
+			continue
+		}
+		decisions = append(decisions, decision)
+	}
+
+	return decisions
+}
+
+// Attributes collects attributes of token subject, of resource and of request
+// environment, resource parameters are left to caller.
+func (policyService *PolicyService) Attributes(
+	tokenInfo tokenManager.TokenInfo,
+	method string,
+	route string,
+	path string,
+	ip string,
+	userAgent string,
+) policy.Attributes {
+	now := policyService.now().UTC()
+	attributes := policy.Attributes{}
+
+	attributes.Set(policyDto.AttributeSubjectUUID, tokenInfo.UUID)
+	attributes.Set(policyDto.AttributeSubjectTenant, tenantUtil.Normalize(tokenInfo.Tenant))
+	attributes.Set(policyDto.AttributeSubjectRoles, tokenInfo.Roles...)
+	attributes.Set(policyDto.AttributeSubjectGroups, tokenInfo.Groups...)
+	attributes.Set(policyDto.AttributeSubjectScope, strings.Fields(tokenInfo.Scope)...)
+	if tokenInfo.ClientID != "" {
+		attributes.Set(policyDto.AttributeSubjectClient, tokenInfo.ClientID)
+	}
+
+	attributes.Set(policyDto.AttributeResourceMethod, method)
+	attributes.Set(policyDto.AttributeResourceRoute, route)
+	attributes.Set(policyDto.AttributeResourcePath, path)
+
+	if ip != "" {
+		attributes.Set(policyDto.AttributeEnvIP, ip)
+	}
+	attributes.Set(policyDto.AttributeEnvTime, now.Format(time.RFC3339))
+	attributes.Set(policyDto.AttributeEnvWeekday, strings.ToLower(now.Weekday().String()))
+	if userAgent != "" {
+		attributes.Set(policyDto.AttributeEnvUserAgent, userAgent)
+	}
+
+	return attributes
+}
+
+func (policyService *PolicyService) record(decision policyDto.Decision) {
+	policyService.logMu.Lock()
+	defer policyService.logMu.Unlock()
+
+	if len(policyService.decisions) == 0 {
+		return
+	}
+	policyService.decisions[policyService.next] = decision
+	policyService.next = (policyService.next + 1) % len(policyService.decisions)
+	if policyService.next == 0 {
+		policyService.full = true
+	}
+}
+
+func (policyService *PolicyService) compile(path string) (*policy.Engine, error) {
+	if path == "" {
+		return policy.New(nil)
+	}
+
+	return policy.Load(path)
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
@@ -0,0 +1,313 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	roleDto "github.com/elusiv0/medods_test/internal/model/role"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	"github.com/elusiv0/medods_test/internal/repo"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+	"github.com/elusiv0/medods_test/pkg/scope"
+)
+
+var (
+	namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+)
+
+// RoleService manages role definitions and their assignment to users. Users
+// carry only role names, permissions of roles are resolved from definitions
+// cached for cacheTTL, so changed definitions apply to issued tokens as well.
+type RoleService struct {
+	roleRepo     repo.RoleRepo
+	userRepo     repo.UserRepo
+	groupRepo    repo.GroupRepo
+	auditService *auditService.AuditService
+	transactor   repo.Transactor
+	cacheTTL     time.Duration
+	logger       *slog.Logger
+	now          func() time.Time
+
+	mu       sync.Mutex
+	cache    map[string][]string
+	loadedAt time.Time
+}
+
+func New(
+	roleRepo repo.RoleRepo,
+	userRepo repo.UserRepo,
+	groupRepo repo.GroupRepo,
+	auditService *auditService.AuditService,
+	transactor *mongoClient.MongoClient,
+	cacheTTL time.Duration,
+	log *slog.Logger,
+) *RoleService {
+	return &RoleService{
+		roleRepo:     roleRepo,
+		userRepo:     userRepo,
+		groupRepo:    groupRepo,
+		auditService: auditService,
+		transactor:   transactor,
+		cacheTTL:     cacheTTL,
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+func (roleService *RoleService) Create(ctx context.Context, create roleDto.CreateRole) (_ roleDto.Role, err error) {
+	defer func() {
+		roleService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: create.Name,
+			Action:  "create_role",
+		}, err)
+	}()
+
+	permissions, err := normalize(create.Permissions)
+	if err != nil || !namePattern.MatchString(create.Name) {
+		return roleDto.Role{}, fmt.Errorf("RoleService - Create: %w", roleDto.ErrBadRole)
+	}
+
+	role := roleDto.Role{
+		Name:        create.Name,
+		Description: create.Description,
+		Permissions: permissions,
+		CreatedAt:   roleService.now().UTC(),
+	}
+	if err := roleService.roleRepo.InsertRole(ctx, role); err != nil {
+		return roleDto.Role{}, fmt.Errorf("RoleService - Create: %w", err)
+	}
+	roleService.invalidate()
+
+	return role, nil
+}
+
+func (roleService *RoleService) Get(ctx context.Context, name string) (roleDto.Role, error) {
+	role, err := roleService.roleRepo.GetRole(ctx, name)
+	if err != nil {
+		return roleDto.Role{}, fmt.Errorf("RoleService - Get: %w", err)
+	}
+
+	return role, nil
+}
+
+func (roleService *RoleService) List(ctx context.Context) ([]roleDto.Role, error) {
+	roles, err := roleService.roleRepo.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("RoleService - List: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (roleService *RoleService) Update(
+	ctx context.Context,
+	name string,
+	update roleDto.UpdateRole,
+) (_ roleDto.Role, err error) {
+	defer func() {
+		roleService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: name,
+			Action:  "update_role",
+		}, err)
+	}()
+
+	if update.Permissions != nil {
+		permissions, err := normalize(*update.Permissions)
+		if err != nil {
+			return roleDto.Role{}, fmt.Errorf("RoleService - Update: %w", err)
+		}
+		update.Permissions = &permissions
+	}
+
+	role, err := roleService.roleRepo.UpdateRole(ctx, name, update)
+	if err != nil {
+		return roleDto.Role{}, fmt.Errorf("RoleService - Update: %w", err)
+	}
+	roleService.invalidate()
+
+	return role, nil
+}
+
+// Delete removes role and takes it away from every user and group holding it.
+func (roleService *RoleService) Delete(ctx context.Context, name string) (err error) {
+	defer func() {
+		roleService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: name,
+			Action:  "delete_role",
+		}, err)
+	}()
+
+	err = roleService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := roleService.roleRepo.DeleteRole(ctx, name); err != nil {
+			return err
+		}
+
+		unassigned, err := roleService.userRepo.UnassignRole(ctx, name)
+		if err != nil {
+			return err
+		}
+		groups, err := roleService.groupRepo.UnassignRole(ctx, name)
+		if err != nil {
+			return err
+		}
+		roleService.logger.Info(
+			"RoleService: role deleted",
+			slog.String("role", name),
+			slog.Int64("unassigned", unassigned),
+			slog.Int64("groups", groups),
+		)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("RoleService - Delete: %w", err)
+	}
+	roleService.invalidate()
+
+	return nil
+}
+
+func (roleService *RoleService) Assignment(ctx context.Context, uuid string) (roleDto.Assignment, error) {
+	user, err := roleService.userRepo.GetUserByUUID(ctx, uuid)
+	if err != nil {
+		return roleDto.Assignment{}, fmt.Errorf("RoleService - Assignment: %w", err)
+	}
+
+	return roleDto.Assignment{
+		Roles: user.Roles,
+	}, nil
+}
+
+// Assign replaces roles of user checking in the same transaction that they are
+// defined, they get into access tokens issued from now on.
+func (roleService *RoleService) Assign(
+	ctx context.Context,
+	uuid string,
+	assignment roleDto.Assignment,
+) (_ roleDto.Assignment, err error) {
+	defer func() {
+		roleService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: uuid,
+			Action:  "assign_roles",
+		}, err)
+	}()
+
+	roles := slices.Clone(assignment.Roles)
+	slices.Sort(roles)
+	roles = slices.Compact(roles)
+
+	var user userDto.User
+	err = roleService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		for _, name := range roles {
+			if _, err := roleService.roleRepo.GetRole(ctx, name); err != nil {
+				if errors.Is(err, roleDto.ErrRoleNotFound) {
+					return fmt.Errorf("%s: %w", name, roleDto.ErrUnknownRole)
+				}
+				return err
+			}
+		}
+
+		var err error
+		user, err = roleService.userRepo.SetRoles(ctx, uuid, roles)
+		return err
+	})
+	if err != nil {
+		return roleDto.Assignment{}, fmt.Errorf("RoleService - Assign: %w", err)
+	}
+
+	return roleDto.Assignment{
+		Roles: user.Roles,
+	}, nil
+}
+
+// Permissions returns permissions granted by roles, roles which are not defined
+// grant nothing.
+func (roleService *RoleService) Permissions(ctx context.Context, roles []string) ([]string, error) {
+	definitions, err := roleService.definitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("RoleService - Permissions: %w", err)
+	}
+
+	permissions := make([]string, 0)
+	for _, role := range roles {
+		permissions = append(permissions, definitions[role]...)
+	}
+	slices.Sort(permissions)
+
+	return slices.Compact(permissions), nil
+}
+
+// Allowed reports whether roles grant every one of permissions.
+func (roleService *RoleService) Allowed(ctx context.Context, roles []string, permissions ...string) (bool, error) {
+	granted, err := roleService.Permissions(ctx, roles)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range permissions {
+		if !scope.Set(granted).Covers(permission) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (roleService *RoleService) definitions(ctx context.Context) (map[string][]string, error) {
+	roleService.mu.Lock()
+	defer roleService.mu.Unlock()
+
+	if roleService.cache != nil && roleService.now().Sub(roleService.loadedAt) < roleService.cacheTTL {
+		return roleService.cache, nil
+	}
+
+	roles, err := roleService.roleRepo.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := make(map[string][]string, len(roles))
+	for _, role := range roles {
+		cache[role.Name] = role.Permissions
+	}
+	roleService.cache = cache
+	roleService.loadedAt = roleService.now()
+
+	return cache, nil
+}
+
+// invalidate makes the next check reload definitions, other replicas pick the
+// change up once their cache expires.
+func (roleService *RoleService) invalidate() {
+	roleService.mu.Lock()
+	defer roleService.mu.Unlock()
+
+	roleService.cache = nil
+}
+
+func normalize(permissions []string) ([]string, error) {
+	normalized := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		permission = strings.TrimSpace(permission)
+		if !scope.Valid(permission) {
+			return nil, roleDto.ErrBadRole
+		}
+		normalized = append(normalized, permission)
+	}
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized), nil
+}
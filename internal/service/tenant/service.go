@@ -0,0 +1,340 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	tenantDto "github.com/elusiv0/medods_test/internal/model/tenant"
+	"github.com/elusiv0/medods_test/internal/repo"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	"github.com/elusiv0/medods_test/pkg/scope"
+)
+
+var (
+	idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+)
+
+// TenantService manages tenants and resolves the one a request belongs to. The
+// default tenant exists without being stored, storing it only changes its name,
+// hosts and policy. Tenants are cached for cacheTTL.
+type TenantService struct {
+	tenantRepo   repo.TenantRepo
+	auditService *auditService.AuditService
+	cacheTTL     time.Duration
+	fallback     bool
+	logger       *slog.Logger
+	now          func() time.Time
+
+	mu       sync.Mutex
+	cache    map[string]tenantDto.Tenant
+	hosts    map[string]string
+	loadedAt time.Time
+}
+
+func New(
+	tenantRepo repo.TenantRepo,
+	auditService *auditService.AuditService,
+	cacheTTL time.Duration,
+	fallback bool,
+	log *slog.Logger,
+) *TenantService {
+	return &TenantService{
+		tenantRepo:   tenantRepo,
+		auditService: auditService,
+		cacheTTL:     cacheTTL,
+		fallback:     fallback,
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+func (tenantService *TenantService) Create(ctx context.Context, create tenantDto.CreateTenant) (_ tenantDto.Tenant, err error) {
+	defer func() {
+		tenantService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: create.ID,
+			Action:  "create_tenant",
+		}, err)
+	}()
+
+	tenant := tenantDto.Tenant{
+		ID:        create.ID,
+		Name:      strings.TrimSpace(create.Name),
+		Hosts:     normalizeHosts(create.Hosts),
+		Policy:    create.Policy,
+		CreatedAt: tenantService.now().UTC(),
+	}
+	if err := tenantService.validate(ctx, tenant); err != nil {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Create: %w", err)
+	}
+
+	if err := tenantService.tenantRepo.InsertTenant(ctx, tenant); err != nil {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Create: %w", err)
+	}
+	tenantService.invalidate()
+
+	return tenant, nil
+}
+
+func (tenantService *TenantService) Get(ctx context.Context, id string) (tenantDto.Tenant, error) {
+	tenant, err := tenantService.tenantRepo.GetTenant(ctx, id)
+	if err != nil {
+		if id == tenantUtil.DefaultID && errors.Is(err, tenantDto.ErrTenantNotFound) {
+			return defaultTenant(), nil
+		}
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Get: %w", err)
+	}
+
+	return tenant, nil
+}
+
+func (tenantService *TenantService) List(ctx context.Context) ([]tenantDto.Tenant, error) {
+	tenants, err := tenantService.tenantRepo.ListTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("TenantService - List: %w", err)
+	}
+
+	return withDefault(tenants), nil
+}
+
+// Update changes tenant id, the default tenant is stored on its first update
+// and can not be disabled.
+func (tenantService *TenantService) Update(
+	ctx context.Context,
+	id string,
+	update tenantDto.UpdateTenant,
+) (_ tenantDto.Tenant, err error) {
+	defer func() {
+		tenantService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: id,
+			Action:  "update_tenant",
+		}, err)
+	}()
+
+	tenant, err := tenantService.Get(ctx, id)
+	if err != nil {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Update: %w", err)
+	}
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		update.Name = &name
+		tenant.Name = name
+	}
+	if update.Hosts != nil {
+		hosts := normalizeHosts(*update.Hosts)
+		update.Hosts = &hosts
+		tenant.Hosts = hosts
+	}
+	if update.Disabled != nil {
+		tenant.Disabled = *update.Disabled
+	}
+	if update.Policy != nil {
+		tenant.Policy = *update.Policy
+	}
+	if err := tenantService.validate(ctx, tenant); err != nil {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Update: %w", err)
+	}
+
+	if id == tenantUtil.DefaultID {
+		if _, _, err := tenantService.tenantRepo.UpsertTenant(ctx, tenant); err != nil {
+			return tenantDto.Tenant{}, fmt.Errorf("TenantService - Update: %w", err)
+		}
+	}
+	tenant, err = tenantService.tenantRepo.UpdateTenant(ctx, id, update)
+	if err != nil {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Update: %w", err)
+	}
+	tenantService.invalidate()
+
+	return tenant, nil
+}
+
+// Tenant returns cached tenant id.
+func (tenantService *TenantService) Tenant(ctx context.Context, id string) (tenantDto.Tenant, error) {
+	tenants, _, err := tenantService.tenants(ctx)
+	if err != nil {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Tenant: %w", err)
+	}
+
+	tenant, ok := tenants[id]
+	if !ok {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Tenant - %s: %w", id, tenantDto.ErrTenantNotFound)
+	}
+
+	return tenant, nil
+}
+
+// Policy returns policy of tenant of ctx, it fails for disabled tenant.
+func (tenantService *TenantService) Policy(ctx context.Context) (tenantDto.Policy, error) {
+	tenant, err := tenantService.Tenant(ctx, tenantUtil.FromContext(ctx))
+	if err != nil {
+		return tenantDto.Policy{}, fmt.Errorf("TenantService - Policy: %w", err)
+	}
+	if tenant.Disabled {
+		return tenantDto.Policy{}, fmt.Errorf("TenantService - Policy - %s: %w", tenant.ID, tenantDto.ErrTenantDisabled)
+	}
+
+	return tenant.Policy, nil
+}
+
+// Resolve finds tenant of request by its host, then by id passed in header. Host
+// and header naming different tenants are rejected. Requests matching neither
+// belong to the default tenant when fallback is on.
+func (tenantService *TenantService) Resolve(ctx context.Context, host string, header string) (tenantDto.Tenant, error) {
+	tenants, hosts, err := tenantService.tenants(ctx)
+	if err != nil {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Resolve: %w", err)
+	}
+
+	id, byHost := hosts[normalizeHost(host)]
+	switch {
+	case byHost && header != "" && header != id:
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Resolve - %s: %w", header, tenantDto.ErrUnknownTenant)
+	case !byHost && header != "":
+		id = header
+	case !byHost && tenantService.fallback:
+		id = tenantUtil.DefaultID
+	case !byHost:
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Resolve - %s: %w", host, tenantDto.ErrUnknownTenant)
+	}
+
+	tenant, ok := tenants[id]
+	if !ok {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Resolve - %s: %w", id, tenantDto.ErrUnknownTenant)
+	}
+	if tenant.Disabled {
+		return tenantDto.Tenant{}, fmt.Errorf("TenantService - Resolve - %s: %w", id, tenantDto.ErrTenantDisabled)
+	}
+
+	return tenant, nil
+}
+
+func (tenantService *TenantService) tenants(ctx context.Context) (map[string]tenantDto.Tenant, map[string]string, error) {
+	tenantService.mu.Lock()
+	defer tenantService.mu.Unlock()
+
+	if tenantService.cache != nil && tenantService.now().Sub(tenantService.loadedAt) < tenantService.cacheTTL {
+		return tenantService.cache, tenantService.hosts, nil
+	}
+
+	tenants, err := tenantService.tenantRepo.ListTenants(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cache := make(map[string]tenantDto.Tenant, len(tenants)+1)
+	hosts := make(map[string]string)
+	for _, tenant := range withDefault(tenants) {
+		cache[tenant.ID] = tenant
+		for _, host := range tenant.Hosts {
+			hosts[host] = tenant.ID
+		}
+	}
+	tenantService.cache = cache
+	tenantService.hosts = hosts
+	tenantService.loadedAt = tenantService.now()
+
+	return cache, hosts, nil
+}
+
+// invalidate makes the next lookup reload tenants, other replicas pick the
+// change up once their cache expires.
+func (tenantService *TenantService) invalidate() {
+	tenantService.mu.Lock()
+	defer tenantService.mu.Unlock()
+
+	tenantService.cache = nil
+}
+
+// validate checks tenant id, policy and that none of its hosts is taken by
+// another tenant.
+func (tenantService *TenantService) validate(ctx context.Context, tenant tenantDto.Tenant) error {
+	if !idPattern.MatchString(tenant.ID) || !ValidPolicy(tenant.Policy) {
+		return tenantDto.ErrBadTenant
+	}
+	if tenant.ID == tenantUtil.DefaultID && tenant.Disabled {
+		return tenantDto.ErrBadTenant
+	}
+
+	tenants, err := tenantService.tenantRepo.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range tenants {
+		if other.ID == tenant.ID {
+			continue
+		}
+		for _, host := range tenant.Hosts {
+			if slices.Contains(other.Hosts, host) {
+				return fmt.Errorf("%s: %w", host, tenantDto.ErrBadTenant)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidPolicy reports whether durations of policy are not negative and its
+// clients and scopes parse.
+func ValidPolicy(policy tenantDto.Policy) bool {
+	if policy.AccessLifeTime < 0 || policy.RefreshLifeTime < 0 || policy.SessionMaxAge < 0 {
+		return false
+	}
+	if _, err := scope.ParseClients(policy.Clients); err != nil {
+		return false
+	}
+	_, err := scope.Parse(strings.Join(policy.DefaultScopes, " "))
+
+	return err == nil
+}
+
+func defaultTenant() tenantDto.Tenant {
+	return tenantDto.Tenant{
+		ID:    tenantUtil.DefaultID,
+		Name:  "Default",
+		Hosts: []string{},
+	}
+}
+
+func withDefault(tenants []tenantDto.Tenant) []tenantDto.Tenant {
+	for _, tenant := range tenants {
+		if tenant.ID == tenantUtil.DefaultID {
+			return tenants
+		}
+	}
+
+	return append([]tenantDto.Tenant{defaultTenant()}, tenants...)
+}
+
+func normalizeHosts(hosts []string) []string {
+	normalized := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		if host = normalizeHost(host); host != "" {
+			normalized = append(normalized, host)
+		}
+	}
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
+
+// normalizeHost lowercases host and drops its port.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		return hostname
+	}
+
+	return host
+}
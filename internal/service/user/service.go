@@ -0,0 +1,246 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	outboxDto "github.com/elusiv0/medods_test/internal/model/outbox"
+	userDto "github.com/elusiv0/medods_test/internal/model/user"
+	"github.com/elusiv0/medods_test/internal/repo"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	outboxService "github.com/elusiv0/medods_test/internal/service/outbox"
+	mongoClient "github.com/elusiv0/medods_test/pkg/mongo"
+)
+
+type UserService struct {
+	userRepo      repo.UserRepo
+	tokenRepo     repo.TokenRepo
+	groupRepo     repo.GroupRepo
+	auditService  *auditService.AuditService
+	outboxService *outboxService.OutboxService
+	transactor    repo.Transactor
+	logger        *slog.Logger
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+func New(
+	userRepo repo.UserRepo,
+	tokenRepo repo.TokenRepo,
+	groupRepo repo.GroupRepo,
+	auditService *auditService.AuditService,
+	outboxService *outboxService.OutboxService,
+	transactor *mongoClient.MongoClient,
+	log *slog.Logger,
+) *UserService {
+	return &UserService{
+		userRepo:      userRepo,
+		tokenRepo:     tokenRepo,
+		groupRepo:     groupRepo,
+		auditService:  auditService,
+		outboxService: outboxService,
+		transactor:    transactor,
+		logger:        log,
+	}
+}
+
+// Create inserts user and its user.created event in one transaction.
+func (userService *UserService) Create(ctx context.Context, create userDto.CreateUser) (_ userDto.User, err error) {
+	create.Name = strings.TrimSpace(create.Name)
+	if create.Name == "" {
+		return userDto.User{}, fmt.Errorf("UserService - Create: %w", userDto.ErrBadUser)
+	}
+
+	var uuid string
+	defer func() {
+		userService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: uuid,
+			Action:  "create_user",
+		}, err)
+	}()
+
+	err = userService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		uuid, err = userService.userRepo.InsertUser(ctx, create)
+		if err != nil {
+			return err
+		}
+
+		return userService.outboxService.Enqueue(ctx, outboxDto.TypeUserCreated, uuid, map[string]string{
+			"name": create.Name,
+		})
+	})
+	if err != nil {
+		return userDto.User{}, fmt.Errorf("UserService - Create: %w", err)
+	}
+
+	return userDto.User{
+		UUID: uuid,
+		Name: create.Name,
+	}, nil
+}
+
+func (userService *UserService) Get(ctx context.Context, uuid string) (userDto.User, error) {
+	user, err := userService.userRepo.GetUserByUUID(ctx, uuid)
+	if err != nil {
+		return userDto.User{}, fmt.Errorf("UserService - Get: %w", err)
+	}
+
+	return user, nil
+}
+
+// Update changes fields set in update and publishes user.updated.
+func (userService *UserService) Update(ctx context.Context, uuid string, update userDto.UpdateUser) (user userDto.User, err error) {
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return userDto.User{}, fmt.Errorf("UserService - Update: %w", userDto.ErrBadUser)
+		}
+		update.Name = &name
+	}
+
+	defer func() {
+		userService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: uuid,
+			Action:  "update_user",
+		}, err)
+	}()
+
+	err = userService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = userService.userRepo.UpdateUser(ctx, uuid, update)
+		if err != nil {
+			return err
+		}
+
+		return userService.outboxService.Enqueue(ctx, outboxDto.TypeUserUpdated, uuid, map[string]string{
+			"name": user.Name,
+		})
+	})
+	if err != nil {
+		return userDto.User{}, fmt.Errorf("UserService - Update: %w", err)
+	}
+
+	return user, nil
+}
+
+func (userService *UserService) List(ctx context.Context, filter userDto.Filter) (userDto.Page, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultPageSize
+	}
+	if filter.Limit > maxPageSize {
+		filter.Limit = maxPageSize
+	}
+
+	users, err := userService.userRepo.ListUsers(ctx, filter)
+	if err != nil {
+		return userDto.Page{}, fmt.Errorf("UserService - List: %w", err)
+	}
+
+	page := userDto.Page{
+		Users: users,
+	}
+	if len(users) == filter.Limit {
+		page.NextCursor = users[len(users)-1].UUID
+	}
+
+	return page, nil
+}
+
+// Disable forbids user to sign in and ends all of its sessions.
+func (userService *UserService) Disable(ctx context.Context, uuid string) (err error) {
+	defer func() {
+		userService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: uuid,
+			Action:  "disable_user",
+		}, err)
+	}()
+
+	err = userService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := userService.userRepo.SetDisabled(ctx, uuid, true); err != nil {
+			return err
+		}
+
+		revoked, err := userService.tokenRepo.DeleteUserTokens(ctx, uuid)
+		if err != nil {
+			return err
+		}
+
+		return userService.outboxService.Enqueue(ctx, outboxDto.TypeUserDisabled, uuid, map[string]string{
+			"revoked": strconv.FormatInt(revoked, 10),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("UserService - Disable: %w", err)
+	}
+
+	return nil
+}
+
+// Enable lets disabled user sign in again, its sessions ended on disable stay ended.
+func (userService *UserService) Enable(ctx context.Context, uuid string) (err error) {
+	defer func() {
+		userService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: uuid,
+			Action:  "enable_user",
+		}, err)
+	}()
+
+	err = userService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := userService.userRepo.SetDisabled(ctx, uuid, false); err != nil {
+			return err
+		}
+
+		return userService.outboxService.Enqueue(ctx, outboxDto.TypeUserEnabled, uuid, map[string]string{})
+	})
+	if err != nil {
+		return fmt.Errorf("UserService - Enable: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes user together with its sessions and group memberships.
+func (userService *UserService) Delete(ctx context.Context, uuid string) (err error) {
+	defer func() {
+		userService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: uuid,
+			Action:  "delete_user",
+		}, err)
+	}()
+
+	err = userService.transactor.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := userService.userRepo.DeleteUser(ctx, uuid); err != nil {
+			return err
+		}
+
+		revoked, err := userService.tokenRepo.DeleteUserTokens(ctx, uuid)
+		if err != nil {
+			return err
+		}
+		if _, err := userService.groupRepo.RemoveUser(ctx, uuid); err != nil {
+			return err
+		}
+
+		return userService.outboxService.Enqueue(ctx, outboxDto.TypeUserDeleted, uuid, map[string]string{
+			"revoked": strconv.FormatInt(revoked, 10),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("UserService - Delete: %w", err)
+	}
+
+	return nil
+}
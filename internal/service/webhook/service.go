@@ -0,0 +1,365 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	eventDto "github.com/elusiv0/medods_test/internal/model/event"
+	webhookDto "github.com/elusiv0/medods_test/internal/model/webhook"
+	"github.com/elusiv0/medods_test/internal/repo"
+	webhookRepository "github.com/elusiv0/medods_test/internal/repo/webhook"
+	auditService "github.com/elusiv0/medods_test/internal/service/audit"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+	"github.com/elusiv0/medods_test/pkg/webhook"
+)
+
+type Policy struct {
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+	Lease        time.Duration
+	BatchSize    int
+}
+
+type WebhookService struct {
+	webhookRepo  repo.WebhookRepo
+	sender       *webhook.Sender
+	auditService *auditService.AuditService
+	policy       Policy
+	logger       *slog.Logger
+	now          func() time.Time
+}
+
+var _ eventDto.Hook = (*WebhookService)(nil)
+
+func New(
+	webhookRepo *webhookRepository.WebhookRepo,
+	sender *webhook.Sender,
+	auditService *auditService.AuditService,
+	policy Policy,
+	log *slog.Logger,
+) *WebhookService {
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		sender:       sender,
+		auditService: auditService,
+		policy:       policy,
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+// Create registers subscription, generated secret is returned only here.
+func (webhookService *WebhookService) Create(
+	ctx context.Context,
+	create webhookDto.CreateSubscription,
+) (subscription webhookDto.Subscription, err error) {
+	defer func() {
+		webhookService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: subscription.ID,
+			Action:  "create_webhook",
+		}, err)
+	}()
+
+	if err := validate(create.URL, create.Events); err != nil {
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookService - Create: %w", err)
+	}
+
+	secret := create.Secret
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return webhookDto.Subscription{}, fmt.Errorf("WebhookService - Create: %w", err)
+		}
+		secret = hex.EncodeToString(b)
+	}
+
+	subscription = webhookDto.Subscription{
+		URL:       create.URL,
+		Events:    create.Events,
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: webhookService.now().UTC(),
+	}
+
+	id, err := webhookService.webhookRepo.InsertSubscription(ctx, subscription)
+	if err != nil {
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookService - Create: %w", err)
+	}
+	subscription.ID = id
+
+	return subscription, nil
+}
+
+func (webhookService *WebhookService) Get(ctx context.Context, id string) (webhookDto.Subscription, error) {
+	subscription, err := webhookService.webhookRepo.GetSubscription(ctx, id)
+	if err != nil {
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookService - Get: %w", err)
+	}
+	subscription.Secret = ""
+
+	return subscription, nil
+}
+
+func (webhookService *WebhookService) List(ctx context.Context) ([]webhookDto.Subscription, error) {
+	subscriptions, err := webhookService.webhookRepo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookService - List: %w", err)
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+func (webhookService *WebhookService) Update(
+	ctx context.Context,
+	id string,
+	update webhookDto.UpdateSubscription,
+) (_ webhookDto.Subscription, err error) {
+	defer func() {
+		webhookService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: id,
+			Action:  "update_webhook",
+		}, err)
+	}()
+
+	current, err := webhookService.webhookRepo.GetSubscription(ctx, id)
+	if err != nil {
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookService - Update: %w", err)
+	}
+
+	target, events := current.URL, current.Events
+	if update.URL != nil {
+		target = *update.URL
+	}
+	if update.Events != nil {
+		events = *update.Events
+	}
+	if err := validate(target, events); err != nil {
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookService - Update: %w", err)
+	}
+
+	subscription, err := webhookService.webhookRepo.UpdateSubscription(ctx, id, update)
+	if err != nil {
+		return webhookDto.Subscription{}, fmt.Errorf("WebhookService - Update: %w", err)
+	}
+	subscription.Secret = ""
+
+	return subscription, nil
+}
+
+func (webhookService *WebhookService) Delete(ctx context.Context, id string) (err error) {
+	defer func() {
+		webhookService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: id,
+			Action:  "delete_webhook",
+		}, err)
+	}()
+
+	if err := webhookService.webhookRepo.DeleteSubscription(ctx, id); err != nil {
+		return fmt.Errorf("WebhookService - Delete: %w", err)
+	}
+
+	return nil
+}
+
+func (webhookService *WebhookService) Deliveries(
+	ctx context.Context,
+	filter webhookDto.DeliveryFilter,
+) ([]webhookDto.Delivery, error) {
+	deliveries, err := webhookService.webhookRepo.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookService - Deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver moves delivery, usually a dead one, back to the queue.
+func (webhookService *WebhookService) Redeliver(ctx context.Context, id string) (_ webhookDto.Delivery, err error) {
+	defer func() {
+		webhookService.auditService.RecordResult(ctx, auditDto.Entry{
+			Type:    auditDto.TypeAdminAction,
+			Subject: id,
+			Action:  "redeliver_webhook",
+		}, err)
+	}()
+
+	delivery, err := webhookService.webhookRepo.RequeueDelivery(ctx, id, webhookService.now())
+	if err != nil {
+		return webhookDto.Delivery{}, fmt.Errorf("WebhookService - Redeliver: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// Handle queues delivery of event for every matching subscription of tenant of ctx.
+func (webhookService *WebhookService) Handle(ctx context.Context, event eventDto.Event) {
+	subscriptions, err := webhookService.webhookRepo.ListActiveSubscriptions(ctx, event.Type)
+	if err != nil {
+		webhookService.logger.Error("WebhookService - Handle: " + err.Error())
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		webhookService.logger.Error("WebhookService - Handle: " + err.Error())
+		return
+	}
+
+	now := webhookService.now().UTC()
+	deliveries := make([]webhookDto.Delivery, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		deliveries = append(deliveries, webhookDto.Delivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        string(payload),
+			Status:         webhookDto.StatusPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+
+	if err := webhookService.webhookRepo.InsertDeliveries(ctx, deliveries); err != nil {
+		webhookService.logger.Error("WebhookService - Handle: " + err.Error())
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (webhookService *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookService.policy.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			webhookService.deliverDue(ctx)
+		}
+	}
+}
+
+func (webhookService *WebhookService) deliverDue(ctx context.Context) {
+	for i := 0; i < webhookService.policy.BatchSize; i++ {
+		delivery, err := webhookService.webhookRepo.ClaimDelivery(ctx, webhookService.now(), webhookService.policy.Lease)
+		if err != nil {
+			if !errors.Is(err, webhookDto.ErrDeliveryNotFound) {
+				webhookService.logger.Error("WebhookService - deliverDue: " + err.Error())
+			}
+			return
+		}
+
+		webhookService.deliver(ctx, delivery)
+	}
+}
+
+func (webhookService *WebhookService) deliver(ctx context.Context, delivery webhookDto.Delivery) {
+	ctx = tenantUtil.WithTenant(ctx, delivery.Tenant)
+	subscription, err := webhookService.webhookRepo.GetSubscription(ctx, delivery.SubscriptionID)
+	switch {
+	case errors.Is(err, webhookDto.ErrSubscriptionNotFound):
+		webhookService.finish(ctx, delivery, 0, errors.New("subscription was deleted"), true)
+		return
+	case err != nil:
+		webhookService.logger.Error("WebhookService - deliver: " + err.Error())
+		return
+	case !subscription.Active:
+		webhookService.finish(ctx, delivery, 0, errors.New("subscription is disabled"), true)
+		return
+	}
+
+	statusCode, err := webhookService.sender.Send(ctx, webhook.Message{
+		URL:        subscription.URL,
+		Secret:     subscription.Secret,
+		Event:      delivery.EventType,
+		DeliveryID: delivery.ID,
+		Body:       []byte(delivery.Payload),
+	})
+	webhookService.finish(ctx, delivery, statusCode, err, false)
+}
+
+func (webhookService *WebhookService) finish(
+	ctx context.Context,
+	delivery webhookDto.Delivery,
+	statusCode int,
+	sendErr error,
+	dead bool,
+) {
+	now := webhookService.now()
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.UpdatedAt = now
+	delivery.LastError = ""
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = webhookDto.StatusSucceeded
+	case dead || delivery.Attempts >= webhookService.policy.MaxAttempts:
+		delivery.Status = webhookDto.StatusDead
+		delivery.LastError = sendErr.Error()
+		webhookService.logger.Warn(
+			"WebhookService: delivery moved to dead letters",
+			slog.String("delivery", delivery.ID),
+			slog.String("error", sendErr.Error()),
+		)
+	default:
+		delivery.Status = webhookDto.StatusPending
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = now.Add(webhookService.backoff(delivery.Attempts))
+	}
+
+	if err := webhookService.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		webhookService.logger.Error("WebhookService - finish: " + err.Error())
+	}
+}
+
+func (webhookService *WebhookService) backoff(attempts int) time.Duration {
+	backoff := webhookService.policy.BaseBackoff
+	for i := 1; i < attempts && backoff < webhookService.policy.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookService.policy.MaxBackoff {
+		backoff = webhookService.policy.MaxBackoff
+	}
+
+	return backoff
+}
+
+func validate(rawURL string, events []string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return webhookDto.ErrBadSubscription
+	}
+
+	if len(events) == 0 {
+		return webhookDto.ErrBadSubscription
+	}
+	for _, event := range events {
+		if event != webhookDto.AllEvents && !slices.Contains(eventDto.Types, event) {
+			return webhookDto.ErrBadSubscription
+		}
+	}
+
+	return nil
+}
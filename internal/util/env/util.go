@@ -0,0 +1,24 @@
+package env
+
+import (
+	"slices"
+)
+
+const (
+	Local = "local"
+	Dev   = "dev"
+	Test  = "test"
+	Prod  = "prod"
+)
+
+// All lists accepted values of ENV.
+var All = []string{Local, Dev, Test, Prod}
+
+// NonProduction lists environments fixtures and in-process stand-ins of
+// external services are allowed in.
+var NonProduction = []string{Local, Dev, Test}
+
+// IsNonProduction reports whether env is one of NonProduction.
+func IsNonProduction(env string) bool {
+	return slices.Contains(NonProduction, env)
+}
@@ -0,0 +1,55 @@
+package hashchain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+)
+
+type canonicalEntry struct {
+	Seq       int64  `json:"seq"`
+	PrevHash  string `json:"prev_hash"`
+	Tenant    string `json:"tenant,omitempty"`
+	Type      string `json:"type"`
+	Action    string `json:"action"`
+	Actor     string `json:"actor"`
+	Subject   string `json:"subject"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	Outcome   string `json:"outcome"`
+	Reason    string `json:"reason"`
+	CreatedAt string `json:"created_at"`
+}
+
+// Hash returns hex encoded SHA-256 of entry content linked to previous entry hash.
+// CreatedAt is taken with millisecond precision, that is what mongo keeps.
+// Default tenant is left out, so that entries written before tenants were
+// introduced keep their hashes.
+func Hash(entry auditDto.Entry) string {
+	tenant := entry.Tenant
+	if tenant == tenantUtil.DefaultID {
+		tenant = ""
+	}
+
+	data, _ := json.Marshal(canonicalEntry{
+		Seq:       entry.Seq,
+		PrevHash:  entry.PrevHash,
+		Tenant:    tenant,
+		Type:      entry.Type,
+		Action:    entry.Action,
+		Actor:     entry.Actor,
+		Subject:   entry.Subject,
+		IP:        entry.IP,
+		UserAgent: entry.UserAgent,
+		Outcome:   entry.Outcome,
+		Reason:    entry.Reason,
+		CreatedAt: entry.CreatedAt.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano),
+	})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
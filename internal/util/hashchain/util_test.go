@@ -0,0 +1,58 @@
+package hashchain
+
+import (
+	"testing"
+	"time"
+
+	auditDto "github.com/elusiv0/medods_test/internal/model/audit"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
+)
+
+func TestHash(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 123_000_000, time.UTC)
+	entry := auditDto.Entry{
+		Seq:       2,
+		PrevHash:  "prev",
+		Type:      auditDto.TypeSignIn,
+		Subject:   "u-1",
+		Outcome:   auditDto.OutcomeSuccess,
+		CreatedAt: createdAt,
+	}
+	hash := Hash(entry)
+
+	for name, mutate := range map[string]func(entry *auditDto.Entry){
+		"seq":       func(entry *auditDto.Entry) { entry.Seq++ },
+		"prev hash": func(entry *auditDto.Entry) { entry.PrevHash = "other" },
+		"tenant":    func(entry *auditDto.Entry) { entry.Tenant = "acme" },
+		"type":      func(entry *auditDto.Entry) { entry.Type = auditDto.TypeRefresh },
+		"action":    func(entry *auditDto.Entry) { entry.Action = "unlock" },
+		"actor":     func(entry *auditDto.Entry) { entry.Actor = "admin" },
+		"subject":   func(entry *auditDto.Entry) { entry.Subject = "u-2" },
+		"ip":        func(entry *auditDto.Entry) { entry.IP = "203.0.113.1" },
+		"outcome":   func(entry *auditDto.Entry) { entry.Outcome = auditDto.OutcomeFailure },
+		"reason":    func(entry *auditDto.Entry) { entry.Reason = "denied" },
+		"created":   func(entry *auditDto.Entry) { entry.CreatedAt = entry.CreatedAt.Add(time.Millisecond) },
+	} {
+		changed := entry
+		mutate(&changed)
+		if Hash(changed) == hash {
+			t.Errorf("changing %s keeps the hash", name)
+		}
+	}
+
+	for name, mutate := range map[string]func(entry *auditDto.Entry){
+		"default tenant": func(entry *auditDto.Entry) { entry.Tenant = tenantUtil.DefaultID },
+		"sub-millisecond": func(entry *auditDto.Entry) {
+			entry.CreatedAt = entry.CreatedAt.Add(999 * time.Microsecond)
+		},
+		"time zone": func(entry *auditDto.Entry) { entry.CreatedAt = entry.CreatedAt.In(time.FixedZone("UTC+3", 3*3600)) },
+		"id":        func(entry *auditDto.Entry) { entry.ID = "663200000000000000000001" },
+		"own hash":  func(entry *auditDto.Entry) { entry.Hash = hash },
+	} {
+		same := entry
+		mutate(&same)
+		if Hash(same) != hash {
+			t.Errorf("changing %s changes the hash", name)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package request
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/elusiv0/medods_test/internal/model/api"
@@ -20,3 +21,35 @@ func GetIdFromQueryPath(c *gin.Context) (string, error) {
 
 	return uuid, nil
 }
+
+type Info struct {
+	IP        string
+	UserAgent string
+	Actor     string
+}
+
+type infoKey struct{}
+
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+func InfoFromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(infoKey{}).(Info)
+	return info
+}
+
+// SetInfo stores client address and user agent of request into its context,
+// actor is kept from already stored info.
+func SetInfo(c *gin.Context, actor string) {
+	ctx := c.Request.Context()
+	if actor == "" {
+		actor = InfoFromContext(ctx).Actor
+	}
+
+	c.Request = c.Request.WithContext(WithInfo(ctx, Info{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Actor:     actor,
+	}))
+}
@@ -0,0 +1,33 @@
+package tenant
+
+import (
+	"context"
+)
+
+// DefaultID is tenant of requests no tenant was resolved for and of data stored
+// before tenants were introduced.
+const DefaultID = "default"
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext returns tenant stored by WithTenant, DefaultID when there is none.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(tenantKey{}).(string); ok && id != "" {
+		return id
+	}
+
+	return DefaultID
+}
+
+// Normalize maps empty tenant of claims and documents to DefaultID.
+func Normalize(id string) string {
+	if id == "" {
+		return DefaultID
+	}
+
+	return id
+}
@@ -1,27 +1,51 @@
 package token
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/elusiv0/medods_test/internal/model/api"
-	"github.com/elusiv0/medods_test/internal/util/hash"
+	tenantUtil "github.com/elusiv0/medods_test/internal/util/tenant"
 	"github.com/golang-jwt/jwt/v5"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TokenManager struct {
-	lifeTime time.Duration
-	secret   string
+	mu              sync.RWMutex
+	lifeTime        time.Duration
+	secret          string
+	previousSecrets []string
+	activeKeyIds    map[string]string
+	keys            map[string]SigningKey
 }
 
+// SigningKey is secret of a tenant, tokens signed with it are valid only when
+// their tenant claim names the same tenant.
+type SigningKey struct {
+	Tenant string
+	Secret []byte
+}
+
+// TokenInfo references refresh token only by its session id, the token itself is
+// never exposed in access token. Roles are the ones user held at issue directly or
+// through Groups, Scope is space delimited list of scopes granted to ClientID. Tenant is empty in tokens
+// of the default tenant issued before tenants were introduced.
 type TokenInfo struct {
-	UUID         string             `json:"uuid"`
-	RefreshToken string             `json:"refresh_token"`
-	RefreshId    primitive.ObjectID `json:"refresh_id`
+	UUID      string             `json:"uuid"`
+	Tenant    string             `json:"tenant,omitempty"`
+	SessionId primitive.ObjectID `json:"sid"`
+	Roles     []string           `json:"roles,omitempty"`
+	Groups    []string           `json:"groups,omitempty"`
+	Scope     string             `json:"scope,omitempty"`
+	ClientID  string             `json:"client_id,omitempty"`
 }
 type Claims struct {
 	TokenInfo
@@ -30,35 +54,124 @@ type Claims struct {
 
 func New(time time.Duration, secret string) *TokenManager {
 	return &TokenManager{
-		lifeTime: time,
-		secret:   secret,
+		lifeTime:     time,
+		secret:       secret,
+		activeKeyIds: make(map[string]string),
+		keys:         make(map[string]SigningKey),
+	}
+}
+
+// SetKeys replaces signing keys. Access tokens of a tenant are signed with its key
+// from activeKeyIds and verified with any key of that tenant, tenants without
+// active key use the configured secret. Tokens without key id are always verified
+// with the configured secret.
+func (tokenManager *TokenManager) SetKeys(activeKeyIds map[string]string, keys map[string]SigningKey) {
+	tokenManager.mu.Lock()
+	defer tokenManager.mu.Unlock()
+
+	tokenManager.activeKeyIds = activeKeyIds
+	tokenManager.keys = keys
+}
+
+// SetLifeTime changes lifetime of access tokens issued from now on.
+func (tokenManager *TokenManager) SetLifeTime(lifeTime time.Duration) {
+	tokenManager.mu.Lock()
+	defer tokenManager.mu.Unlock()
+
+	tokenManager.lifeTime = lifeTime
+}
+
+// SetSecrets replaces the configured secret. Previous secrets no longer sign
+// anything but keep verifying access tokens without key id, refresh tokens and
+// audit checkpoints issued with them, so that the secret can be rotated.
+func (tokenManager *TokenManager) SetSecrets(secret string, previous []string) {
+	tokenManager.mu.Lock()
+	defer tokenManager.mu.Unlock()
+
+	tokenManager.secret = secret
+	tokenManager.previousSecrets = previous
+}
+
+// secrets returns the configured secret followed by previous ones.
+func (tokenManager *TokenManager) secrets() []string {
+	tokenManager.mu.RLock()
+	defer tokenManager.mu.RUnlock()
+
+	secrets := make([]string, 0, len(tokenManager.previousSecrets)+1)
+	secrets = append(secrets, tokenManager.secret)
+
+	return append(secrets, tokenManager.previousSecrets...)
+}
+
+func (tokenManager *TokenManager) currentSecret() string {
+	tokenManager.mu.RLock()
+	defer tokenManager.mu.RUnlock()
+
+	return tokenManager.secret
+}
+
+func (tokenManager *TokenManager) signingKey(tenant string) (string, []byte) {
+	tokenManager.mu.RLock()
+	defer tokenManager.mu.RUnlock()
+
+	keyId := tokenManager.activeKeyIds[tenantUtil.Normalize(tenant)]
+	if key, ok := tokenManager.keys[keyId]; ok {
+		return keyId, key.Secret
 	}
+
+	return "", []byte(tokenManager.secret)
 }
 
-func (tokenManager *TokenManager) CheckTokenMatch(tokenInfo TokenInfo, refreshToken string) (bool, error) {
-	if err := hash.Compare(tokenInfo.RefreshToken, refreshToken); err != nil {
-		return false, fmt.Errorf("TokenManager - CheckTokenMatch: %w", api.ErrTokenMismatch)
+func (tokenManager *TokenManager) verificationKey(t *jwt.Token) (interface{}, error) {
+	keyId, _ := t.Header["kid"].(string)
+	if keyId == "" {
+		keySet := jwt.VerificationKeySet{}
+		for _, secret := range tokenManager.secrets() {
+			keySet.Keys = append(keySet.Keys, []byte(secret))
+		}
+		return keySet, nil
+	}
+
+	tokenManager.mu.RLock()
+	defer tokenManager.mu.RUnlock()
+
+	key, ok := tokenManager.keys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", keyId)
+	}
+	claims, ok := t.Claims.(*Claims)
+	if !ok || tenantUtil.Normalize(claims.Tenant) != key.Tenant {
+		return nil, fmt.Errorf("signing key %q belongs to another tenant", keyId)
 	}
 
-	return true, nil
+	return key.Secret, nil
 }
 
-func (tokenManager *TokenManager) NewJWTToken(refreshToken string, uuid string, id primitive.ObjectID) (string, error) {
+// NewJWTToken signs access token with key of tokenInfo.Tenant, it expires after
+// lifeTime or after the configured lifetime when lifeTime is zero.
+func (tokenManager *TokenManager) NewJWTToken(tokenInfo TokenInfo, lifeTime time.Duration) (string, error) {
+	if lifeTime <= 0 {
+		tokenManager.mu.RLock()
+		lifeTime = tokenManager.lifeTime
+		tokenManager.mu.RUnlock()
+	}
+
 	claims := &Claims{
-		TokenInfo{
-			UUID:         uuid,
-			RefreshToken: refreshToken,
-			RefreshId:    id,
-		},
+		tokenInfo,
 		jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenManager.lifeTime)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(lifeTime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
 
-	tok, err := token.SignedString([]byte(tokenManager.secret))
+	keyId, key := tokenManager.signingKey(tokenInfo.Tenant)
+	if keyId != "" {
+		token.Header["kid"] = keyId
+	}
+
+	tok, err := token.SignedString(key)
 	if err != nil {
 		return "", fmt.Errorf("TokenManager - NewJWTToken - SignedString: %w", err)
 	}
@@ -69,14 +182,19 @@ func (tokenManager *TokenManager) NewJWTToken(refreshToken string, uuid string,
 func (tokenManager *TokenManager) ValidateJWT(accessToken string) (TokenInfo, error) {
 	claims := &Claims{}
 
-	token, err := jwt.ParseWithClaims(accessToken, claims, func(t *jwt.Token) (interface{}, error) {
-		return []byte(tokenManager.secret), nil
-	})
+	token, err := jwt.ParseWithClaims(
+		accessToken,
+		claims,
+		tokenManager.verificationKey,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS512.Alg()}),
+	)
 
 	switch {
-	case token.Valid:
+	case token != nil && token.Valid:
 		return token.Claims.(*Claims).TokenInfo, nil
-	case errors.Is(err, jwt.ErrTokenMalformed) || errors.Is(err, jwt.ErrTokenSignatureInvalid):
+	case errors.Is(err, jwt.ErrTokenMalformed) ||
+		errors.Is(err, jwt.ErrTokenSignatureInvalid) ||
+		errors.Is(err, jwt.ErrTokenUnverifiable):
 		return TokenInfo{}, fmt.Errorf("TokenManager - ValidateJwt: %w", api.ErrInvalidAccessToken)
 	case errors.Is(err, jwt.ErrTokenExpired) || errors.Is(err, jwt.ErrTokenNotValidYet):
 		return token.Claims.(*Claims).TokenInfo, fmt.Errorf("TokenManager - ValidateJwt: %w", api.ErrAccessTokenExpired)
@@ -85,10 +203,87 @@ func (tokenManager *TokenManager) ValidateJWT(accessToken string) (TokenInfo, er
 	}
 }
 
-func (tokenManager *TokenManager) NewRefreshToken() (string, error) {
+// Inspect decodes access token without verifying it, for diagnostics only.
+func (tokenManager *TokenManager) Inspect(accessToken string) (map[string]interface{}, Claims, error) {
+	claims := Claims{}
+
+	token, _, err := jwt.NewParser().ParseUnverified(accessToken, &claims)
+	if err != nil {
+		return nil, Claims{}, fmt.Errorf("TokenManager - Inspect: %w", api.ErrInvalidAccessToken)
+	}
+
+	return token.Header, claims, nil
+}
+
+// NewRefreshToken returns opaque refresh token "<id>.<secret>" of session id and
+// digest of its secret, only the digest is stored.
+func (tokenManager *TokenManager) NewRefreshToken(id primitive.ObjectID) (string, string, error) {
 	b := make([]byte, 32)
 	if _, err := rand.Read(b); err != nil {
-		return "", fmt.Errorf("TokenManager - NewRefreshToken: %w", err)
+		return "", "", fmt.Errorf("TokenManager - NewRefreshToken: %w", err)
+	}
+	secret := hex.EncodeToString(b)
+
+	return id.Hex() + "." + secret, hex.EncodeToString(refreshDigest(tokenManager.currentSecret(), secret)), nil
+}
+
+// ParseRefreshToken splits refresh token into session id and secret.
+func (tokenManager *TokenManager) ParseRefreshToken(refreshToken string) (primitive.ObjectID, string, error) {
+	rawId, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || secret == "" {
+		return primitive.NilObjectID, "", fmt.Errorf("TokenManager - ParseRefreshToken: %w", api.ErrTokenMismatch)
+	}
+
+	id, err := primitive.ObjectIDFromHex(rawId)
+	if err != nil {
+		return primitive.NilObjectID, "", fmt.Errorf("TokenManager - ParseRefreshToken: %w", api.ErrTokenMismatch)
 	}
-	return hex.EncodeToString(b), nil
+
+	return id, secret, nil
+}
+
+// CheckRefreshSecret compares secret with stored digest in constant time.
+func (tokenManager *TokenManager) CheckRefreshSecret(secret, digest string) error {
+	expected, err := hex.DecodeString(digest)
+	if err == nil {
+		for _, key := range tokenManager.secrets() {
+			if hmac.Equal(refreshDigest(key, secret), expected) {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("TokenManager - CheckRefreshSecret: %w", api.ErrTokenMismatch)
+}
+
+func refreshDigest(key string, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte("refresh:" + secret))
+
+	return mac.Sum(nil)
+}
+
+// Sign returns hex encoded HMAC-SHA512 of data keyed with the signing secret.
+func (tokenManager *TokenManager) Sign(data []byte) string {
+	mac := hmac.New(sha512.New, []byte(tokenManager.currentSecret()))
+	mac.Write(data)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (tokenManager *TokenManager) VerifySignature(data []byte, signature string) bool {
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	for _, secret := range tokenManager.secrets() {
+		mac := hmac.New(sha512.New, []byte(secret))
+		mac.Write(data)
+		if hmac.Equal(mac.Sum(nil), expected) {
+			return true
+		}
+	}
+
+	return false
 }
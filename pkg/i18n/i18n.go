@@ -0,0 +1,149 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+type Catalog struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[string]string
+	tags          []language.Tag
+	matcher       language.Matcher
+}
+
+func New(defaultLocale string) *Catalog {
+	catalog := &Catalog{
+		defaultLocale: normalize(defaultLocale),
+		messages:      make(map[string]map[string]string),
+	}
+	catalog.rebuildMatcher()
+
+	return catalog
+}
+
+func (catalog *Catalog) DefaultLocale() string {
+	return catalog.defaultLocale
+}
+
+func (catalog *Catalog) Add(locale string, messages map[string]string) {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	locale = normalize(locale)
+	if _, ok := catalog.messages[locale]; !ok {
+		catalog.messages[locale] = make(map[string]string, len(messages))
+	}
+	for code, message := range messages {
+		catalog.messages[locale][code] = message
+	}
+
+	catalog.rebuildMatcher()
+}
+
+// LoadFS reads every <locale>.json, <locale>.yaml or <locale>.yml file in dir,
+// later files override messages already present in the catalog.
+func (catalog *Catalog) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("I18n - LoadFS - ReadDir: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		ext := path.Ext(name)
+		locale := strings.TrimSuffix(name, ext)
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("I18n - LoadFS - ReadFile: %w", err)
+		}
+
+		messages := make(map[string]string)
+		switch ext {
+		case ".json":
+			err = json.Unmarshal(data, &messages)
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, &messages)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("I18n - LoadFS - parse %s: %w", name, err)
+		}
+
+		catalog.Add(locale, messages)
+	}
+
+	return nil
+}
+
+func (catalog *Catalog) LoadDir(dir string) error {
+	return catalog.LoadFS(os.DirFS(dir), ".")
+}
+
+// Locale picks the best supported locale for an Accept-Language header value.
+func (catalog *Catalog) Locale(acceptLanguage string) string {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	if acceptLanguage == "" {
+		return catalog.defaultLocale
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return catalog.defaultLocale
+	}
+
+	_, index, confidence := catalog.matcher.Match(tags...)
+	if confidence == language.No {
+		return catalog.defaultLocale
+	}
+
+	base, _ := catalog.tags[index].Base()
+	return base.String()
+}
+
+// Message returns the message for code in locale, falling back to the default locale.
+func (catalog *Catalog) Message(locale, code string) (string, bool) {
+	catalog.mu.RLock()
+	defer catalog.mu.RUnlock()
+
+	if message, ok := catalog.messages[normalize(locale)][code]; ok {
+		return message, true
+	}
+	message, ok := catalog.messages[catalog.defaultLocale][code]
+
+	return message, ok
+}
+
+func (catalog *Catalog) rebuildMatcher() {
+	tags := []language.Tag{language.Make(catalog.defaultLocale)}
+	for locale := range catalog.messages {
+		if locale != catalog.defaultLocale {
+			tags = append(tags, language.Make(locale))
+		}
+	}
+
+	catalog.tags = tags
+	catalog.matcher = language.NewMatcher(tags)
+}
+
+func normalize(locale string) string {
+	base, _ := language.Make(locale).Base()
+	return base.String()
+}
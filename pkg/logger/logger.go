@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 
@@ -14,32 +15,25 @@ const (
 	prod  = "prod"
 )
 
-func New(env string) (log *slog.Logger) {
+// New builds logger of env, level is consulted on every record so it can be
+// changed while running.
+func New(env string, level *slog.LevelVar) (log *slog.Logger) {
 	switch env {
 	case local:
 		log = slog.New(
 			tint.NewHandler(
 				colorable.NewColorable(os.Stdout),
 				&tint.Options{
-					Level: slog.LevelDebug,
+					Level: level,
 				},
 			),
 		)
-	case dev:
+	case dev, prod:
 		log = slog.New(
 			slog.NewJSONHandler(
 				os.Stdout,
 				&slog.HandlerOptions{
-					Level: slog.LevelDebug,
-				},
-			),
-		)
-	case prod:
-		log = slog.New(
-			slog.NewJSONHandler(
-				os.Stdout,
-				&slog.HandlerOptions{
-					Level: slog.LevelInfo,
+					Level: level,
 				},
 			),
 		)
@@ -47,3 +41,21 @@ func New(env string) (log *slog.Logger) {
 
 	return
 }
+
+// ParseLevel parses level name, empty level falls back to default of env: debug
+// everywhere but prod.
+func ParseLevel(env string, level string) (slog.Level, error) {
+	if level == "" {
+		if env == prod {
+			return slog.LevelInfo, nil
+		}
+		return slog.LevelDebug, nil
+	}
+
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", level)
+	}
+
+	return parsed, nil
+}
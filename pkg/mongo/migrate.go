@@ -0,0 +1,293 @@
+package mongo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	migrationsCollectionName = "schema_migrations"
+	lockCollectionName       = "schema_migrations_lock"
+	lockID                   = "lock"
+
+	defaultLockTTL      = 5 * time.Minute
+	defaultLockWait     = time.Minute
+	lockPollInterval    = time.Second
+	namespaceExistsCode = 48
+)
+
+var ErrMigrationLocked = errors.New("migrations are locked by another process")
+
+// Migration changes database schema, Version orders migrations and must never be
+// reused once migration has been released.
+type Migration struct {
+	Version     int64
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+type MigrationStatus struct {
+	Version     int64     `json:"version"`
+	Description string    `json:"description"`
+	Applied     bool      `json:"applied"`
+	AppliedAt   time.Time `json:"applied_at,omitempty"`
+}
+
+type appliedMigration struct {
+	Version     int64     `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Migrator applies migrations missing from schema_migrations. Runs are serialized
+// between processes with a lease in schema_migrations_lock, so replicas starting
+// at once apply every migration only once.
+type Migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+	logger     *slog.Logger
+	owner      string
+	lockTTL    time.Duration
+	lockWait   time.Duration
+}
+
+type migratoropt func(*Migrator)
+
+func WithLockTTL(ttl time.Duration) migratoropt {
+	return func(migrator *Migrator) {
+		migrator.lockTTL = ttl
+	}
+}
+
+func WithLockWait(wait time.Duration) migratoropt {
+	return func(migrator *Migrator) {
+		migrator.lockWait = wait
+	}
+}
+
+func NewMigrator(
+	client *MongoClient,
+	migrations []Migration,
+	logger *slog.Logger,
+	opts ...migratoropt,
+) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	migrator := &Migrator{
+		db:         client.MongoDatabase,
+		migrations: sorted,
+		logger:     logger,
+		owner:      lockOwner(),
+		lockTTL:    defaultLockTTL,
+		lockWait:   defaultLockWait,
+	}
+
+	for _, opt := range opts {
+		opt(migrator)
+	}
+
+	return migrator
+}
+
+// Up applies pending migrations in version order and returns how many were applied.
+func (migrator *Migrator) Up(ctx context.Context) (int, error) {
+	if err := migrator.lock(ctx); err != nil {
+		return 0, fmt.Errorf("Mongo - Migrator - Up: %w", err)
+	}
+	defer migrator.unlock()
+
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("Mongo - Migrator - Up: %w", err)
+	}
+
+	count := 0
+	for _, migration := range migrator.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		migrator.logger.Info(
+			"Mongo: applying migration",
+			slog.Int64("version", migration.Version),
+			slog.String("description", migration.Description),
+		)
+		if err := migration.Up(ctx, migrator.db); err != nil {
+			return count, fmt.Errorf("Mongo - Migrator - Up - migration %d: %w", migration.Version, err)
+		}
+
+		record := appliedMigration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now().UTC(),
+		}
+		if _, err := migrator.db.Collection(migrationsCollectionName).InsertOne(ctx, record); err != nil {
+			return count, fmt.Errorf("Mongo - Migrator - Up - InsertOne: %w", err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Status lists known migrations with their state, versions applied by newer builds
+// are listed as well.
+func (migrator *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Mongo - Migrator - Status: %w", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrator.migrations))
+	for _, migration := range migrator.migrations {
+		status := MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+		}
+		if record, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range applied {
+		statuses = append(statuses, MigrationStatus{
+			Version:     record.Version,
+			Description: record.Description,
+			Applied:     true,
+			AppliedAt:   record.AppliedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+func (migrator *Migrator) applied(ctx context.Context) (map[int64]appliedMigration, error) {
+	cursor, err := migrator.db.Collection(migrationsCollectionName).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("Find: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	applied := make(map[int64]appliedMigration)
+	for cursor.Next(ctx) {
+		record := appliedMigration{}
+		if err := cursor.Decode(&record); err != nil {
+			return nil, fmt.Errorf("Decode: %w", err)
+		}
+		applied[record.Version] = record
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("Cursor: %w", err)
+	}
+
+	return applied, nil
+}
+
+// lock takes the lease, waiting for another process to finish up to lockWait.
+// A lease of crashed process is taken over once it expires.
+func (migrator *Migrator) lock(ctx context.Context) error {
+	deadline := time.Now().Add(migrator.lockWait)
+	collection := migrator.db.Collection(lockCollectionName)
+
+	for {
+		now := time.Now().UTC()
+		filter := bson.M{
+			"_id": lockID,
+			"$or": bson.A{
+				bson.M{"owner": migrator.owner},
+				bson.M{"locked_until": bson.M{"$lte": now}},
+			},
+		}
+		update := bson.M{"$set": bson.M{
+			"owner":        migrator.owner,
+			"locked_until": now.Add(migrator.lockTTL),
+		}}
+
+		_, err := collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		if err == nil {
+			return nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return fmt.Errorf("lock - UpdateOne: %w", err)
+		}
+
+		if time.Now().After(deadline) {
+			return ErrMigrationLocked
+		}
+		migrator.logger.Info("Mongo: waiting for migration lock")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func (migrator *Migrator) unlock() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"_id": lockID, "owner": migrator.owner}
+	if _, err := migrator.db.Collection(lockCollectionName).DeleteOne(ctx, filter); err != nil {
+		migrator.logger.Error("Mongo - Migrator - unlock: " + err.Error())
+	}
+}
+
+// EnsureCollection creates collection with $jsonSchema validator, validator of an
+// existing collection is replaced. Documents stored before are checked only when
+// they are updated.
+func EnsureCollection(ctx context.Context, db *mongo.Database, name string, schema bson.M) error {
+	opts := options.CreateCollection().
+		SetValidator(bson.M{"$jsonSchema": schema}).
+		SetValidationLevel("moderate")
+
+	err := db.CreateCollection(ctx, name, opts)
+	if err == nil {
+		return nil
+	}
+
+	var commandErr mongo.CommandError
+	if !errors.As(err, &commandErr) || commandErr.Code != namespaceExistsCode {
+		return fmt.Errorf("Mongo - EnsureCollection - CreateCollection: %w", err)
+	}
+
+	command := bson.D{
+		{Key: "collMod", Value: name},
+		{Key: "validator", Value: bson.M{"$jsonSchema": schema}},
+		{Key: "validationLevel", Value: "moderate"},
+	}
+	if err := db.RunCommand(ctx, command).Err(); err != nil {
+		return fmt.Errorf("Mongo - EnsureCollection - collMod: %w", err)
+	}
+
+	return nil
+}
+
+func lockOwner() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
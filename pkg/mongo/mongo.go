@@ -2,23 +2,37 @@ package mongo
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 type MongoClient struct {
 	MongoClient   *mongo.Client
 	MongoDatabase *mongo.Database
 	logger        *slog.Logger
+	transactions  bool
 }
 
 type MongoConn struct {
-	host               string
-	port               string
+	uri                string
+	hosts              []string
+	srv                bool
+	replicaSet         string
 	user               string
 	password           string
 	dbName             string
@@ -26,21 +40,45 @@ type MongoConn struct {
 	withCredentials    bool
 	connectTimeout     time.Duration
 	connectionAttempts int
+	tls                *TLS
+	minPoolSize        uint64
+	maxPoolSize        uint64
+	readPreference     string
+	readConcern        string
+	writeConcern       string
+	allowStandalone    bool
 }
 
-type connopt func(*MongoConn)
+// ErrTransactionsUnsupported is returned by New when server is neither a replica
+// set member nor a mongos router and standalone servers are not allowed.
+var ErrTransactionsUnsupported = errors.New("server does not support multi-document transactions")
+
+// TLS locates PEM encoded files, CAFile replaces system roots and CertFile with
+// KeyFile is the client certificate.
+type TLS struct {
+	CAFile   string
+	CertFile string
+	KeyFile  string
+	Insecure bool
+}
+
+// ConnOpt configures MongoConn.
+type ConnOpt func(*MongoConn)
 
 const (
 	defaultConnAttempts   = 10
 	defaultConnectTimeout = 2 * time.Second
+	initialPingBackoff    = 500 * time.Millisecond
+	maxPingBackoff        = 10 * time.Second
 )
 
+// NewMongoConn describes connection to host:port, more hosts are added with
+// WithHosts and WithURI replaces hosts altogether.
 func NewMongoConn(
 	host, port, dbName string,
-	connopts ...connopt) *MongoConn {
+	connopts ...ConnOpt) *MongoConn {
 	mongoConn := &MongoConn{
-		host:               host,
-		port:               port,
+		hosts:              make([]string, 0),
 		user:               "",
 		password:           "",
 		withCredentials:    false,
@@ -49,6 +87,12 @@ func NewMongoConn(
 		authDb:             "",
 		connectionAttempts: defaultConnAttempts,
 	}
+	if host != "" {
+		if port != "" {
+			host = net.JoinHostPort(host, port)
+		}
+		mongoConn.hosts = append(mongoConn.hosts, host)
+	}
 
 	for _, opt := range connopts {
 		opt(mongoConn)
@@ -58,13 +102,13 @@ func NewMongoConn(
 
 }
 
-func WithTimeout(connTimeout time.Duration) connopt {
+func WithTimeout(connTimeout time.Duration) ConnOpt {
 	return func(mongoConn *MongoConn) {
 		mongoConn.connectTimeout = connTimeout
 	}
 }
 
-func WithCredentials(user, password string) connopt {
+func WithCredentials(user, password string) ConnOpt {
 	return func(mongoConn *MongoConn) {
 		mongoConn.user = user
 		mongoConn.password = password
@@ -73,48 +117,108 @@ func WithCredentials(user, password string) connopt {
 	}
 }
 
-func WithAuthDb(authDb string) connopt {
+func WithAuthDb(authDb string) ConnOpt {
 	return func(mongoConn *MongoConn) {
 		mongoConn.authDb = authDb
 	}
 }
 
-func WithConnectionAttempts(connectAttempts int) connopt {
+func WithConnectionAttempts(connectAttempts int) ConnOpt {
 	return func(mongoConn *MongoConn) {
 		mongoConn.connectionAttempts = connectAttempts
 	}
 }
 
-func (mongoConn *MongoConn) parseUrl() string {
-	if mongoConn.withCredentials {
-		return fmt.Sprintf(
-			"mongodb://%s:%s@%s:%s/%s",
-			mongoConn.user,
-			mongoConn.password,
-			mongoConn.host,
-			mongoConn.port,
-			mongoConn.dbName,
-		)
-	} else {
-		return fmt.Sprintf(
-			"mongodb://%s:%s",
-			mongoConn.host,
-			mongoConn.port,
-		)
+// WithURI connects with connection string, options set explicitly take
+// precedence over ones in uri.
+func WithURI(uri string) ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.uri = uri
 	}
 }
 
-func New(ctx context.Context, mongoConn *MongoConn, logger *slog.Logger) (*MongoClient, error) {
-	mongoClient := &MongoClient{
-		logger: logger,
+// WithHosts adds members of a replica set or mongos routers as host:port.
+func WithHosts(hosts ...string) ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.hosts = append(mongoConn.hosts, hosts...)
 	}
+}
 
-	cont, cancel := context.WithTimeout(context.Background(), mongoConn.connectTimeout)
-	defer cancel()
+// WithSRV resolves the only host as mongodb+srv DNS seed list.
+func WithSRV() ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.srv = true
+	}
+}
+
+func WithReplicaSet(replicaSet string) ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.replicaSet = replicaSet
+	}
+}
+
+func WithTLS(tls TLS) ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.tls = &tls
+	}
+}
+
+// WithPoolSize bounds connection pool of every server, zero leaves bound of uri
+// or driver default.
+func WithPoolSize(min, max uint64) ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.minPoolSize = min
+		mongoConn.maxPoolSize = max
+	}
+}
+
+// WithReadPreference sets mode such as primary, secondaryPreferred or nearest.
+func WithReadPreference(mode string) ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.readPreference = mode
+	}
+}
+
+// WithReadConcern sets level such as local, majority or snapshot.
+func WithReadConcern(level string) ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.readConcern = level
+	}
+}
+
+// WithWriteConcern sets w as "majority", number of members or tag set name.
+func WithWriteConcern(w string) ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.writeConcern = w
+	}
+}
+
+// WithStandalone allows servers without multi-document transactions, there
+// WithTransaction runs fn as is. Without it New fails on such servers.
+func WithStandalone() ConnOpt {
+	return func(mongoConn *MongoConn) {
+		mongoConn.allowStandalone = true
+	}
+}
+
+// clientOptions builds driver options, credentials are never put into uri.
+func (mongoConn *MongoConn) clientOptions() (*options.ClientOptions, error) {
+	opts := options.Client()
+
+	switch {
+	case mongoConn.uri != "":
+		opts.ApplyURI(mongoConn.uri)
+	case mongoConn.srv:
+		if len(mongoConn.hosts) != 1 {
+			return nil, fmt.Errorf("srv connection requires exactly one host, got %d", len(mongoConn.hosts))
+		}
+		opts.ApplyURI("mongodb+srv://" + mongoConn.hosts[0])
+	case len(mongoConn.hosts) > 0:
+		opts.SetHosts(mongoConn.hosts)
+	default:
+		return nil, errors.New("neither uri nor hosts are set")
+	}
 
-	url := mongoConn.parseUrl()
-	opts := options.Client().ApplyURI(url)
-	fmt.Println(url)
 	if mongoConn.withCredentials {
 		opts.SetAuth(options.Credential{
 			AuthSource: mongoConn.authDb,
@@ -122,28 +226,219 @@ func New(ctx context.Context, mongoConn *MongoConn, logger *slog.Logger) (*Mongo
 			Password:   mongoConn.password,
 		})
 	}
+	if mongoConn.replicaSet != "" {
+		opts.SetReplicaSet(mongoConn.replicaSet)
+	}
+	if mongoConn.tls != nil {
+		tlsConfig, err := mongoConn.tls.config()
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+	if mongoConn.minPoolSize > 0 {
+		opts.SetMinPoolSize(mongoConn.minPoolSize)
+	}
+	if mongoConn.maxPoolSize > 0 {
+		opts.SetMaxPoolSize(mongoConn.maxPoolSize)
+	}
+	if mongoConn.readPreference != "" {
+		mode, err := readpref.ModeFromString(mongoConn.readPreference)
+		if err != nil {
+			return nil, err
+		}
+		pref, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetReadPreference(pref)
+	}
+	if mongoConn.readConcern != "" {
+		opts.SetReadConcern(readconcern.New(readconcern.Level(mongoConn.readConcern)))
+	}
+	if mongoConn.writeConcern != "" {
+		opts.SetWriteConcern(parseWriteConcern(mongoConn.writeConcern))
+	}
+
+	return opts, opts.Validate()
+}
+
+// String describes connection for logs, credentials of uri are redacted.
+func (mongoConn *MongoConn) String() string {
+	if mongoConn.uri != "" {
+		return redactURI(mongoConn.uri)
+	}
+
+	scheme := "mongodb://"
+	if mongoConn.srv {
+		scheme = "mongodb+srv://"
+	}
+
+	return scheme + strings.Join(mongoConn.hosts, ",") + "/" + mongoConn.dbName
+}
+
+func (tlsOpts TLS) config() (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: tlsOpts.Insecure,
+	}
+
+	if tlsOpts.CAFile != "" {
+		pem, err := os.ReadFile(tlsOpts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls ca: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca: no certificates found in %s", tlsOpts.CAFile)
+		}
+	}
+	if tlsOpts.CertFile != "" || tlsOpts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(tlsOpts.CertFile, tlsOpts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
 
-	client, err := mongo.Connect(cont, opts)
+	return config, nil
+}
+
+func parseWriteConcern(w string) *writeconcern.WriteConcern {
+	if w == "majority" {
+		return writeconcern.Majority()
+	}
+	if n, err := strconv.Atoi(w); err == nil {
+		return &writeconcern.WriteConcern{W: n}
+	}
+
+	return writeconcern.Custom(w)
+}
+
+// redactURI drops user info of connection string.
+func redactURI(uri string) string {
+	scheme, rest, found := strings.Cut(uri, "://")
+	if !found {
+		return "<redacted>"
+	}
+
+	hostsEnd := strings.IndexAny(rest, "/?")
+	if hostsEnd < 0 {
+		hostsEnd = len(rest)
+	}
+	if at := strings.LastIndex(rest[:hostsEnd], "@"); at >= 0 {
+		rest = "<redacted>@" + rest[at+1:]
+	}
+
+	return scheme + "://" + rest
+}
+
+func New(ctx context.Context, mongoConn *MongoConn, logger *slog.Logger) (*MongoClient, error) {
+	mongoClient := &MongoClient{
+		logger: logger,
+	}
+
+	opts, err := mongoConn.clientOptions()
+	if err != nil {
+		return nil, fmt.Errorf("Mongo - New - clientOptions: %w", err)
+	}
+	logger.Info("Mongo: connecting", slog.String("to", mongoConn.String()))
+
+	client, err := mongo.Connect(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("Mongo - New - Connect: %w", err)
 	}
 	mongoClient.MongoClient = client
-	if err := mongoClient.pingWithAttempts(cont, mongoConn.connectionAttempts); err != nil {
+	if err := mongoClient.pingWithAttempts(ctx, mongoConn.connectionAttempts, mongoConn.connectTimeout); err != nil {
 		return nil, err
 	}
 
 	mongoClient.MongoDatabase = client.Database(mongoConn.dbName)
+	helloCtx, cancel := context.WithTimeout(ctx, mongoConn.connectTimeout)
+	defer cancel()
+	mongoClient.transactions = mongoClient.supportsTransactions(helloCtx)
+	if !mongoClient.transactions {
+		if !mongoConn.allowStandalone {
+			if err := client.Disconnect(ctx); err != nil {
+				logger.Warn("Mongo - New - Disconnect: " + err.Error())
+			}
+			return nil, fmt.Errorf("Mongo - New: %w", ErrTransactionsUnsupported)
+		}
+		logger.Warn("Mongo is not a replica set member, multi-document transactions are disabled")
+	}
+
 	return mongoClient, nil
 }
 
-func (mongoClient *MongoClient) pingWithAttempts(ctx context.Context, attempts int) error {
+// WithTransaction runs fn in a multi-document transaction. Standalone servers
+// allowed by WithStandalone do not support transactions, there fn is run as is. Called with ctx of a running
+// transaction fn joins it.
+func (mongoClient *MongoClient) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if !mongoClient.transactions || mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := mongoClient.MongoClient.StartSession()
+	if err != nil {
+		return fmt.Errorf("Mongo - WithTransaction - StartSession: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+
+	return err
+}
+
+// TransactionsSupported reports whether WithTransaction gives atomicity, callers
+// have to compensate failed writes themselves otherwise.
+func (mongoClient *MongoClient) TransactionsSupported() bool {
+	return mongoClient.transactions
+}
+
+func (mongoClient *MongoClient) supportsTransactions(ctx context.Context) bool {
+	hello := struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}{}
+
+	if err := mongoClient.MongoClient.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false
+	}
+
+	return hello.SetName != "" || hello.Msg == "isdbgrid"
+}
+
+// pingWithAttempts gives every ping its own timeout and waits between attempts,
+// doubling the delay up to maxPingBackoff.
+func (mongoClient *MongoClient) pingWithAttempts(ctx context.Context, attempts int, timeout time.Duration) error {
 	var err error
+	delay := initialPingBackoff
 	for attempts > 0 {
 		attempts--
-		if err = mongoClient.MongoClient.Ping(ctx, nil); err == nil {
+
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = mongoClient.MongoClient.Ping(pingCtx, nil)
+		cancel()
+		if err == nil {
 			return nil
 		}
-		mongoClient.logger.Warn("Ping to mongo is failed, ", slog.Int("attempts", attempts))
+		mongoClient.logger.Warn(
+			"Ping to mongo is failed",
+			slog.Int("attempts", attempts),
+			slog.Duration("retry_in", delay),
+			slog.String("error", err.Error()),
+		)
+		if attempts == 0 {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("Mongo - PingWithAttempts: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxPingBackoff)
 	}
 
 	return fmt.Errorf("Mongo - PingWithAttempts: Zero attempts left, failed to ping mongo: %w", err)
@@ -0,0 +1,155 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/elusiv0/medods_test/pkg/ratelimit"
+)
+
+const (
+	OperatorEquals      = "equals"
+	OperatorNotEquals   = "not_equals"
+	OperatorIn          = "in"
+	OperatorNotIn       = "not_in"
+	OperatorCIDR        = "cidr"
+	OperatorNotCIDR     = "not_cidr"
+	OperatorTimeBetween = "time_between"
+)
+
+const clockLayout = "15:04"
+
+// Condition compares attribute with Values or, when Ref is set, with values of
+// another attribute such as "subject.uuid". Condition on absent attribute does
+// not hold, except for not_equals, not_in and not_cidr of deny policy: deny
+// "ip is not in office network" has to deny request whose ip is unknown too.
+//
+// cidr and not_cidr take Values of CIDR blocks or plain addresses.
+//
+// time_between takes Values ["09:00", "18:00"] and expects RFC 3339 attribute
+// value, Location names time zone the clock is read in, UTC by default. Range
+// whose end is before its start spans midnight.
+type Condition struct {
+	Attribute string   `json:"attribute" yaml:"attribute"`
+	Operator  string   `json:"operator" yaml:"operator"`
+	Values    []string `json:"values,omitempty" yaml:"values"`
+	Ref       string   `json:"ref,omitempty" yaml:"ref"`
+	Location  string   `json:"location,omitempty" yaml:"location"`
+}
+
+type compiledCondition struct {
+	condition Condition
+	networks  ratelimit.Allowlist
+	location  *time.Location
+	from      int
+	to        int
+	// absentHolds is what condition on absent attribute evaluates to
+	absentHolds bool
+}
+
+func compileCondition(condition Condition, effect string) (compiledCondition, error) {
+	compiled := compiledCondition{condition: condition, location: time.UTC}
+	if effect == EffectDeny {
+		switch condition.Operator {
+		case OperatorNotEquals, OperatorNotIn, OperatorNotCIDR:
+			compiled.absentHolds = true
+		}
+	}
+
+	if condition.Attribute == "" {
+		return compiled, fmt.Errorf("condition has no attribute")
+	}
+	if condition.Ref == "" && len(condition.Values) == 0 {
+		return compiled, fmt.Errorf("condition on %s has neither values nor ref", condition.Attribute)
+	}
+	if condition.Ref != "" && len(condition.Values) != 0 {
+		return compiled, fmt.Errorf("condition on %s has both values and ref", condition.Attribute)
+	}
+
+	switch condition.Operator {
+	case OperatorEquals, OperatorNotEquals, OperatorIn, OperatorNotIn:
+	case OperatorCIDR, OperatorNotCIDR:
+		if condition.Ref != "" {
+			return compiled, fmt.Errorf("%s condition on %s does not accept ref", condition.Operator, condition.Attribute)
+		}
+		networks, err := ratelimit.ParseAllowlist(condition.Values)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.networks = networks
+	case OperatorTimeBetween:
+		if len(condition.Values) != 2 {
+			return compiled, fmt.Errorf("time_between condition on %s needs start and end", condition.Attribute)
+		}
+		from, err := time.Parse(clockLayout, condition.Values[0])
+		if err != nil {
+			return compiled, fmt.Errorf("time_between condition on %s: %w", condition.Attribute, err)
+		}
+		to, err := time.Parse(clockLayout, condition.Values[1])
+		if err != nil {
+			return compiled, fmt.Errorf("time_between condition on %s: %w", condition.Attribute, err)
+		}
+		compiled.from = from.Hour()*60 + from.Minute()
+		compiled.to = to.Hour()*60 + to.Minute()
+		if condition.Location != "" {
+			if compiled.location, err = time.LoadLocation(condition.Location); err != nil {
+				return compiled, fmt.Errorf("time_between condition on %s: %w", condition.Attribute, err)
+			}
+		}
+	default:
+		return compiled, fmt.Errorf("condition on %s has unknown operator %q", condition.Attribute, condition.Operator)
+	}
+
+	return compiled, nil
+}
+
+func (compiled compiledCondition) holds(attributes Attributes) bool {
+	values := attributes[compiled.condition.Attribute]
+	if len(values) == 0 {
+		return compiled.absentHolds
+	}
+
+	expected := compiled.condition.Values
+	if compiled.condition.Ref != "" {
+		if expected = attributes[compiled.condition.Ref]; len(expected) == 0 {
+			return compiled.absentHolds
+		}
+	}
+
+	switch compiled.condition.Operator {
+	case OperatorEquals, OperatorIn:
+		return intersects(values, expected)
+	case OperatorNotEquals, OperatorNotIn:
+		return !intersects(values, expected)
+	case OperatorCIDR:
+		return slices.ContainsFunc(values, compiled.networks.Contains)
+	case OperatorNotCIDR:
+		return !slices.ContainsFunc(values, compiled.networks.Contains)
+	case OperatorTimeBetween:
+		return slices.ContainsFunc(values, compiled.between)
+	}
+
+	return false
+}
+
+func (compiled compiledCondition) between(value string) bool {
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false
+	}
+	at = at.In(compiled.location)
+	minute := at.Hour()*60 + at.Minute()
+
+	if compiled.from <= compiled.to {
+		return minute >= compiled.from && minute < compiled.to
+	}
+
+	return minute >= compiled.from || minute < compiled.to
+}
+
+func intersects(values []string, expected []string) bool {
+	return slices.ContainsFunc(values, func(value string) bool {
+		return slices.Contains(expected, value)
+	})
+}
@@ -0,0 +1,86 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type document struct {
+	Policies []Policy `json:"policies" yaml:"policies"`
+}
+
+// Load reads policies from .json, .yaml or .yml file, or from every such file
+// of directory in name order, and compiles them.
+func Load(path string) (*Engine, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("Policy - Load: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("Policy - Load: %w", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() && isPolicyFile(entry.Name()) {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	policies := make([]Policy, 0)
+	for _, file := range files {
+		loaded, err := readFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Policy - Load: %w", err)
+		}
+		policies = append(policies, loaded...)
+	}
+
+	return New(policies)
+}
+
+func readFile(file string) ([]Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	document := document{}
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&document)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&document)
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w: %s", file, ErrInvalidPolicy, err.Error())
+	}
+
+	return document.Policies, nil
+}
+
+func isPolicyFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+
+	return false
+}
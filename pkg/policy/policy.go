@@ -0,0 +1,251 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+
+	// Any action or resource pattern matches every request.
+	Any = "*"
+
+	ReasonDenied        = "denied_by_policy"
+	ReasonAllowed       = "allowed_by_policy"
+	ReasonNoAllow       = "no_allow_policy_matched"
+	ReasonNotApplicable = "not_applicable"
+)
+
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// Policy applies to requests whose action is one of Actions and whose resource
+// matches one of Resources, it takes effect when every condition holds.
+type Policy struct {
+	ID          string      `json:"id" yaml:"id"`
+	Description string      `json:"description,omitempty" yaml:"description"`
+	Effect      string      `json:"effect" yaml:"effect"`
+	Actions     []string    `json:"actions" yaml:"actions"`
+	Resources   []string    `json:"resources" yaml:"resources"`
+	Conditions  []Condition `json:"conditions,omitempty" yaml:"conditions"`
+}
+
+// Attributes maps attribute name such as "subject.roles" to its values, single
+// valued attributes hold one value.
+type Attributes map[string][]string
+
+func (attributes Attributes) Set(name string, values ...string) {
+	if len(values) == 0 {
+		return
+	}
+	attributes[name] = values
+}
+
+type Decision struct {
+	Allowed bool
+	Policy  string
+	Reason  string
+}
+
+type compiled struct {
+	policy     Policy
+	conditions []compiledCondition
+}
+
+// Engine evaluates policies, deny policies override allow ones. Request no
+// policy applies to is allowed, request some allow policy applies to is denied
+// unless one of them holds.
+type Engine struct {
+	policies []compiled
+}
+
+func New(policies []Policy) (*Engine, error) {
+	engine := &Engine{policies: make([]compiled, 0, len(policies))}
+	ids := make(map[string]struct{}, len(policies))
+
+	for i, policy := range policies {
+		if policy.ID == "" {
+			return nil, fmt.Errorf("Policy - New: %w: policy %d has no id", ErrInvalidPolicy, i)
+		}
+		if _, ok := ids[policy.ID]; ok {
+			return nil, fmt.Errorf("Policy - New: %w: policy %s is listed twice", ErrInvalidPolicy, policy.ID)
+		}
+		ids[policy.ID] = struct{}{}
+
+		if policy.Effect != EffectAllow && policy.Effect != EffectDeny {
+			return nil, fmt.Errorf("Policy - New: %w: policy %s has unknown effect %q", ErrInvalidPolicy, policy.ID, policy.Effect)
+		}
+		if len(policy.Actions) == 0 || len(policy.Resources) == 0 {
+			return nil, fmt.Errorf("Policy - New: %w: policy %s has no actions or resources", ErrInvalidPolicy, policy.ID)
+		}
+		policy.Actions = slices.Clone(policy.Actions)
+		for i, action := range policy.Actions {
+			policy.Actions[i] = strings.ToUpper(action)
+		}
+
+		conditions := make([]compiledCondition, 0, len(policy.Conditions))
+		for _, condition := range policy.Conditions {
+			compiledCondition, err := compileCondition(condition, policy.Effect)
+			if err != nil {
+				return nil, fmt.Errorf("Policy - New: %w: policy %s: %s", ErrInvalidPolicy, policy.ID, err.Error())
+			}
+			conditions = append(conditions, compiledCondition)
+		}
+
+		engine.policies = append(engine.policies, compiled{policy: policy, conditions: conditions})
+	}
+
+	return engine, nil
+}
+
+// Policies returns loaded policies in evaluation order.
+func (engine *Engine) Policies() []Policy {
+	policies := make([]Policy, 0, len(engine.policies))
+	for _, compiled := range engine.policies {
+		policies = append(policies, compiled.policy)
+	}
+
+	return policies
+}
+
+// Evaluate decides whether action on resource is allowed for attributes.
+func (engine *Engine) Evaluate(action string, resource string, attributes Attributes) Decision {
+	applicable := false
+	allowed := ""
+
+	for _, compiled := range engine.policies {
+		if !compiled.applies(action, resource) {
+			continue
+		}
+		if compiled.policy.Effect == EffectAllow {
+			applicable = true
+		}
+		if !compiled.holds(attributes) {
+			continue
+		}
+		if compiled.policy.Effect == EffectDeny {
+			return Decision{Allowed: false, Policy: compiled.policy.ID, Reason: ReasonDenied}
+		}
+		if allowed == "" {
+			allowed = compiled.policy.ID
+		}
+	}
+
+	switch {
+	case allowed != "":
+		return Decision{Allowed: true, Policy: allowed, Reason: ReasonAllowed}
+	case applicable:
+		return Decision{Allowed: false, Reason: ReasonNoAllow}
+	default:
+		return Decision{Allowed: true, Reason: ReasonNotApplicable}
+	}
+}
+
+func (compiled compiled) applies(action string, resource string) bool {
+	action = strings.ToUpper(action)
+	if !slices.ContainsFunc(compiled.policy.Actions, func(pattern string) bool {
+		return pattern == Any || pattern == action
+	}) {
+		return false
+	}
+
+	return slices.ContainsFunc(compiled.policy.Resources, func(pattern string) bool {
+		return MatchResource(pattern, resource)
+	})
+}
+
+func (compiled compiled) holds(attributes Attributes) bool {
+	for _, condition := range compiled.conditions {
+		if !condition.holds(attributes) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Route finds route template of policies path matches, for requests routed
+// outside of this service such as forwarded ones. When several templates match
+// the one with most static segments wins, as it does in gin.
+func (engine *Engine) Route(path string) (string, map[string]string, bool) {
+	route, params, static := "", map[string]string(nil), -1
+	for _, compiled := range engine.policies {
+		for _, pattern := range compiled.policy.Resources {
+			if strings.HasSuffix(pattern, Any) {
+				continue
+			}
+			matched, ok := MatchRoute(pattern, path)
+			if !ok {
+				continue
+			}
+			if count := staticSegments(pattern); count > static {
+				route, params, static = pattern, matched, count
+			}
+		}
+	}
+
+	return route, params, static >= 0
+}
+
+// MatchRoute reports whether path matches gin route template such as
+// "/api/v1/users/:uuid" and returns values of its parameters, "*name" parameter
+// takes the rest of path.
+func MatchRoute(route string, path string) (map[string]string, bool) {
+	params := make(map[string]string)
+	routeSegments := strings.Split(route, "/")
+	pathSegments := strings.Split(path, "/")
+
+	for i, segment := range routeSegments {
+		if name, ok := strings.CutPrefix(segment, "*"); ok {
+			if i > len(pathSegments) {
+				return nil, false
+			}
+			params[name] = "/" + strings.Join(pathSegments[i:], "/")
+			return params, true
+		}
+		if i >= len(pathSegments) {
+			return nil, false
+		}
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+			params[name] = pathSegments[i]
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	if len(routeSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+func staticSegments(route string) int {
+	count := 0
+	for _, segment := range strings.Split(route, "/") {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			count++
+		}
+	}
+
+	return count
+}
+
+// MatchResource reports whether pattern covers resource, "*" covers every
+// resource and "/api/v1/users/*" covers every resource under "/api/v1/users/".
+func MatchResource(pattern string, resource string) bool {
+	if pattern == Any || pattern == resource {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(pattern, Any)
+
+	return ok && strings.HasPrefix(resource, prefix)
+}
@@ -0,0 +1,259 @@
+package policy
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestEngine(t *testing.T, policies ...Policy) *Engine {
+	t.Helper()
+
+	engine, err := New(policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return engine
+}
+
+func TestEvaluateDenyOverrides(t *testing.T) {
+	engine := newTestEngine(t,
+		Policy{
+			ID:         "admins",
+			Effect:     EffectAllow,
+			Actions:    []string{Any},
+			Resources:  []string{"/api/v1/admin/*"},
+			Conditions: []Condition{{Attribute: "subject.roles", Operator: OperatorIn, Values: []string{"admin"}}},
+		},
+		Policy{
+			ID:         "self",
+			Effect:     EffectAllow,
+			Actions:    []string{"get"},
+			Resources:  []string{"/api/v1/admin/users/:uuid"},
+			Conditions: []Condition{{Attribute: "path.uuid", Operator: OperatorEquals, Ref: "subject.uuid"}},
+		},
+		Policy{
+			ID:         "office",
+			Effect:     EffectDeny,
+			Actions:    []string{"POST", "DELETE"},
+			Resources:  []string{"/api/v1/admin/*"},
+			Conditions: []Condition{{Attribute: "request.ip", Operator: OperatorNotCIDR, Values: []string{"10.0.0.0/8"}}},
+		},
+	)
+
+	for name, tc := range map[string]struct {
+		action     string
+		resource   string
+		attributes Attributes
+		want       Decision
+	}{
+		"allowed by first holding policy": {
+			action:     "GET",
+			resource:   "/api/v1/admin/users/:uuid",
+			attributes: Attributes{"subject.roles": {"admin"}, "subject.uuid": {"u-1"}, "path.uuid": {"u-1"}},
+			want:       Decision{Allowed: true, Policy: "admins", Reason: ReasonAllowed},
+		},
+		"allowed by ref": {
+			action:     "get",
+			resource:   "/api/v1/admin/users/:uuid",
+			attributes: Attributes{"subject.uuid": {"u-1"}, "path.uuid": {"u-1"}},
+			want:       Decision{Allowed: true, Policy: "self", Reason: ReasonAllowed},
+		},
+		"no allow policy holds": {
+			action:     "GET",
+			resource:   "/api/v1/admin/users/:uuid",
+			attributes: Attributes{"subject.uuid": {"u-1"}, "path.uuid": {"u-2"}},
+			want:       Decision{Allowed: false, Reason: ReasonNoAllow},
+		},
+		"deny overrides allow": {
+			action:     "DELETE",
+			resource:   "/api/v1/admin/users/:uuid",
+			attributes: Attributes{"subject.roles": {"admin"}, "request.ip": {"203.0.113.1"}},
+			want:       Decision{Allowed: false, Policy: "office", Reason: ReasonDenied},
+		},
+		"deny does not hold": {
+			action:     "DELETE",
+			resource:   "/api/v1/admin/users/:uuid",
+			attributes: Attributes{"subject.roles": {"admin"}, "request.ip": {"10.1.2.3"}},
+			want:       Decision{Allowed: true, Policy: "admins", Reason: ReasonAllowed},
+		},
+		"deny holds on absent attribute": {
+			action:     "POST",
+			resource:   "/api/v1/admin/roles",
+			attributes: Attributes{"subject.roles": {"admin"}},
+			want:       Decision{Allowed: false, Policy: "office", Reason: ReasonDenied},
+		},
+		"allow does not hold on absent attribute": {
+			action:     "GET",
+			resource:   "/api/v1/admin/users/:uuid",
+			attributes: Attributes{"path.uuid": {"u-1"}},
+			want:       Decision{Allowed: false, Reason: ReasonNoAllow},
+		},
+		"not applicable": {
+			action:     "GET",
+			resource:   "/api/v1/auth/sign-in",
+			attributes: Attributes{},
+			want:       Decision{Allowed: true, Reason: ReasonNotApplicable},
+		},
+	} {
+		if got := engine.Evaluate(tc.action, tc.resource, tc.attributes); got != tc.want {
+			t.Errorf("%s: Evaluate = %+v, want %+v", name, got, tc.want)
+		}
+	}
+}
+
+func TestConditionHolds(t *testing.T) {
+	for name, tc := range map[string]struct {
+		condition  Condition
+		effect     string
+		attributes Attributes
+		want       bool
+	}{
+		"equals":                 {Condition{Attribute: "a", Operator: OperatorEquals, Values: []string{"x"}}, EffectAllow, Attributes{"a": {"x"}}, true},
+		"in multi valued":        {Condition{Attribute: "a", Operator: OperatorIn, Values: []string{"x", "y"}}, EffectAllow, Attributes{"a": {"z", "y"}}, true},
+		"not in":                 {Condition{Attribute: "a", Operator: OperatorNotIn, Values: []string{"x", "y"}}, EffectAllow, Attributes{"a": {"z"}}, true},
+		"not equals absent":      {Condition{Attribute: "a", Operator: OperatorNotEquals, Values: []string{"x"}}, EffectAllow, Attributes{}, false},
+		"deny not equals absent": {Condition{Attribute: "a", Operator: OperatorNotEquals, Values: []string{"x"}}, EffectDeny, Attributes{}, true},
+		"deny not in absent ref": {Condition{Attribute: "a", Operator: OperatorNotIn, Ref: "b"}, EffectDeny, Attributes{"a": {"x"}}, true},
+		"deny equals absent":     {Condition{Attribute: "a", Operator: OperatorEquals, Values: []string{"x"}}, EffectDeny, Attributes{}, false},
+		"ref":                    {Condition{Attribute: "a", Operator: OperatorEquals, Ref: "b"}, EffectAllow, Attributes{"a": {"x"}, "b": {"x"}}, true},
+		"absent ref":             {Condition{Attribute: "a", Operator: OperatorEquals, Ref: "b"}, EffectAllow, Attributes{"a": {"x"}}, false},
+		"cidr":                   {Condition{Attribute: "ip", Operator: OperatorCIDR, Values: []string{"10.0.0.0/8", "192.168.1.1"}}, EffectAllow, Attributes{"ip": {"192.168.1.1"}}, true},
+		"not cidr":               {Condition{Attribute: "ip", Operator: OperatorNotCIDR, Values: []string{"10.0.0.0/8"}}, EffectAllow, Attributes{"ip": {"10.0.0.1"}}, false},
+		"time between":           {Condition{Attribute: "t", Operator: OperatorTimeBetween, Values: []string{"09:00", "18:00"}}, EffectAllow, Attributes{"t": {"2024-05-01T09:00:00Z"}}, true},
+		"time between end":       {Condition{Attribute: "t", Operator: OperatorTimeBetween, Values: []string{"09:00", "18:00"}}, EffectAllow, Attributes{"t": {"2024-05-01T18:00:00Z"}}, false},
+		"time between location":  {Condition{Attribute: "t", Operator: OperatorTimeBetween, Values: []string{"09:00", "18:00"}, Location: "Europe/Moscow"}, EffectAllow, Attributes{"t": {"2024-05-01T07:00:00Z"}}, true},
+		"time past midnight":     {Condition{Attribute: "t", Operator: OperatorTimeBetween, Values: []string{"22:00", "06:00"}}, EffectAllow, Attributes{"t": {"2024-05-01T01:30:00Z"}}, true},
+		"time out of range":      {Condition{Attribute: "t", Operator: OperatorTimeBetween, Values: []string{"22:00", "06:00"}}, EffectAllow, Attributes{"t": {"2024-05-01T12:00:00Z"}}, false},
+		"malformed time":         {Condition{Attribute: "t", Operator: OperatorTimeBetween, Values: []string{"00:00", "23:59"}}, EffectAllow, Attributes{"t": {"noon"}}, false},
+	} {
+		compiled, err := compileCondition(tc.condition, tc.effect)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if got := compiled.holds(tc.attributes); got != tc.want {
+			t.Errorf("%s: holds = %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+func TestNewRejectsInvalidPolicies(t *testing.T) {
+	valid := Policy{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}}
+
+	for name, policies := range map[string][]Policy{
+		"no id":          {{Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}}},
+		"listed twice":   {valid, valid},
+		"unknown effect": {{ID: "p", Effect: "permit", Actions: []string{"GET"}, Resources: []string{"/"}}},
+		"no actions":     {{ID: "p", Effect: EffectAllow, Resources: []string{"/"}}},
+		"no resources":   {{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}}},
+		"no attribute":   {{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}, Conditions: []Condition{{Operator: OperatorEquals, Values: []string{"x"}}}}},
+		"no values":      {{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}, Conditions: []Condition{{Attribute: "a", Operator: OperatorEquals}}}},
+		"values and ref": {{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}, Conditions: []Condition{{Attribute: "a", Operator: OperatorEquals, Values: []string{"x"}, Ref: "b"}}}},
+		"cidr ref":       {{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}, Conditions: []Condition{{Attribute: "a", Operator: OperatorCIDR, Ref: "b"}}}},
+		"bad cidr":       {{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}, Conditions: []Condition{{Attribute: "a", Operator: OperatorCIDR, Values: []string{"10.0.0.0/40"}}}}},
+		"bad clock":      {{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}, Conditions: []Condition{{Attribute: "a", Operator: OperatorTimeBetween, Values: []string{"9am", "18:00"}}}}},
+		"bad location":   {{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}, Conditions: []Condition{{Attribute: "a", Operator: OperatorTimeBetween, Values: []string{"09:00", "18:00"}, Location: "Mars/Olympus"}}}},
+		"unknown op":     {{ID: "p", Effect: EffectAllow, Actions: []string{"GET"}, Resources: []string{"/"}, Conditions: []Condition{{Attribute: "a", Operator: "like", Values: []string{"x"}}}}},
+	} {
+		if _, err := New(policies); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("%s: err = %v, want ErrInvalidPolicy", name, err)
+		}
+	}
+}
+
+func TestMatchRoute(t *testing.T) {
+	for _, tc := range []struct {
+		route  string
+		path   string
+		params map[string]string
+		ok     bool
+	}{
+		{"/api/v1/users", "/api/v1/users", map[string]string{}, true},
+		{"/api/v1/users/:uuid", "/api/v1/users/u-1", map[string]string{"uuid": "u-1"}, true},
+		{"/api/v1/users/:uuid/roles/:role", "/api/v1/users/u-1/roles/admin", map[string]string{"uuid": "u-1", "role": "admin"}, true},
+		{"/api/v1/users/:uuid", "/api/v1/users/", nil, false},
+		{"/api/v1/users/:uuid", "/api/v1/users", nil, false},
+		{"/api/v1/users/:uuid", "/api/v1/users/u-1/roles", nil, false},
+		{"/api/v1/users", "/api/v1/groups", nil, false},
+		{"/files/*path", "/files/a/b.txt", map[string]string{"path": "/a/b.txt"}, true},
+		{"/files/*path", "/files", map[string]string{"path": "/"}, true},
+		{"/files/*path", "/", nil, false},
+	} {
+		params, ok := MatchRoute(tc.route, tc.path)
+		if ok != tc.ok || !maps.Equal(params, tc.params) {
+			t.Errorf("MatchRoute(%s, %s) = %v, %v, want %v, %v", tc.route, tc.path, params, ok, tc.params, tc.ok)
+		}
+	}
+}
+
+func TestRoutePrefersStaticSegments(t *testing.T) {
+	engine := newTestEngine(t,
+		Policy{ID: "any", Effect: EffectAllow, Actions: []string{Any}, Resources: []string{"/api/v1/*"}},
+		Policy{ID: "user", Effect: EffectAllow, Actions: []string{Any}, Resources: []string{"/api/v1/users/:uuid", "/api/v1/:kind/:id"}},
+		Policy{ID: "me", Effect: EffectAllow, Actions: []string{Any}, Resources: []string{"/api/v1/users/me"}},
+	)
+
+	for path, want := range map[string]string{
+		"/api/v1/users/me":  "/api/v1/users/me",
+		"/api/v1/users/u-1": "/api/v1/users/:uuid",
+		"/api/v1/groups/g":  "/api/v1/:kind/:id",
+	} {
+		route, _, ok := engine.Route(path)
+		if !ok || route != want {
+			t.Errorf("Route(%s) = %s, %v, want %s", path, route, ok, want)
+		}
+	}
+
+	route, params, ok := engine.Route("/api/v1/users/u-1")
+	if !ok || route != "/api/v1/users/:uuid" || params["uuid"] != "u-1" {
+		t.Errorf("Route = %s, %v, %v", route, params, ok)
+	}
+	if route, _, ok := engine.Route("/api/v1/users"); ok {
+		t.Errorf("path matched only by wildcard resource is routed to %s", route)
+	}
+}
+
+func TestMatchResource(t *testing.T) {
+	for _, tc := range []struct {
+		pattern  string
+		resource string
+		want     bool
+	}{
+		{"*", "/api/v1/users", true},
+		{"/api/v1/users", "/api/v1/users", true},
+		{"/api/v1/users/*", "/api/v1/users/:uuid", true},
+		{"/api/v1/users/*", "/api/v1/users", false},
+		{"/api/v1/users", "/api/v1/users/:uuid", false},
+	} {
+		if got := MatchResource(tc.pattern, tc.resource); got != tc.want {
+			t.Errorf("MatchResource(%s, %s) = %v, want %v", tc.pattern, tc.resource, got, tc.want)
+		}
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"10-allow.yaml": "policies:\n  - id: allow\n    effect: allow\n    actions: [get]\n    resources: [\"*\"]\n",
+		"20-deny.json":  `{"policies":[{"id":"deny","effect":"deny","actions":["*"],"resources":["/admin/*"]}]}`,
+		"notes.txt":     "not a policy",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies := engine.Policies()
+	if len(policies) != 2 || policies[0].ID != "allow" || policies[1].ID != "deny" || policies[0].Actions[0] != "GET" {
+		t.Errorf("Policies = %+v", policies)
+	}
+}
@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaPublisher writes message to the topic of the same name, waiting for
+// acknowledgement of all in-sync replicas. Messages with equal keys land in
+// the same partition and keep their order.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+var _ Publisher = (*KafkaPublisher)(nil)
+
+func NewKafkaPublisher(brokers []string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+func (publisher *KafkaPublisher) Publish(ctx context.Context, message Message) error {
+	headers := make([]kafka.Header, 0, len(message.Headers)+1)
+	headers = append(headers, kafka.Header{Key: HeaderMessageID, Value: []byte(message.ID)})
+	for key, value := range message.Headers {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+
+	err := publisher.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   message.Topic,
+		Key:     []byte(message.Key),
+		Value:   message.Payload,
+		Headers: headers,
+	})
+	if err != nil {
+		return fmt.Errorf("KafkaPublisher - Publish: %w", err)
+	}
+
+	return nil
+}
+
+func (publisher *KafkaPublisher) Close() error {
+	return publisher.writer.Close()
+}
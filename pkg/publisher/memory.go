@@ -0,0 +1,55 @@
+package publisher
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryPublisher keeps published messages in process, it stands in for a broker
+// in local environment and tests.
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+var _ Publisher = (*MemoryPublisher)(nil)
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (publisher *MemoryPublisher) Publish(ctx context.Context, message Message) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if publisher.err != nil {
+		return publisher.err
+	}
+	publisher.messages = append(publisher.messages, message)
+
+	return nil
+}
+
+// Messages returns copy of messages published so far.
+func (publisher *MemoryPublisher) Messages() []Message {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	messages := make([]Message, len(publisher.messages))
+	copy(messages, publisher.messages)
+
+	return messages
+}
+
+// FailWith makes following publishes return err, nil restores normal behaviour.
+func (publisher *MemoryPublisher) FailWith(err error) {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.err = err
+}
+
+func (publisher *MemoryPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSPublisher publishes message to the subject named after its topic. Core NATS
+// does not acknowledge messages, so with JetStream disabled delivery is considered
+// done once the server has processed the flush.
+type NATSPublisher struct {
+	conn      *nats.Conn
+	jetStream jetstream.JetStream
+}
+
+var _ Publisher = (*NATSPublisher)(nil)
+
+func NewNATSPublisher(url string, useJetStream bool) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("NATSPublisher - Connect: %w", err)
+	}
+
+	publisher := &NATSPublisher{
+		conn: conn,
+	}
+	if useJetStream {
+		publisher.jetStream, err = jetstream.New(conn)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("NATSPublisher - JetStream: %w", err)
+		}
+	}
+
+	return publisher, nil
+}
+
+func (publisher *NATSPublisher) Publish(ctx context.Context, message Message) error {
+	msg := nats.NewMsg(message.Topic)
+	msg.Data = message.Payload
+	for key, value := range message.Headers {
+		msg.Header.Set(key, value)
+	}
+	msg.Header.Set(HeaderMessageID, message.ID)
+
+	if publisher.jetStream != nil {
+		if _, err := publisher.jetStream.PublishMsg(ctx, msg, jetstream.WithMsgID(message.ID)); err != nil {
+			return fmt.Errorf("NATSPublisher - Publish: %w", err)
+		}
+		return nil
+	}
+
+	if err := publisher.conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("NATSPublisher - Publish: %w", err)
+	}
+	if err := publisher.conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("NATSPublisher - Publish - Flush: %w", err)
+	}
+
+	return nil
+}
+
+func (publisher *NATSPublisher) Close() error {
+	return publisher.conn.Drain()
+}
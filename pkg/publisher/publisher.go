@@ -0,0 +1,27 @@
+package publisher
+
+import (
+	"context"
+)
+
+const (
+	// HeaderMessageID carries id of the message, consumers use it to drop
+	// duplicates produced by at-least-once delivery.
+	HeaderMessageID = "Message-Id"
+	HeaderEventType = "Event-Type"
+	HeaderTenant    = "Tenant"
+)
+
+type Message struct {
+	ID      string
+	Topic   string
+	Key     string
+	Payload []byte
+	Headers map[string]string
+}
+
+// Publisher sends message to the broker, nil error means broker has accepted it.
+type Publisher interface {
+	Publish(ctx context.Context, message Message) error
+	Close() error
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+type Allowlist []*net.IPNet
+
+// ParseAllowlist accepts CIDR blocks and plain IP addresses.
+func ParseAllowlist(entries []string) (Allowlist, error) {
+	allowlist := make(Allowlist, 0, len(entries))
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("RateLimit - ParseAllowlist: invalid ip %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			allowlist = append(allowlist, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("RateLimit - ParseAllowlist: %w", err)
+		}
+		allowlist = append(allowlist, network)
+	}
+
+	return allowlist, nil
+}
+
+func (allowlist Allowlist) Contains(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range allowlist {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoStore struct {
+	collection *mongo.Collection
+	now        func() time.Time
+}
+
+type mongoBucket struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+var _ Store = (*MongoStore)(nil)
+
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{
+		collection: collection,
+		now:        time.Now,
+	}
+}
+
+// Take refills and consumes the bucket in a single pipeline update, so
+// concurrent replicas sharing the collection never race on the same key.
+func (store *MongoStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	now := store.now().UTC()
+	burst := float64(limit.Requests)
+
+	elapsed := bson.M{"$divide": bson.A{
+		bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, bson.M{"$ifNull": bson.A{"$updated_at", now}}}}}},
+		1000,
+	}}
+	refilled := bson.M{"$min": bson.A{
+		burst,
+		bson.M{"$add": bson.A{
+			bson.M{"$ifNull": bson.A{"$tokens", burst}},
+			bson.M{"$multiply": bson.A{elapsed, limit.rate()}},
+		}},
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens":     refilled,
+			"updated_at": now,
+			"expires_at": now.Add(limit.Period),
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed": bson.M{"$gte": bson.A{"$tokens", 1}},
+			"tokens": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$tokens", 1}},
+				bson.M{"$subtract": bson.A{"$tokens", 1}},
+				"$tokens",
+			}},
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	b := mongoBucket{}
+	if err := store.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline, opts).Decode(&b); err != nil {
+		return Result{}, fmt.Errorf("RateLimit - MongoStore - Take: %w", err)
+	}
+
+	return result(b.Tokens, b.Allowed, limit), nil
+}
+
+func (store *MongoStore) EnsureIndexes(ctx context.Context) error {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	if _, err := store.collection.Indexes().CreateOne(ctx, index); err != nil {
+		return fmt.Errorf("RateLimit - MongoStore - EnsureIndexes: %w", err)
+	}
+
+	return nil
+}
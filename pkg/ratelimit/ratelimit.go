@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit describes a token bucket that holds Requests tokens and refills
+// completely once per Period. A zero Limit disables limiting.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+func (limit Limit) Enabled() bool {
+	return limit.Requests > 0 && limit.Period > 0
+}
+
+// rate returns bucket refill speed in tokens per second.
+func (limit Limit) rate() float64 {
+	return float64(limit.Requests) / limit.Period.Seconds()
+}
+
+func (limit Limit) String() string {
+	if !limit.Enabled() {
+		return ""
+	}
+
+	return fmt.Sprintf("%d/%s", limit.Requests, limit.Period)
+}
+
+// UnmarshalText parses limits written as "<requests>/<period>", where period is
+// either a unit (s, m, h) or a time.Duration such as "30s".
+func (limit *Limit) UnmarshalText(text []byte) error {
+	value := strings.TrimSpace(string(text))
+	if value == "" || value == "0" {
+		*limit = Limit{}
+		return nil
+	}
+
+	requestsPart, periodPart, found := strings.Cut(value, "/")
+	if !found {
+		return fmt.Errorf("RateLimit - Limit: invalid limit %q, expected <requests>/<period>", value)
+	}
+
+	requests, err := strconv.Atoi(requestsPart)
+	if err != nil || requests < 0 {
+		return fmt.Errorf("RateLimit - Limit: invalid requests count %q", requestsPart)
+	}
+
+	var period time.Duration
+	switch periodPart {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		period, err = time.ParseDuration(periodPart)
+		if err != nil || period <= 0 {
+			return fmt.Errorf("RateLimit - Limit: invalid period %q", periodPart)
+		}
+	}
+
+	*limit = Limit{
+		Requests: requests,
+		Period:   period,
+	}
+
+	return nil
+}
+
+func (limit Limit) MarshalText() ([]byte, error) {
+	return []byte(limit.String()), nil
+}
+
+// result builds the outcome of a take from the amount of tokens left after it.
+func result(tokens float64, allowed bool, limit Limit) Result {
+	rate := limit.rate()
+
+	res := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Requests) - tokens) / rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+
+	return res
+}
+
+// refill returns amount of tokens in the bucket at now.
+func refill(tokens float64, updatedAt, now time.Time, limit Limit) float64 {
+	elapsed := now.Sub(updatedAt).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	return math.Min(float64(limit.Requests), tokens+elapsed*limit.rate())
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+
+	return time.Duration(math.Ceil(s)) * time.Second
+}
@@ -0,0 +1,131 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestStore(now *time.Time) *MemoryStore {
+	store := NewMemoryStore()
+	store.now = func() time.Time { return *now }
+
+	return store
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	now := time.Unix(1714564800, 0)
+	store := newTestStore(&now)
+	limit := Limit{Requests: 2, Period: 10 * time.Second}
+
+	for i, want := range []Result{
+		{Allowed: true, Limit: 2, Remaining: 1, Reset: 5 * time.Second},
+		{Allowed: true, Limit: 2, Remaining: 0, Reset: 10 * time.Second},
+		{Allowed: false, Limit: 2, Remaining: 0, Reset: 10 * time.Second, RetryAfter: 5 * time.Second},
+	} {
+		res, err := store.Take(context.Background(), "key", limit)
+		if err != nil {
+			t.Fatalf("take %d: %v", i, err)
+		}
+		if res != want {
+			t.Errorf("take %d = %+v, want %+v", i, res, want)
+		}
+	}
+
+	if res, _ := store.Take(context.Background(), "other", limit); !res.Allowed {
+		t.Error("buckets are shared between keys")
+	}
+
+	now = now.Add(5 * time.Second)
+	if res, _ := store.Take(context.Background(), "key", limit); !res.Allowed || res.Remaining != 0 {
+		t.Errorf("take after refill of one token = %+v", res)
+	}
+
+	now = now.Add(time.Hour)
+	if res, _ := store.Take(context.Background(), "key", limit); !res.Allowed || res.Remaining != 1 {
+		t.Errorf("take after full refill = %+v, bucket must not grow past its size", res)
+	}
+}
+
+func TestMemoryStoreDisabledLimit(t *testing.T) {
+	now := time.Unix(1714564800, 0)
+	store := newTestStore(&now)
+
+	for i := 0; i < 3; i++ {
+		if res, _ := store.Take(context.Background(), "key", Limit{}); !res.Allowed {
+			t.Fatalf("take %d is rejected by disabled limit", i)
+		}
+	}
+	if len(store.buckets) != 0 {
+		t.Error("disabled limit keeps a bucket")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	now := time.Unix(1714564800, 0)
+	store := newTestStore(&now)
+	limit := Limit{Requests: 1, Period: time.Second}
+
+	store.Take(context.Background(), "stale", limit)
+	now = now.Add(2 * sweepInterval)
+	store.Take(context.Background(), "fresh", limit)
+
+	if _, ok := store.buckets["stale"]; ok {
+		t.Error("refilled bucket is not swept")
+	}
+	if _, ok := store.buckets["fresh"]; !ok {
+		t.Error("fresh bucket is swept")
+	}
+}
+
+func TestLimitUnmarshalText(t *testing.T) {
+	for text, want := range map[string]Limit{
+		"":      {},
+		"0":     {},
+		"5/s":   {Requests: 5, Period: time.Second},
+		"10/m":  {Requests: 10, Period: time.Minute},
+		"100/h": {Requests: 100, Period: time.Hour},
+		"3/30s": {Requests: 3, Period: 30 * time.Second},
+		" 1/m ": {Requests: 1, Period: time.Minute},
+	} {
+		var limit Limit
+		if err := limit.UnmarshalText([]byte(text)); err != nil {
+			t.Errorf("UnmarshalText(%q): %v", text, err)
+			continue
+		}
+		if limit != want {
+			t.Errorf("UnmarshalText(%q) = %+v, want %+v", text, limit, want)
+		}
+	}
+
+	for _, text := range []string{"5", "x/s", "-1/s", "5/d", "5/-1s", "5/0s"} {
+		var limit Limit
+		if err := limit.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("UnmarshalText(%q) = %+v, want error", text, limit)
+		}
+	}
+}
+
+func TestAllowlistContains(t *testing.T) {
+	allowlist, err := ParseAllowlist([]string{"10.0.0.0/8", " 192.168.1.1 ", "", "::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for addr, want := range map[string]bool{
+		"10.1.2.3":    true,
+		"192.168.1.1": true,
+		"192.168.1.2": false,
+		"::1":         true,
+		"127.0.0.1":   false,
+		"invalid":     false,
+	} {
+		if got := allowlist.Contains(addr); got != want {
+			t.Errorf("Contains(%s) = %v, want %v", addr, got, want)
+		}
+	}
+
+	if _, err := ParseAllowlist([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("invalid cidr is accepted")
+	}
+}
@@ -0,0 +1,149 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (state State) String() string {
+	switch state {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker opens after threshold consecutive failures and rejects calls for
+// cooldown, then lets a single probe through: its success closes the breaker
+// and its failure opens it again.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(name string, threshold int, cooldown time.Duration, log *slog.Logger) *Breaker {
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    log,
+		now:       time.Now,
+	}
+}
+
+// Allow reports whether call may proceed, a rejected call gets time left until
+// the next probe.
+func (breaker *Breaker) Allow() (time.Duration, error) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	switch breaker.state {
+	case StateOpen:
+		left := breaker.cooldown - breaker.now().Sub(breaker.openedAt)
+		if left > 0 {
+			return left, ErrCircuitOpen
+		}
+		breaker.setState(StateHalfOpen)
+		breaker.probing = true
+		return 0, nil
+	case StateHalfOpen:
+		if breaker.probing {
+			return breaker.cooldown, ErrCircuitOpen
+		}
+		breaker.probing = true
+		return 0, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (breaker *Breaker) Success() {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.failures = 0
+	breaker.probing = false
+	if breaker.state != StateClosed {
+		breaker.setState(StateClosed)
+	}
+}
+
+func (breaker *Breaker) Failure() {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.failures++
+	breaker.probing = false
+	if breaker.state == StateHalfOpen || breaker.state == StateClosed && breaker.failures >= breaker.threshold {
+		breaker.openedAt = breaker.now()
+		breaker.setState(StateOpen)
+	}
+}
+
+// Abandon ends call whose outcome tells nothing about the dependency, such as
+// one cancelled by caller: failures are kept and the next probe is let through.
+func (breaker *Breaker) Abandon() {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	breaker.probing = false
+}
+
+func (breaker *Breaker) State() State {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+
+	if breaker.state == StateOpen && breaker.now().Sub(breaker.openedAt) >= breaker.cooldown {
+		return StateHalfOpen
+	}
+
+	return breaker.state
+}
+
+func (breaker *Breaker) Name() string {
+	return breaker.name
+}
+
+func (breaker *Breaker) setState(state State) {
+	level := slog.LevelInfo
+	if state == StateOpen {
+		level = slog.LevelWarn
+	}
+	breaker.logger.Log(
+		context.Background(),
+		level,
+		"Breaker: state changed",
+		slog.String("breaker", breaker.name),
+		slog.String("from", breaker.state.String()),
+		slog.String("to", state.String()),
+		slog.Int("failures", breaker.failures),
+	)
+	breaker.state = state
+}
@@ -0,0 +1,135 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
+)
+
+// Policy bounds a call: Timeout limits every attempt, Attempts counts the first
+// one as well.
+type Policy struct {
+	Attempts    int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+}
+
+// Executor runs calls under policy and breaker. Errors accepted by retryable are
+// failures of the dependency itself: they are retried and trip the breaker,
+// other errors are results of the call and are returned as is.
+type Executor struct {
+	policy    Policy
+	breaker   *Breaker
+	retryable func(error) bool
+	logger    *slog.Logger
+}
+
+func NewExecutor(policy Policy, breaker *Breaker, retryable func(error) bool, log *slog.Logger) *Executor {
+	if policy.Attempts < 1 {
+		policy.Attempts = 1
+	}
+
+	return &Executor{
+		policy:    policy,
+		breaker:   breaker,
+		retryable: retryable,
+		logger:    log,
+	}
+}
+
+func (executor *Executor) Breaker() *Breaker {
+	return executor.breaker
+}
+
+// Do calls fn, repeating it on retryable errors when retry is set. Calls that
+// are not safe to repeat are made once, yet still timed out and counted by the
+// breaker. Calls cancelled by caller are not counted.
+func (executor *Executor) Do(ctx context.Context, op string, retry bool, fn func(ctx context.Context) error) error {
+	attempts := 1
+	if retry {
+		attempts = executor.policy.Attempts
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay := executor.backoff(attempt)
+			executor.logger.Debug(
+				"Executor: retrying",
+				slog.String("op", op),
+				slog.Int("attempt", attempt+1),
+				slog.Duration("delay", delay),
+				slog.String("error", err.Error()),
+			)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%s: %w", op, err)
+			case <-time.After(delay):
+			}
+		}
+
+		if retryAfter, allowErr := executor.breaker.Allow(); allowErr != nil {
+			return &OpenError{Op: op, RetryAfter: retryAfter}
+		}
+
+		err = executor.attempt(ctx, fn)
+		if errors.Is(err, context.Canceled) {
+			executor.breaker.Abandon()
+			return err
+		}
+		if err == nil || !executor.retryable(err) {
+			executor.breaker.Success()
+			return err
+		}
+		executor.breaker.Failure()
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return err
+}
+
+func (executor *Executor) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if executor.policy.Timeout <= 0 {
+		return fn(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, executor.policy.Timeout)
+	defer cancel()
+
+	return fn(attemptCtx)
+}
+
+// backoff returns full jitter delay before attempt, growing exponentially from
+// BaseBackoff up to MaxBackoff.
+func (executor *Executor) backoff(attempt int) time.Duration {
+	ceiling := executor.policy.BaseBackoff << (attempt - 1)
+	if ceiling <= 0 || ceiling > executor.policy.MaxBackoff {
+		ceiling = executor.policy.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// OpenError is returned without calling the dependency while breaker is open.
+type OpenError struct {
+	Op         string
+	RetryAfter time.Duration
+}
+
+func (e *OpenError) Error() string {
+	return e.Op + ": " + ErrCircuitOpen.Error()
+}
+
+func (e *OpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
@@ -0,0 +1,73 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+func newTestExecutor(now *time.Time) *Executor {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	breaker := NewBreaker("test", 1, time.Minute, logger)
+	breaker.now = func() time.Time { return *now }
+
+	return NewExecutor(
+		Policy{Attempts: 1},
+		breaker,
+		func(err error) bool { return errors.Is(err, errUnavailable) },
+		logger,
+	)
+}
+
+func TestCancelledProbeDoesNotCloseBreaker(t *testing.T) {
+	now := time.Now()
+	executor := newTestExecutor(&now)
+	fail := func(ctx context.Context) error { return errUnavailable }
+
+	if err := executor.Do(context.Background(), "op", false, fail); !errors.Is(err, errUnavailable) {
+		t.Fatalf("err = %v", err)
+	}
+	if state := executor.Breaker().State(); state != StateOpen {
+		t.Fatalf("state = %s, want open", state)
+	}
+
+	now = now.Add(time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	err := executor.Do(ctx, "op", false, func(ctx context.Context) error {
+		cancel()
+		return ctx.Err()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if state := executor.Breaker().State(); state != StateHalfOpen {
+		t.Fatalf("state after cancelled probe = %s, want half_open", state)
+	}
+
+	// the cancelled probe does not hold the slot of the next one
+	if err := executor.Do(context.Background(), "op", false, fail); !errors.Is(err, errUnavailable) {
+		t.Fatalf("next probe err = %v", err)
+	}
+	if state := executor.Breaker().State(); state != StateOpen {
+		t.Fatalf("state after failed probe = %s, want open", state)
+	}
+}
+
+func TestCancelledCallDoesNotResetFailures(t *testing.T) {
+	now := time.Now()
+	executor := newTestExecutor(&now)
+	executor.breaker.threshold = 2
+
+	executor.Do(context.Background(), "op", false, func(ctx context.Context) error { return errUnavailable })
+	executor.Do(context.Background(), "op", false, func(ctx context.Context) error { return context.Canceled })
+	executor.Do(context.Background(), "op", false, func(ctx context.Context) error { return errUnavailable })
+
+	if state := executor.Breaker().State(); state != StateOpen {
+		t.Fatalf("state = %s, want open", state)
+	}
+}
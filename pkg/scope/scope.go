@@ -0,0 +1,88 @@
+package scope
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Any granted covers every scope, "<resource>:*" covers every scope of resource.
+const Any = "*"
+
+var tokenPattern = regexp.MustCompile(`^(\*|[a-z0-9_.-]+(:[a-z0-9_.-]+)*(:\*)?)$`)
+
+// Set is a sorted list of distinct scopes.
+type Set []string
+
+// Parse splits space delimited scope parameter of RFC 6749.
+func Parse(value string) (Set, error) {
+	set := make(Set, 0)
+	for _, token := range strings.Fields(value) {
+		if !tokenPattern.MatchString(token) {
+			return nil, fmt.Errorf("Scope - Parse: invalid scope %q", token)
+		}
+		set = append(set, token)
+	}
+	slices.Sort(set)
+
+	return slices.Compact(set), nil
+}
+
+// Valid reports whether token is a well formed scope or permission.
+func Valid(token string) bool {
+	return tokenPattern.MatchString(token)
+}
+
+func (set Set) String() string {
+	return strings.Join(set, " ")
+}
+
+// Covers reports whether any scope of set matches token.
+func (set Set) Covers(token string) bool {
+	return slices.ContainsFunc(set, func(granted string) bool {
+		return Matches(granted, token)
+	})
+}
+
+// Matches reports whether granted covers token, "*" covers every token and
+// "users:*" covers "users:read" as well as "users:roles:write".
+func Matches(granted string, token string) bool {
+	if granted == Any || granted == token {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(granted, ":*")
+
+	return ok && strings.HasPrefix(token, prefix+":")
+}
+
+// ParseClients accepts "<client id>=<scope> <scope>" entries listing scopes each
+// client may request.
+func ParseClients(entries []string) (map[string]Set, error) {
+	clients := make(map[string]Set, len(entries))
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, scopes, ok := strings.Cut(entry, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("Scope - ParseClients: invalid client %q", entry)
+		}
+		if _, ok := clients[id]; ok {
+			return nil, fmt.Errorf("Scope - ParseClients: client %q is listed twice", id)
+		}
+
+		set, err := Parse(scopes)
+		if err != nil {
+			return nil, fmt.Errorf("Scope - ParseClients - %s: %w", id, err)
+		}
+		clients[id] = set
+	}
+
+	return clients, nil
+}
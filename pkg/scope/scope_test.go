@@ -0,0 +1,97 @@
+package scope
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for value, want := range map[string]Set{
+		"":                          {},
+		"  ":                        {},
+		"users:read":                {"users:read"},
+		"users:write users:read":    {"users:read", "users:write"},
+		"users:read  users:read *":  {"*", "users:read"},
+		"users:roles:write users:*": {"users:*", "users:roles:write"},
+	} {
+		set, err := Parse(value)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", value, err)
+			continue
+		}
+		if !slices.Equal(set, want) {
+			t.Errorf("Parse(%q) = %q, want %q", value, set, want)
+		}
+	}
+
+	for _, value := range []string{"Users:read", "users:", ":read", "users:*:read", "users*", "users:read,users:write"} {
+		if set, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) = %q, want error", value, set)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	for _, tc := range []struct {
+		granted string
+		token   string
+		want    bool
+	}{
+		{"*", "users:read", true},
+		{"*", "*", true},
+		{"users:read", "users:read", true},
+		{"users:read", "users:write", false},
+		{"users:*", "users:read", true},
+		{"users:*", "users:roles:write", true},
+		{"users:*", "users", false},
+		{"users:*", "usersx:read", false},
+		{"users:*", "*", false},
+		{"users:roles:*", "users:read", false},
+		{"users:read", "users:*", false},
+	} {
+		if got := Matches(tc.granted, tc.token); got != tc.want {
+			t.Errorf("Matches(%q, %q) = %v, want %v", tc.granted, tc.token, got, tc.want)
+		}
+	}
+}
+
+func TestCovers(t *testing.T) {
+	set := Set{"profile:read", "users:*"}
+
+	for token, want := range map[string]bool{
+		"profile:read":  true,
+		"profile:write": false,
+		"users:delete":  true,
+		"*":             false,
+	} {
+		if got := set.Covers(token); got != want {
+			t.Errorf("Covers(%q) = %v, want %v", token, got, want)
+		}
+	}
+	if Set(nil).Covers("profile:read") {
+		t.Error("empty set covers a scope")
+	}
+}
+
+func TestParseClients(t *testing.T) {
+	clients, err := ParseClients([]string{" web = profile:read profile:write ", "", "cli=*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clients) != 2 ||
+		!slices.Equal(clients["web"], Set{"profile:read", "profile:write"}) ||
+		!slices.Equal(clients["cli"], Set{"*"}) {
+		t.Errorf("ParseClients = %q", clients)
+	}
+
+	for name, entries := range map[string][]string{
+		"missing scopes": {"web"},
+		"missing id":     {"=profile:read"},
+		"listed twice":   {"web=profile:read", "web=profile:write"},
+		"invalid scope":  {"web=Profile"},
+	} {
+		if _, err := ParseClients(entries); err == nil {
+			t.Errorf("%s: ParseClients(%q) is accepted", name, entries)
+		}
+	}
+}
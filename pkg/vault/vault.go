@@ -0,0 +1,127 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	TokenHeader     = "X-Vault-Token"
+	NamespaceHeader = "X-Vault-Namespace"
+
+	// RefPrefix marks config values that reference a secret as
+	// "vault:<path>#<field>".
+	RefPrefix = "vault:"
+)
+
+var (
+	ErrSecretNotFound = errors.New("secret not found")
+	ErrInvalidRef     = errors.New("invalid secret reference, expected vault:<path>#<field>")
+)
+
+// Client reads secrets from KV engine of Vault compatible HTTP API, both KV
+// version 1 and version 2 responses are understood.
+type Client struct {
+	address   string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+type clientopt func(*Client)
+
+func WithNamespace(namespace string) clientopt {
+	return func(client *Client) {
+		client.namespace = namespace
+	}
+}
+
+func New(address string, token string, timeout time.Duration, opts ...clientopt) *Client {
+	client := &Client{
+		address: strings.TrimRight(address, "/"),
+		token:   token,
+		client: &http.Client{
+			Timeout: timeout,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+type readResponse struct {
+	Data map[string]any `json:"data"`
+}
+
+// Read returns fields of secret at path, e.g. "secret/data/auth" of KV version 2.
+func (client *Client) Read(ctx context.Context, path string) (map[string]string, error) {
+	url := client.address + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Vault - Read - NewRequest: %w", err)
+	}
+	req.Header.Set(TokenHeader, client.token)
+	if client.namespace != "" {
+		req.Header.Set(NamespaceHeader, client.namespace)
+	}
+
+	resp, err := client.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Vault - Read - Do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("Vault - Read - %s: %w", path, ErrSecretNotFound)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("Vault - Read - %s: unexpected status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	response := readResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("Vault - Read - Decode: %w", err)
+	}
+
+	// KV version 2 wraps fields in data.data next to data.metadata
+	data := response.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+
+	fields := make(map[string]string, len(data))
+	for key, value := range data {
+		if value == nil {
+			continue
+		}
+		if text, ok := value.(string); ok {
+			fields[key] = text
+			continue
+		}
+		fields[key] = fmt.Sprint(value)
+	}
+
+	return fields, nil
+}
+
+// ParseRef splits "vault:<path>#<field>" reference.
+func ParseRef(ref string) (string, string, error) {
+	path, field, found := strings.Cut(strings.TrimPrefix(ref, RefPrefix), "#")
+	if !strings.HasPrefix(ref, RefPrefix) || !found || path == "" || field == "" {
+		return "", "", ErrInvalidRef
+	}
+
+	return path, field, nil
+}
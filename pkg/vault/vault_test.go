@@ -0,0 +1,136 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestReadKVVersion2(t *testing.T) {
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/secret/data/auth" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if token := r.Header.Get(TokenHeader); token != "s.token" {
+			t.Errorf("token header is %q", token)
+		}
+		if namespace := r.Header.Get(NamespaceHeader); namespace != "team" {
+			t.Errorf("namespace header is %q", namespace)
+		}
+		w.Write([]byte(`{"data":{"data":{"jwt_secret":"top secret","attempts":3,"empty":null},"metadata":{"version":2}}}`))
+	})
+
+	client := New(server.URL+"/", "s.token", time.Second, WithNamespace("team"))
+	fields, err := client.Read(context.Background(), "/secret/data/auth")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	if fields["jwt_secret"] != "top secret" || fields["attempts"] != "3" {
+		t.Errorf("unexpected fields %v", fields)
+	}
+	if _, ok := fields["empty"]; ok {
+		t.Error("null field is returned")
+	}
+}
+
+func TestReadKVVersion1(t *testing.T) {
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(NamespaceHeader) != "" {
+			t.Error("namespace header is sent without namespace")
+		}
+		// field named data without metadata is a plain KV version 1 field
+		w.Write([]byte(`{"data":{"jwt_secret":"v1 secret","data":{"nested":"value"}}}`))
+	})
+
+	fields, err := New(server.URL, "s.token", time.Second).Read(context.Background(), "secret/auth")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	if fields["jwt_secret"] != "v1 secret" {
+		t.Errorf("jwt_secret is %q", fields["jwt_secret"])
+	}
+	if _, ok := fields["data"]; !ok {
+		t.Error("field named data is unwrapped without metadata")
+	}
+}
+
+func TestReadNotFound(t *testing.T) {
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errors":[]}`))
+	})
+
+	_, err := New(server.URL, "s.token", time.Second).Read(context.Background(), "secret/data/missing")
+	if !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("err = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestReadUnexpectedStatus(t *testing.T) {
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors":["permission denied"]}`))
+	})
+
+	_, err := New(server.URL, "bad", time.Second).Read(context.Background(), "secret/data/auth")
+	if err == nil || errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("err = %v, want unexpected status error", err)
+	}
+	if !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("error %q lacks status and body", err)
+	}
+}
+
+func TestReadMalformedResponse(t *testing.T) {
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`not json`))
+	})
+
+	if _, err := New(server.URL, "s.token", time.Second).Read(context.Background(), "secret/data/auth"); err == nil {
+		t.Fatal("malformed response is accepted")
+	}
+}
+
+func TestReadTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	defer close(release)
+
+	if _, err := New(server.URL, "s.token", 50*time.Millisecond).Read(context.Background(), "secret/data/auth"); err == nil {
+		t.Fatal("slow server does not time out")
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	path, field, err := ParseRef("vault:secret/data/auth#jwt_secret")
+	if err != nil || path != "secret/data/auth" || field != "jwt_secret" {
+		t.Fatalf("ParseRef = %q, %q, %v", path, field, err)
+	}
+
+	for _, ref := range []string{
+		"secret/data/auth#jwt_secret",
+		"vault:secret/data/auth",
+		"vault:#jwt_secret",
+		"vault:secret/data/auth#",
+	} {
+		if _, _, err := ParseRef(ref); !errors.Is(err, ErrInvalidRef) {
+			t.Errorf("ParseRef(%q) err = %v, want ErrInvalidRef", ref, err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+
+	signatureVersion = "v1="
+)
+
+type Message struct {
+	URL        string
+	Secret     string
+	Event      string
+	DeliveryID string
+	Body       []byte
+}
+
+// ErrForbiddenAddress is returned when webhook url resolves to loopback,
+// private, link-local or other internal address.
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+type Sender struct {
+	client       *http.Client
+	allowPrivate bool
+	now          func() time.Time
+}
+
+type SenderOpt func(*Sender)
+
+// WithPrivateNetworks lets deliveries reach loopback and private addresses, for
+// receivers running next to the service in local environment.
+func WithPrivateNetworks() SenderOpt {
+	return func(sender *Sender) {
+		sender.allowPrivate = true
+	}
+}
+
+// NewSender builds sender whose deliveries reach only public addresses: the
+// address is checked after name resolution, right before connecting, so that
+// DNS can not point a subscription at internal services, and redirects are not
+// followed.
+func NewSender(timeout time.Duration, opts ...SenderOpt) *Sender {
+	sender := &Sender{now: time.Now}
+	for _, opt := range opts {
+		opt(sender)
+	}
+
+	dialer := &net.Dialer{Timeout: timeout, Control: sender.control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// proxy would be dialed instead of the receiver, its address says nothing
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	sender.client = &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return sender
+}
+
+func (sender *Sender) control(network string, address string, conn syscall.RawConn) error {
+	if sender.allowPrivate {
+		return nil
+	}
+
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, ErrForbiddenAddress)
+	}
+	if !IsPublic(addrPort.Addr()) {
+		return fmt.Errorf("%s: %w", address, ErrForbiddenAddress)
+	}
+
+	return nil
+}
+
+// cgnat is shared address space of carrier-grade NAT, RFC 6598.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublic reports whether addr is a globally routable unicast address.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!cgnat.Contains(addr)
+}
+
+// Sign returns signature of body sent at timestamp: HMAC-SHA256 over "<timestamp>.<body>".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return signatureVersion + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature and rejects messages older than tolerance, receivers
+// use it to protect themselves from replays.
+func Verify(secret string, timestamp int64, body []byte, signature string, tolerance time.Duration, now time.Time) bool {
+	sent := time.Unix(timestamp, 0)
+	if now.Sub(sent) > tolerance || sent.Sub(now) > tolerance {
+		return false
+	}
+
+	if !strings.HasPrefix(signature, signatureVersion) {
+		return false
+	}
+
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Send posts signed message, any non 2xx response is reported as error along
+// with its status code, so is redirect.
+func (sender *Sender) Send(ctx context.Context, message Message) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, message.URL, bytes.NewReader(message.Body))
+	if err != nil {
+		return 0, fmt.Errorf("Webhook - Send - NewRequest: %w", err)
+	}
+
+	timestamp := sender.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(message.Secret, timestamp, message.Body))
+	req.Header.Set(EventHeader, message.Event)
+	req.Header.Set(DeliveryHeader, message.DeliveryID)
+
+	resp, err := sender.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("Webhook - Send - Do: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("Webhook - Send: unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func newServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestSendRejectsLoopback(t *testing.T) {
+	called := false
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	_, err := NewSender(time.Second).Send(context.Background(), Message{URL: server.URL, Secret: "secret"})
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("err = %v, want ErrForbiddenAddress", err)
+	}
+	if called {
+		t.Error("loopback receiver is reached")
+	}
+}
+
+func TestSendSignsMessage(t *testing.T) {
+	body := []byte(`{"type":"auth.sign_in"}`)
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(EventHeader) != "auth.sign_in" || r.Header.Get(DeliveryHeader) != "d-1" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		if r.Header.Get(TimestampHeader) != "1714564800" ||
+			r.Header.Get(SignatureHeader) != Sign("secret", 1714564800, body) {
+			t.Error("message is not signed")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	sender := NewSender(time.Second, WithPrivateNetworks())
+	sender.now = func() time.Time { return time.Unix(1714564800, 0) }
+
+	statusCode, err := sender.Send(context.Background(), Message{
+		URL:        server.URL,
+		Secret:     "secret",
+		Event:      "auth.sign_in",
+		DeliveryID: "d-1",
+		Body:       body,
+	})
+	if err != nil || statusCode != http.StatusNoContent {
+		t.Fatalf("Send = %d, %v", statusCode, err)
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	target := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("redirect is followed")
+	})
+	server := newServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	})
+
+	statusCode, err := NewSender(time.Second, WithPrivateNetworks()).Send(context.Background(), Message{URL: server.URL})
+	if err == nil || statusCode != http.StatusFound {
+		t.Fatalf("Send = %d, %v, want failed 302", statusCode, err)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for address, want := range map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::248":   true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"fe80::1":                false,
+		"fd00::1":                false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"224.0.0.1":              false,
+	} {
+		if got := IsPublic(netip.MustParseAddr(address)); got != want {
+			t.Errorf("IsPublic(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1714564800, 0)
+	body := []byte(`{}`)
+	signature := Sign("secret", now.Unix(), body)
+
+	if !Verify("secret", now.Unix(), body, signature, time.Minute, now) {
+		t.Error("valid signature is rejected")
+	}
+	if Verify("other", now.Unix(), body, signature, time.Minute, now) {
+		t.Error("signature of another secret is accepted")
+	}
+	if Verify("secret", now.Unix(), body, signature, time.Minute, now.Add(2*time.Minute)) {
+		t.Error("stale message is accepted")
+	}
+}